@@ -0,0 +1,154 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAddress splits an address of the ctl module into a network and an address for Dial. It accepts the kamcmd
+// syntax of the "binrpc" parameter of the ctl module, such as "tcp:host:port" or "unixs:/run/kamailio/kamailio_ctl",
+// and URLs such as "unix:///run/kamailio/kamailio_ctl" or "binrpc://host:port":
+//
+//   - unix, unixs and unixgram (or unixd, a datagram unix socket in kamcmd) are followed by a path;
+//   - tcp, tcp4, tcp6, udp, udp4 and udp6 are followed by host:port, binrpc being an alias of tcp.
+//
+// Addresses without network are unix sockets if they start with "/", and TCP otherwise.
+//
+// IPv6 literals can be written with brackets, as in "tcp:[fe80::1%eth0]:2049", or without, in which case the port is
+// after the last colon, as in "udp:fe80::1%eth0:2049". Zones are kept for link-local addresses.
+func ParseAddress(address string) (network string, addr string, err error) {
+	if strings.HasPrefix(address, "/") {
+		return "unix", address, nil
+	}
+
+	network, addr = "tcp", address
+
+	scheme, rest, found := strings.Cut(address, ":")
+
+	if found {
+		isURL := strings.HasPrefix(rest, "//")
+
+		if isURL {
+			rest = strings.TrimPrefix(rest, "//")
+		}
+
+		switch scheme {
+		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+			network, addr = scheme, rest
+		case "binrpc":
+			network, addr = "tcp", rest
+		case "unixs":
+			network, addr = "unix", rest
+		case "unixd":
+			network, addr = "unixgram", rest
+		default:
+			if isURL {
+				return "", "", fmt.Errorf("invalid address %s: unknown scheme %s", address, scheme)
+			}
+		}
+
+		if isURL && !strings.HasPrefix(network, "unix") {
+			addr = strings.TrimSuffix(addr, "/")
+		}
+	}
+
+	if strings.HasPrefix(network, "unix") {
+		if addr == "" {
+			return "", "", fmt.Errorf("invalid address %s: missing path", address)
+		}
+
+		return network, addr, nil
+	}
+
+	if addr, err = parseHostPort(addr); err != nil {
+		return "", "", fmt.Errorf("invalid address %s: %w", address, err)
+	}
+
+	return network, addr, nil
+}
+
+// DialAddress is like Dial, with an address parsed by ParseAddress, such as "unixs:/run/kamailio/kamailio_ctl" or
+// "binrpc://10.0.0.1:2049".
+func DialAddress(address string, options ...Option) (*Client, error) {
+	network, addr, err := ParseAddress(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Dial(network, addr, options...)
+}
+
+// InvokeTimeout is the timeout of Invoke when its context has no deadline.
+const InvokeTimeout = 10 * time.Second
+
+// Invoke calls method with args on the Kamailio listening on address, parsed by ParseAddress, and returns the records
+// of the reply. It dials, calls, and closes the connection, for scripts and tools making a single call:
+//
+//	records, err := binrpc.Invoke(ctx, "udp:10.0.0.1:2046", "core.version")
+//
+// The whole call, dial included, is limited by the deadline of ctx, or else by InvokeTimeout. To make several calls,
+// keep a Client instead.
+func Invoke(ctx context.Context, address, method string, args ...any) ([]Record, error) {
+	network, addr, err := ParseAddress(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, InvokeTimeout)
+		defer cancel()
+	}
+
+	client, err := dialWith(ctx, &net.Dialer{}, network, addr, nil)
+
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	defer client.Close()
+
+	return client.CallContext(ctx, method, args...)
+}
+
+// parseHostPort returns hostport as accepted by net.Dial, bracketing IPv6 literals.
+func parseHostPort(hostport string) (string, error) {
+	if strings.HasPrefix(hostport, "[") || strings.Count(hostport, ":") < 2 {
+		host, port, err := net.SplitHostPort(hostport)
+
+		if err != nil {
+			return "", err
+		}
+
+		if strings.HasPrefix(hostport, "[") {
+			if _, err = netip.ParseAddr(host); err != nil {
+				return "", err
+			}
+		}
+
+		return net.JoinHostPort(host, port), nil
+	}
+
+	// an IPv6 literal without brackets: the port is after the last colon
+	i := strings.LastIndex(hostport, ":")
+	host, port := hostport[:i], hostport[i+1:]
+
+	if _, err := netip.ParseAddr(host); err != nil {
+		return "", errors.New("missing port, or IPv6 literal without port: use [host]:port")
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", fmt.Errorf("invalid port %s", port)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,76 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := map[string][2]string{
+		"tcp:127.0.0.1:2049":           {"tcp", "127.0.0.1:2049"},
+		"udp:127.0.0.1:2046":           {"udp", "127.0.0.1:2046"},
+		"unixd:/run/kamailio/ctl":      {"unixgram", "/run/kamailio/ctl"},
+		"unix:/run/kamailio/ctl":       {"unix", "/run/kamailio/ctl"},
+		"unixs:/run/kamailio/ctl":      {"unix", "/run/kamailio/ctl"},
+		"/run/kamailio/kamailio_ctl":   {"unix", "/run/kamailio/kamailio_ctl"},
+		"localhost:2049":               {"tcp", "localhost:2049"},
+		"tcp:[::1]:2049":               {"tcp", "[::1]:2049"},
+		"udp6:[fe80::1%eth0]:2046":     {"udp6", "[fe80::1%eth0]:2046"},
+		"tcp:fe80::1%eth0:2049":        {"tcp", "[fe80::1%eth0]:2049"},
+		"2001:db8::1:2049":             {"tcp", "[2001:db8::1]:2049"},
+		"[2001:db8::1]:2049":           {"tcp", "[2001:db8::1]:2049"},
+		"unix:///run/kamailio/ctl":     {"unix", "/run/kamailio/ctl"},
+		"unixgram:///run/kamailio/ctl": {"unixgram", "/run/kamailio/ctl"},
+		"binrpc://10.0.0.1:2049":       {"tcp", "10.0.0.1:2049"},
+		"binrpc://[::1]:2049/":         {"tcp", "[::1]:2049"},
+		"udp://10.0.0.1:2046":          {"udp", "10.0.0.1:2046"},
+	}
+
+	for address, expected := range tests {
+		network, addr, err := ParseAddress(address)
+
+		if err != nil {
+			t.Errorf("%s: %v", address, err)
+		}
+
+		if network != expected[0] || addr != expected[1] {
+			t.Errorf("%s: expected %v, got %s %s", address, expected, network, addr)
+		}
+	}
+}
+
+func TestParseAddressInvalid(t *testing.T) {
+	for _, address := range []string{"tcp:localhost", "tcp:[host]:2049", "udp:fe80::1%eth0", "http://localhost:2049", "unix://", "binrpc://localhost"} {
+		if _, _, err := ParseAddress(address); err == nil {
+			t.Errorf("%s: error must be returned", address)
+		}
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	address := listen(t, echo)
+
+	records, err := Invoke(context.Background(), "tcp:"+address, "core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.echo" {
+		t.Errorf(`expected "core.echo", got "%s"`, value)
+	}
+
+	// the deadline of ctx limits the call
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err = Invoke(ctx, "tcp:"+address, "core.echo", 1000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if _, err = Invoke(context.Background(), "sctp:"+address, "core.echo"); err == nil {
+		t.Error("error must be returned for an invalid address")
+	}
+}
@@ -0,0 +1,138 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+)
+
+// pollRecords are the records of a typical request of a poller.
+var pollRecords = []Record{
+	{Type: TypeString, Value: "stats.get_statistics"},
+	{Type: TypeString, Value: "all"},
+	{Type: TypeArray, Value: []Record{{Type: TypeInt, Value: 1}, {Type: TypeDouble, Value: 0.5}}},
+	{Type: TypeStruct, Value: []StructItem{{Key: "group", Value: Record{Type: TypeBytes, Value: []byte{1, 2}}}}},
+}
+
+func TestAppendRecord(t *testing.T) {
+	prefix := []byte{0xFF}
+
+	buffer, err := AppendRecord(prefix, Record{Type: TypeString, Value: "core.echo"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "ff" + "910a636f72652e6563686f00"; hex.EncodeToString(buffer) != expected {
+		t.Errorf("expected %s, got %x", expected, buffer)
+	}
+
+	if buffer, err = AppendRecord(prefix, Record{Type: TypeInt, Value: "1"}); err == nil {
+		t.Error("error must be returned for a value not matching the type")
+	}
+
+	if !bytes.Equal(buffer, prefix) {
+		t.Errorf("expected %x, got %x", prefix, buffer)
+	}
+}
+
+func TestAppendPacket(t *testing.T) {
+	for _, cookie := range []uint32{0, 0x12, 0x12345678} {
+		var expected bytes.Buffer
+
+		if err := writePacketRecords(&expected, cookie, pollRecords); err != nil {
+			t.Fatal(err)
+		}
+
+		packet, err := AppendPacket([]byte("prefix"), cookie, pollRecords...)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(packet[6:], expected.Bytes()) {
+			t.Errorf("expected %x, got %x", expected.Bytes(), packet[6:])
+		}
+
+		records, err := ReadPacket(bytes.NewReader(packet[6:]), cookie)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(records) != len(pollRecords) {
+			t.Errorf("expected %d records, got %d", len(pollRecords), len(records))
+		}
+	}
+}
+
+func TestAppendPacketAllocs(t *testing.T) {
+	buffer := make([]byte, 0, 256)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buffer, _ = AppendPacket(buffer[:0], 0x12345678, pollRecords...)
+	})
+
+	if allocs != 0 {
+		t.Errorf("expected 0 allocations, got %f", allocs)
+	}
+}
+
+// writePacketRecords writes the packet of records with writePacket.
+func writePacketRecords(w io.Writer, cookie uint32, records []Record) error {
+	pointers := make([]*Record, len(records))
+
+	for i := range records {
+		pointers[i] = &records[i]
+	}
+
+	return writePacket(w, 0, cookie, pointers)
+}
+
+func BenchmarkAppendPacket(b *testing.B) {
+	b.ReportAllocs()
+
+	var buffer []byte
+
+	for i := 0; i < b.N; i++ {
+		buffer, _ = AppendPacket(buffer[:0], uint32(i), pollRecords...)
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	b.ReportAllocs()
+
+	encoder := NewEncoder(io.Discard)
+	packet := Packet{Records: pollRecords}
+
+	for i := 0; i < b.N; i++ {
+		packet.Header.Cookie = uint32(i)
+		encoder.Encode(&packet)
+	}
+}
+
+func BenchmarkWritePacketArgs(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		WritePacketArgs(io.Discard, "stats.get_statistics", "all")
+	}
+}
+
+func BenchmarkDecoder(b *testing.B) {
+	b.ReportAllocs()
+
+	packet, _ := AppendPacket(nil, 0x12345678, pollRecords...)
+	reader := bytes.NewReader(packet)
+	decoder := NewDecoder(reader)
+
+	for i := 0; i < b.N; i++ {
+		reader.Reset(packet)
+		decoder.r.Reset(reader)
+
+		if _, err := decoder.Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+)
+
+// CallBatch calls the RPC functions of requests, created by NewRequest, in a single round trip: the requests are
+// written back to back, and the replies are matched to them by cookie as they are read. This saves a round trip per
+// call, such as when an exporter polls a dozen statistics on each scrape:
+//
+//	uptime, _ := binrpc.NewRequest("core.uptime")
+//	stats, _ := binrpc.NewRequest("tm.stats")
+//
+//	results := client.CallBatch(ctx, []*binrpc.Request{uptime, stats})
+//
+//	if results[1].Err == nil {
+//		fmt.Println(results[1].Records)
+//	}
+//
+// The results are in the order of requests. If Kamailio replies with a fault, the error of the result is an
+// *RPCError. If the batch fails, such as when the connection is lost, the calls not replied yet fail with its error.
+//
+// The deadline of ctx and the timeout of the Client (see WithTimeout) apply to the whole batch. Unlike CallContext,
+// the calls are not passed to the interceptors and the hooks, and are neither retried nor retransmitted. They are
+// counted in the statistics (see WithStats).
+func (c *Client) CallBatch(ctx context.Context, requests []*Request) []CallResult {
+	results := make([]CallResult, len(requests))
+	pending := make(map[uint32]int, len(requests))
+
+	for i, request := range requests {
+		if _, ok := pending[request.Cookie]; ok {
+			return failBatch(results, fmt.Errorf("cookie %08x used by several requests", request.Cookie))
+		}
+
+		pending[request.Cookie] = i
+	}
+
+	start := time.Now()
+
+	for _, request := range requests {
+		c.stats.start(request.Method)
+	}
+
+	if err := c.batch(ctx, requests, pending, results); err != nil {
+		for _, i := range pending {
+			results[i].Err = err
+		}
+	}
+
+	for i, request := range requests {
+		c.stats.done(request.Method, time.Since(start), results[i].Err)
+	}
+
+	return results
+}
+
+// batch writes requests and reads their replies into results. The replies not read are left in pending, the index
+// of the request of each cookie, and fail with the error returned.
+func (c *Client) batch(ctx context.Context, requests []*Request, pending map[uint32]int, results []CallResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.markUsed()
+
+	timeouts := c.timeouts.override(callTimeouts(ctx))
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, abandoned := range c.abandoned {
+		if _, ok := pending[abandoned]; ok {
+			return ErrCookieInUse
+		}
+	}
+
+	if err := c.ready(ctx, timeouts.Dial); err != nil {
+		return err
+	}
+
+	deadline, hasDeadline := c.deadline(ctx)
+
+	c.conn.SetWriteDeadline(stepDeadline(deadline, hasDeadline, timeouts.Write))
+
+	defer c.conn.SetDeadline(time.Time{})
+
+	stop := c.watch(ctx)
+	defer stop()
+
+	if err := c.writeBatch(ctx, requests); err != nil {
+		// a partial request may have been written
+		c.fail(err)
+		return contextError(ctx, err)
+	}
+
+	c.conn.SetReadDeadline(stepDeadline(deadline, hasDeadline, timeouts.Read))
+
+	// the watcher may have interrupted the batch before the read deadline was set
+	if ctx.Err() != nil {
+		c.conn.SetReadDeadline(time.Unix(1, 0))
+	}
+
+	for len(pending) > 0 {
+		header, buffer, err := c.readPacket()
+
+		if err != nil {
+			if c.err == nil {
+				for cookie := range pending {
+					c.abandon(cookie)
+				}
+			}
+
+			c.log(ctx, slog.LevelDebug, "binrpc read", errorAttr(err))
+
+			return contextError(ctx, err)
+		}
+
+		i, ok := pending[header.Cookie]
+
+		c.logRead(ctx, header, buffer.Bytes(), ok)
+
+		if !ok {
+			buffers.put(buffer)
+
+			if !c.forget(header.Cookie) && !isDatagram(c.network) {
+				c.fail(errors.New("expected cookie did not match"))
+				return c.err
+			}
+
+			continue
+		}
+
+		delete(pending, header.Cookie)
+
+		records, err := decodePayload(header, buffer.Bytes(), c.readerOptions)
+		buffers.put(buffer)
+
+		switch {
+		case err != nil:
+			c.log(ctx, slog.LevelDebug, "binrpc decode", slog.Any("cookie", header.Cookie), errorAttr(err))
+			results[i].Err = err
+		case header.Fault():
+			results[i].Err = faultError(records)
+		default:
+			results[i].Records = records
+		}
+	}
+
+	return nil
+}
+
+// writeBatch writes requests to the connection: at once on a stream, with a single system call if the connection
+// supports it (see net.Buffers), and one packet after the other on a datagram connection.
+func (c *Client) writeBatch(ctx context.Context, requests []*Request) error {
+	if isDatagram(c.network) {
+		for _, request := range requests {
+			if err := c.writeRequest(ctx, request); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	packets := make(net.Buffers, 0, len(requests))
+
+	for _, request := range requests {
+		packets = append(packets, request.packet)
+	}
+
+	_, err := packets.WriteTo(c.conn)
+
+	for _, request := range requests {
+		c.logWrite(ctx, request, err)
+	}
+
+	return err
+}
+
+// failBatch sets the error of all results to err, and returns them.
+func failBatch(results []CallResult, err error) []CallResult {
+	for i := range results {
+		results[i].Err = err
+	}
+
+	return results
+}
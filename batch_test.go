@@ -0,0 +1,91 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallBatch(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "missing" {
+			return []any{&RPCError{Code: 500, Message: "command missing not found"}}
+		}
+
+		return echo(records)
+	}, WithStats())
+
+	var requests []*Request
+
+	for _, method := range []string{"core.uptime", "missing", "tm.stats"} {
+		request, err := NewRequest(method)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		requests = append(requests, request)
+	}
+
+	results := client.CallBatch(context.Background(), requests)
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	for _, i := range []int{0, 2} {
+		if results[i].Err != nil {
+			t.Fatal(results[i].Err)
+		}
+
+		if method, _ := results[i].Records[0].String(); method != requests[i].Method {
+			t.Errorf(`expected "%s", got "%s"`, requests[i].Method, method)
+		}
+	}
+
+	var fault *RPCError
+
+	if !errors.As(results[1].Err, &fault) || fault.Code != 500 {
+		t.Errorf("expected a fault, got %v", results[1].Err)
+	}
+
+	if stats := client.Stats(); stats["tm.stats"].Calls != 1 || stats["missing"].Faults != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// the same cookie twice
+	results = client.CallBatch(context.Background(), []*Request{requests[0], requests[0]})
+
+	if results[0].Err == nil || results[1].Err == nil {
+		t.Error("error must be returned for duplicate cookies")
+	}
+}
+
+func TestCallBatchTimeout(t *testing.T) {
+	client := serve(t, echo, WithTimeout(100*time.Millisecond))
+
+	fast, _ := NewRequest("fast")
+	slow, _ := NewRequest("slow", 150)
+
+	results := client.CallBatch(context.Background(), []*Request{fast, slow})
+
+	if results[0].Err != nil {
+		t.Error(results[0].Err)
+	}
+
+	if !isTimeout(results[1].Err) {
+		t.Errorf("expected a timeout, got %v", results[1].Err)
+	}
+
+	// the late reply is discarded by the next call
+	records, err := client.Call("next")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if method, _ := records[0].String(); method != "next" {
+		t.Errorf(`expected "next", got "%s"`, method)
+	}
+}
@@ -7,15 +7,17 @@
 //
 // Limits
 //
-// The current implementation handles only int, string, and structs containing int or string values. Other types will return an error.
+// The current implementation handles only int, string, double, bytes, structs and arrays. Other types will return an
+// error.
 //
 // Usage
 //
 // High level functions:
 //
-// - WritePacket to call an RPC function (a string like "tm.stats")
+// - WritePacket to call an RPC function (a string like "tm.stats"), or WritePacketArgs for args of different types,
+// structs and arrays
 //
-// - ReadPacket to read the response
+// - ReadPacket to read the response, or ReadPacketContext to abort the read when a context is done
 //
 //   package main
 //
@@ -53,11 +55,16 @@ package binrpc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"math/rand"
+	"math"
+	"net"
+	"net/netip"
+	"reflect"
 	"strconv"
+	"time"
 )
 
 // BinRPCMagic is a magic value at the start of every BINRPC packet.
@@ -80,18 +87,30 @@ const (
 	MaxSizeOfLength = 4
 )
 
-// internal error used to detect the end of a struct
+// internal errors used to detect the end of a struct or an array
 var errEndOfStruct = errors.New("END_OF_STRUCT")
+var errEndOfArray = errors.New("END_OF_ARRAY")
 
 // Header is a struct containing values needed for parsing the payload and replying. It is not a binary representation of the actual header.
 type Header struct {
 	PayloadLength int
 	Cookie        uint32
+
+	// size of the header on the wire
+	size int
+
+	// flags of the packet (4 bits), see flagFault
+	flags uint8
+}
+
+// Size returns the size of the header on the wire, in bytes. It is zero if the header was not read.
+func (header *Header) Size() int {
+	return header.size
 }
 
 // ValidTypes is an interface of types that can be used in a Record.
 type ValidTypes interface {
-	int | string | float64
+	int | string | float64 | []byte
 }
 
 // Record represents a BINRPC type+size, and Go value. It is not a binary representation of a record.
@@ -99,6 +118,10 @@ type ValidTypes interface {
 type Record struct {
 	size int
 
+	// position within the packet, if recorded (see ReaderOptions.RecordOffsets)
+	offset      int
+	offsetKnown bool
+
 	Type  uint8
 	Value any
 }
@@ -119,6 +142,15 @@ func (record Record) String() (string, error) {
 	return record.Value.(string), nil
 }
 
+// Bytes returns the bytes value, or an error if the type is not bytes.
+func (record Record) Bytes() ([]byte, error) {
+	if record.Type != TypeBytes {
+		return nil, fmt.Errorf("type error: expected type bytes (%d), got %d", TypeBytes, record.Type)
+	}
+
+	return record.Value.([]byte), nil
+}
+
 // Int returns the int value, or an error if the type is not a int.
 func (record Record) Int() (int, error) {
 	if record.Type != TypeInt {
@@ -146,7 +178,27 @@ func (record *Record) StructItems() ([]StructItem, error) {
 	return record.Value.([]StructItem), nil
 }
 
-// Scan copies the value in the Record into the values pointed at by dest. Valid dest type are *int, *string, and *[]StructItem
+// Array returns the elements of an array value, or an error if not an array.
+func (record *Record) Array() ([]Record, error) {
+	if record.Type != TypeArray {
+		return nil, fmt.Errorf("type error: expected type array (%d), got %d", TypeArray, record.Type)
+	}
+
+	return record.Value.([]Record), nil
+}
+
+// Offset returns the position of the record within the packet it was decoded from, and the length of its encoding.
+// Both include the record header. ok is false if the position was not recorded, see ReaderOptions.RecordOffsets.
+func (record *Record) Offset() (offset int, length int, ok bool) {
+	return record.offset, record.size, record.offsetKnown
+}
+
+// Scan copies the value in the Record into the values pointed at by dest. Valid dest type are *int, *int64, *uint,
+// *uint32, *uint64, *bool, *string, *float64, *[]byte, *netip.Addr, *[]StructItem, *[]Record, pointers to structs (see
+// scanStruct for how struct items are mapped to fields), and pointers to slices of valid types, for arrays.
+//
+// Unsigned integers must be in the range of their type. Booleans are read from 0 or 1, and from "yes" or "no", as
+// replied by some commands. Addresses are parsed from strings, such as the ones of core.sockets_list.
 func (record *Record) Scan(dest any) error {
 	switch dest.(type) {
 	case *string:
@@ -159,6 +211,8 @@ func (record *Record) Scan(dest any) error {
 			*s = strconv.Itoa(record.Value.(int))
 		case TypeDouble:
 			*s = fmt.Sprintf("%.3f", record.Value.(float64))
+		case TypeBytes:
+			*s = string(record.Value.([]byte))
 		default:
 			return fmt.Errorf("type error: cannot convert type %d to string", record.Type)
 		}
@@ -177,6 +231,65 @@ func (record *Record) Scan(dest any) error {
 		default:
 			return fmt.Errorf("type error: cannot convert type %d to int", record.Type)
 		}
+	case *int64:
+		i := dest.(*int64)
+
+		switch record.Type {
+		case TypeString:
+			if n, err := strconv.ParseInt(record.Value.(string), 10, 64); err == nil {
+				*i = n
+			} else {
+				return err
+			}
+		case TypeInt:
+			*i = int64(record.Value.(int))
+		default:
+			return fmt.Errorf("type error: cannot convert type %d to int64", record.Type)
+		}
+	case *uint:
+		n, err := record.scanUint(strconv.IntSize)
+
+		if err != nil {
+			return err
+		}
+
+		*dest.(*uint) = uint(n)
+	case *uint32:
+		n, err := record.scanUint(32)
+
+		if err != nil {
+			return err
+		}
+
+		*dest.(*uint32) = uint32(n)
+	case *uint64:
+		n, err := record.scanUint(64)
+
+		if err != nil {
+			return err
+		}
+
+		*dest.(*uint64) = n
+	case *bool:
+		b, err := record.scanBool()
+
+		if err != nil {
+			return err
+		}
+
+		*dest.(*bool) = b
+	case *netip.Addr:
+		if record.Type != TypeString {
+			return fmt.Errorf("type error: cannot convert type %d to netip.Addr", record.Type)
+		}
+
+		addr, err := netip.ParseAddr(record.Value.(string))
+
+		if err != nil {
+			return err
+		}
+
+		*dest.(*netip.Addr) = addr
 	case *float64:
 		f := dest.(*float64)
 
@@ -194,6 +307,17 @@ func (record *Record) Scan(dest any) error {
 		default:
 			return fmt.Errorf("type error: cannot convert type %d to double", record.Type)
 		}
+	case *[]byte:
+		b := dest.(*[]byte)
+
+		switch record.Type {
+		case TypeBytes:
+			*b = record.Value.([]byte)
+		case TypeString:
+			*b = []byte(record.Value.(string))
+		default:
+			return fmt.Errorf("type error: cannot convert type %d to []byte", record.Type)
+		}
 	case *[]StructItem:
 		if record.Type != TypeStruct {
 			return fmt.Errorf("type error: cannot convert type %d to []StructItem", record.Type)
@@ -201,8 +325,28 @@ func (record *Record) Scan(dest any) error {
 
 		items := dest.(*[]StructItem)
 		*items = record.Value.([]StructItem)
+	case *[]Record:
+		if record.Type != TypeArray {
+			return fmt.Errorf("type error: cannot convert type %d to []Record", record.Type)
+		}
+
+		elements := dest.(*[]Record)
+		*elements = record.Value.([]Record)
 	default:
-		return errors.New("invalid dest type")
+		v := reflect.ValueOf(dest)
+
+		if v.Kind() != reflect.Pointer || v.IsNil() {
+			return errors.New("invalid dest type")
+		}
+
+		switch v.Elem().Kind() {
+		case reflect.Struct:
+			return record.scanStruct(v.Elem())
+		case reflect.Slice:
+			return record.scanSlice(v.Elem())
+		default:
+			return errors.New("invalid dest type")
+		}
 	}
 
 	return nil
@@ -210,69 +354,142 @@ func (record *Record) Scan(dest any) error {
 
 // Encode is a low level function that encodes a record and writes it to w.
 func (record *Record) Encode(w io.Writer) error {
-	var value bytes.Buffer
+	buffer, err := appendRecord(nil, record)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(buffer)
+
+	return err
+}
+
+// AppendRecord appends the encoding of record to dst and returns the extended buffer, allocating only to grow dst.
+// On error, dst is returned unchanged.
+func AppendRecord(dst []byte, record Record) ([]byte, error) {
+	buffer, err := appendRecord(dst, &record)
 
+	if err != nil {
+		return dst, err
+	}
+
+	return buffer, nil
+}
+
+// appendRecord is the implementation of AppendRecord.
+func appendRecord(dst []byte, record *Record) ([]byte, error) {
 	switch record.Type {
 	case TypeInt:
-		var v int
-		var ok bool
+		v, ok := record.Value.(int)
 
-		if v, ok = record.Value.(int); !ok {
-			return errors.New("type error: expected type int")
+		if !ok {
+			return nil, errors.New("type error: expected type int")
 		}
 
-		// shortcut!
-		if v == 0 {
-			_, err := w.Write([]byte{0x00})
-			return err
+		dst = appendRecordHeader(dst, record.Type, int(getMinBinarySizeOfInt(v)))
+
+		return appendIntBE(dst, v), nil
+	case TypeString, TypeAVP:
+		s, ok := record.Value.(string)
+
+		if !ok {
+			return nil, errors.New("type error: expected type string")
 		}
 
-		value.Write(intToBytesBE(v))
-	case TypeString:
-		if s, ok := record.Value.(string); !ok {
-			return errors.New("type error: expected type string")
-		} else {
-			value.WriteString(s)
+		dst = appendRecordHeader(dst, record.Type, len(s)+1)
+		dst = append(dst, s...)
+
+		return append(dst, 0x00), nil
+	case TypeBytes:
+		b, ok := record.Value.([]byte)
+
+		if !ok {
+			return nil, errors.New("type error: expected type []byte")
 		}
 
-		value.WriteByte(0x00)
+		// unlike strings, bytes are not null terminated
+		dst = appendRecordHeader(dst, record.Type, len(b))
+
+		return append(dst, b...), nil
 	case TypeDouble:
-		var v float64
-		var ok bool
+		v, ok := record.Value.(float64)
 
-		if v, ok = record.Value.(float64); !ok {
-			return errors.New("type error: expected type float64")
+		if !ok {
+			return nil, errors.New("type error: expected type float64")
 		}
 
-		value.Write(intToBytesBE(int(v * 1000)))
+		// doubles are implemented as int*1000
+		i := int(v * 1000)
+
+		dst = appendRecordHeader(dst, record.Type, int(getMinBinarySizeOfInt(i)))
+
+		return appendIntBE(dst, i), nil
+	case TypeStruct:
+		items, ok := record.Value.([]StructItem)
+
+		if !ok {
+			return nil, errors.New("type error: expected type []StructItem")
+		}
+
+		return appendStruct(dst, items)
+	case TypeArray:
+		elements, ok := record.Value.([]Record)
+
+		if !ok {
+			return nil, errors.New("type error: expected type []Record")
+		}
+
+		return appendArray(dst, elements)
 	default:
-		return fmt.Errorf("type error: type %d not implemented", record.Type)
+		return nil, fmt.Errorf("type error: type %d not implemented", record.Type)
 	}
+}
 
-	sizeOfValue := value.Len()
+// appendRecordHeader appends the header of a record of type recordType, having a value of size bytes.
+func appendRecordHeader(dst []byte, recordType uint8, size int) []byte {
+	if size < 8 {
+		// this can fit in 3 bits
+		return append(dst, byte(size<<4)|recordType)
+	}
 
-	var buffer bytes.Buffer
+	dst = append(dst, 1<<7|getMinBinarySizeOfInt(size)<<4|recordType)
 
-	if sizeOfValue < 8 {
-		// this can fit in 3 bits
-		header := byte(sizeOfValue<<4) | record.Type
-		buffer.WriteByte(header)
-		buffer.Write(value.Bytes())
-	} else {
-		sizeBytes := intToBytesBE(sizeOfValue)
+	return appendIntBE(dst, size)
+}
 
-		header := 1<<7 | uint8(len(sizeBytes)<<4) | record.Type
+// appendStruct appends a struct: a header without size, the name and value of each item, and the end marker.
+func appendStruct(dst []byte, items []StructItem) ([]byte, error) {
+	var err error
 
-		buffer.WriteByte(header)
-		buffer.Write(sizeBytes)
-		buffer.Write(value.Bytes())
+	dst = append(dst, TypeStruct)
+
+	for i := range items {
+		dst = appendRecordHeader(dst, TypeAVP, len(items[i].Key)+1)
+		dst = append(dst, items[i].Key...)
+		dst = append(dst, 0x00)
+
+		if dst, err = appendRecord(dst, &items[i].Value); err != nil {
+			return nil, fmt.Errorf("struct item %s: %w", items[i].Key, err)
+		}
 	}
 
-	if _, err := buffer.WriteTo(w); err != nil {
-		return err
+	return append(dst, 1<<7|TypeStruct), nil
+}
+
+// appendArray appends an array: a header without size, each element, and the end marker.
+func appendArray(dst []byte, elements []Record) ([]byte, error) {
+	var err error
+
+	dst = append(dst, TypeArray)
+
+	for i := range elements {
+		if dst, err = appendRecord(dst, &elements[i]); err != nil {
+			return nil, fmt.Errorf("array element %d: %w", i, err)
+		}
 	}
 
-	return nil
+	return append(dst, 1<<7|TypeArray), nil
 }
 
 // CreateRecord is a low level function that creates a Record from value v and fills the Type property automatically.
@@ -288,6 +505,8 @@ func CreateRecord[T ValidTypes](v T) (*Record, error) {
 		record.Type = TypeInt
 	case float64:
 		record.Type = TypeDouble
+	case []byte:
+		record.Type = TypeBytes
 	default:
 		return nil, errors.New("type not implemented")
 	}
@@ -299,10 +518,8 @@ func CreateRecord[T ValidTypes](v T) (*Record, error) {
 func ReadHeader(r io.Reader) (*Header, error) {
 	buf := make([]byte, 2)
 
-	if len, err := r.Read(buf); err != nil {
-		return nil, fmt.Errorf("cannot read header: %w", err)
-	} else if len != 2 {
-		return nil, fmt.Errorf("cannot read header: read=%d/%d", len, 2)
+	if err := readFull(r, buf, "header"); err != nil {
+		return nil, err
 	}
 
 	if magic := buf[0] >> 4; magic != BinRPCMagic {
@@ -313,29 +530,34 @@ func ReadHeader(r io.Reader) (*Header, error) {
 		return nil, fmt.Errorf("version did not match, expected %d, got %d", BinRPCVersion, version)
 	}
 
+	flags := buf[1] >> 4
 	sizeOfLength := buf[1]&0x0C>>2 + 1
 	sizeOfCookie := buf[1]&0x3 + 1
 
 	buf = make([]byte, sizeOfLength)
 
-	if len, err := r.Read(buf); err != nil {
-		return nil, fmt.Errorf("cannot read total length: %w", err)
-	} else if len != int(sizeOfLength) {
-		return nil, fmt.Errorf("cannot read total length, read=%d/%d", len, sizeOfLength)
+	if err := readFull(r, buf, "total length"); err != nil {
+		return nil, err
 	}
 
-	header := Header{}
+	header := Header{
+		size:  2 + int(sizeOfLength) + int(sizeOfCookie),
+		flags: flags,
+	}
 
 	for _, b := range buf {
 		header.PayloadLength = header.PayloadLength<<8 + int(b)
 	}
 
+	// up to 4 bytes: the length overflows on 32 bits platforms
+	if header.PayloadLength < 0 {
+		return nil, fmt.Errorf("invalid total length %d", header.PayloadLength)
+	}
+
 	cookieBytes := make([]byte, sizeOfCookie)
 
-	if len, err := r.Read(cookieBytes); err != nil {
-		return nil, fmt.Errorf("cannot read cookie: %w", err)
-	} else if len != int(sizeOfCookie) {
-		return nil, fmt.Errorf("cannot read cookie, read=%d/%d", len, sizeOfCookie)
+	if err := readFull(r, cookieBytes, "cookie"); err != nil {
+		return nil, err
 	}
 
 	for _, b := range cookieBytes {
@@ -345,16 +567,52 @@ func ReadHeader(r io.Reader) (*Header, error) {
 	return &header, nil
 }
 
-// ReadRecord is a low level function that reads from r and returns a Record or an error if one occurred.
+// readFull reads exactly len(buf) bytes from r, what being the name of the data read, for errors. A single Read may
+// legally return less, for instance when a packet is split in several TCP segments.
+func readFull(r io.Reader, buf []byte, what string) error {
+	n, err := io.ReadFull(r, buf)
+
+	switch {
+	case err == nil:
+		return nil
+	case n == 0:
+		return fmt.Errorf("cannot read %s: %w", what, err)
+	default:
+		return fmt.Errorf("cannot read %s: read=%d/%d: %w", what, n, len(buf), err)
+	}
+}
+
+// ReadRecord is a low level function that reads from r and returns a Record or an error if one occurred. The nesting
+// depth of structs and arrays is limited to DefaultMaxDepth.
 func ReadRecord(r io.Reader) (*Record, error) {
+	return readRecord(r, &decodeState{})
+}
+
+// readRecord reads a record from r, recording its position and the position of decoding errors if state asks for it.
+func readRecord(r io.Reader, state *decodeState) (*Record, error) {
+	offset := state.offset()
+	record, err := decodeRecord(r, state)
+
+	if err != nil {
+		return nil, state.wrap(offset, err)
+	}
+
+	if offset >= 0 && state.options.RecordOffsets {
+		record.offset = offset
+		record.offsetKnown = true
+	}
+
+	return record, nil
+}
+
+// decodeRecord is the implementation of readRecord.
+func decodeRecord(r io.Reader, state *decodeState) (*Record, error) {
 	record := Record{}
 
-	buf := make([]byte, 1)
+	buf := state.buffer(1)
 
-	if len, err := r.Read(buf); err != nil {
-		return nil, fmt.Errorf("cannot read record header: %w", err)
-	} else if len != 1 {
-		return nil, fmt.Errorf("cannot read record header: read=%d/1", len)
+	if err := readFull(r, buf, "record header"); err != nil {
+		return nil, err
 	}
 
 	flag := buf[0] >> 7
@@ -368,13 +626,20 @@ func ReadRecord(r io.Reader) (*Record, error) {
 		return nil, errEndOfStruct
 	}
 
+	if flag == 1 && size == 0 && record.Type == TypeArray {
+		// this marks the end of an array
+		return nil, errEndOfArray
+	}
+
+	if err := state.count(); err != nil {
+		return nil, err
+	}
+
 	if flag == 1 {
-		buf = make([]byte, size)
+		buf = state.buffer(size)
 
-		if len, err := r.Read(buf); err != nil {
-			return nil, fmt.Errorf("cannot read record size: %w", err)
-		} else if len != size {
-			return nil, fmt.Errorf("cannot read record size: read=%d/%d", len, size)
+		if err := readFull(r, buf, "record size"); err != nil {
+			return nil, err
 		}
 
 		size = 0
@@ -382,18 +647,26 @@ func ReadRecord(r io.Reader) (*Record, error) {
 			size = size<<8 + int(b)
 		}
 
+		// up to 7 bytes: the size overflows on 32 bits platforms
+		if size < 0 || size > math.MaxInt-record.size {
+			return nil, fmt.Errorf("cannot read record value: invalid size %d", size)
+		}
+
 		record.size += size
 	}
 
+	// do not trust the size before allocating, when the bytes left are known (e.g. the payload of a packet)
+	if left, ok := r.(interface{ Len() int }); ok && size > left.Len() {
+		return nil, fmt.Errorf("cannot read record value: size %d exceeds the %d bytes left", size, left.Len())
+	}
+
 	if size == 0 {
 		buf = nil
 	} else {
-		buf = make([]byte, size)
+		buf = state.buffer(size)
 
-		if len, err := r.Read(buf); err != nil {
-			return nil, fmt.Errorf("cannot read record value: %w", err)
-		} else if len != size {
-			return nil, fmt.Errorf("cannot read record value: read=%d/%d", len, size)
+		if err := readFull(r, buf, "record value"); err != nil {
+			return nil, err
 		}
 	}
 
@@ -408,30 +681,25 @@ func ReadRecord(r io.Reader) (*Record, error) {
 
 		// skip the null byte
 		record.Value = string(buf[0 : len(buf)-1])
+	case TypeBytes:
+		// buf is reused by the next records
+		record.Value = append([]byte{}, buf...)
 	case TypeInt:
-		record.Value = int(0)
-
-		if size == 0 {
-			break
-		}
-
-		for _, b := range buf {
-			record.Value = record.Value.(int)<<8 + int(b)
-		}
+		record.Value = decodeInt(buf)
 	case TypeDouble:
-		record.Value = int(0)
-
-		for _, b := range buf {
-			record.Value = record.Value.(int)<<8 + int(b)
-		}
-
 		// double are implemented as int*1000
-		record.Value = float64(record.Value.(int)) / 1000.0
+		record.Value = float64(decodeInt(buf)) / 1000.0
 	case TypeStruct:
+		if err := state.enter(); err != nil {
+			return nil, err
+		}
+
+		defer state.leave()
+
 		var items []StructItem
 
 		for {
-			avpName, err := ReadRecord(r)
+			avpName, err := readRecord(r, state)
 
 			if err == errEndOfStruct {
 				record.size++
@@ -446,7 +714,7 @@ func ReadRecord(r io.Reader) (*Record, error) {
 
 			record.size += avpName.size
 
-			avpValue, err := ReadRecord(r)
+			avpValue, err := readRecord(r, state)
 
 			if err != nil {
 				return nil, err
@@ -461,6 +729,54 @@ func ReadRecord(r io.Reader) (*Record, error) {
 		}
 
 		record.Value = items
+	case TypeArray:
+		if err := state.enter(); err != nil {
+			return nil, err
+		}
+
+		defer state.leave()
+
+		elements := []Record{}
+
+		for {
+			element, err := readRecord(r, state)
+
+			if err == errEndOfArray {
+				record.size++
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			record.size += element.size
+
+			if element.Type == TypeAVP {
+				// named elements are added by Kamailio with rpc->struct_add() on an array,
+				// each one is returned as a struct containing a single item
+				value, err := readRecord(r, state)
+
+				if err != nil {
+					return nil, err
+				}
+
+				record.size += value.size
+
+				element = &Record{
+					size:        element.size + value.size,
+					offset:      element.offset,
+					offsetKnown: element.offsetKnown,
+					Type:        TypeStruct,
+					Value: []StructItem{{
+						Key:   element.Value.(string),
+						Value: *value,
+					}},
+				}
+			}
+
+			elements = append(elements, *element)
+		}
+
+		record.Value = elements
 	default:
 		return nil, fmt.Errorf("type error: type %d not implemented", record.Type)
 	}
@@ -470,9 +786,15 @@ func ReadRecord(r io.Reader) (*Record, error) {
 
 // ReadPacket reads from r and returns records, or an error if one occurred.
 // If expectedCookie is not zero, it verifies the cookie.
+//
+// If Kamailio replies with a fault, the records are returned with an *RPCError holding the code and the message.
 func ReadPacket(r io.Reader, expectedCookie uint32) ([]Record, error) {
-	bufreader := bufio.NewReader(r)
-	header, err := ReadHeader(bufreader)
+	return ReadPacketWithOptions(r, expectedCookie, ReaderOptions{})
+}
+
+// ReadPacketWithOptions is like ReadPacket, with options controlling the decoding.
+func ReadPacketWithOptions(r io.Reader, expectedCookie uint32, options ReaderOptions) ([]Record, error) {
+	header, payload, err := readPayload(bufio.NewReader(r), options.MaxPayload)
 
 	if err != nil {
 		return nil, err
@@ -482,18 +804,112 @@ func ReadPacket(r io.Reader, expectedCookie uint32) ([]Record, error) {
 		return nil, errors.New("expected cookie did not match")
 	}
 
-	payloadBytes := make([]byte, header.PayloadLength)
+	records, err := decodePayload(header, payload, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Fault() {
+		return records, faultError(records)
+	}
+
+	return records, nil
+}
+
+// ReadPacketContext is like ReadPacket, but reads from conn until ctx is done, in which case it returns the error of
+// ctx. A packet partially read is lost, so conn should be closed after an error.
+//
+// The Client handles this for you: see Client.CallContext.
+func ReadPacketContext(ctx context.Context, conn net.Conn, expectedCookie uint32) ([]Record, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+		defer conn.SetReadDeadline(time.Time{})
+	}
+
+	stop := watchConn(ctx, conn)
+	defer stop()
+
+	records, err := ReadPacket(conn, expectedCookie)
+
+	return records, contextError(ctx, err)
+}
+
+// DecodePacket reads a packet from r whatever its cookie, and returns its header and records. It is meant for tools
+// analyzing captures: use ReadPacket or ReadResponse to read a reply.
+// To read several packets from the same stream, r must be a *bufio.Reader, as a new one would read ahead.
+func DecodePacket(r io.Reader, options ReaderOptions) (*Header, []Record, error) {
+	header, payload, err := readPayload(bufio.NewReader(r), options.MaxPayload)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := decodePayload(header, payload, options)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, records, nil
+}
+
+// readPayload reads a header and the whole payload it announces from r, unless its length exceeds maxPayload (if not
+// zero). The payload is consumed even if the caller does not want it, so that r stays aligned on packet boundaries.
+func readPayload(r io.Reader, maxPayload int) (*Header, []byte, error) {
+	var payload bytes.Buffer
+
+	header, err := readPayloadTo(r, &payload, maxPayload)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return header, payload.Bytes(), nil
+}
+
+// readPayloadTo is like readPayload, but writes the payload to buffer.
+func readPayloadTo(r io.Reader, buffer *bytes.Buffer, maxPayload int) (*Header, error) {
+	header, err := ReadHeader(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if maxPayload > 0 && header.PayloadLength > maxPayload {
+		return nil, fmt.Errorf("%w: payload length %d exceeds %d", ErrLimitExceeded, header.PayloadLength, maxPayload)
+	}
+
+	// grow the payload as it is read, rather than trusting the length announced
+	if _, err := io.CopyN(buffer, r, int64(header.PayloadLength)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
 
-	if _, err := io.ReadFull(bufreader, payloadBytes); err != nil {
 		return nil, err
 	}
 
+	return header, nil
+}
+
+// decodePayload decodes all the records contained in the payload of a packet.
+func decodePayload(header *Header, payload []byte, options ReaderOptions) ([]Record, error) {
+	state := &decodeState{
+		options: options,
+	}
+
+	return state.decodePayload(header, payload)
+}
+
+// decodePayload decodes the records of payload with state, whose scratch buffer is reused from one payload to the next.
+func (state *decodeState) decodePayload(header *Header, payload []byte) ([]Record, error) {
 	read := 0
-	payload := bytes.NewReader(payloadBytes)
 	records := []Record{}
 
-	for read < header.PayloadLength {
-		record, err := ReadRecord(payload)
+	state.reset(header, payload)
+
+	for read < len(payload) {
+		record, err := readRecord(state.payload, state)
 
 		if err != nil {
 			return nil, err
@@ -503,57 +919,169 @@ func ReadPacket(r io.Reader, expectedCookie uint32) ([]Record, error) {
 		read += record.size
 	}
 
-	return records, err
+	return records, nil
 }
 
 // WritePacket creates a BINRPC packet (header and payload) containing values v, and writes it to w.
 // It returns the cookie generated, or an error if one occurred.
 func WritePacket[T ValidTypes](w io.Writer, values ...T) (uint32, error) {
+	cookie := RandomCookies.Cookie()
+
+	if err := WritePacketWithCookie(w, cookie, values...); err != nil {
+		return 0, err
+	}
+
+	return cookie, nil
+}
+
+// WritePacketWithCookie is like WritePacket, with the cookie of the packet chosen by the caller, which makes the
+// packet reproducible.
+func WritePacketWithCookie[T ValidTypes](w io.Writer, cookie uint32, values ...T) error {
 	if len(values) == 0 {
-		return 0, errors.New("missing values")
+		return errors.New("missing values")
 	}
 
-	var header bytes.Buffer
-	var payload bytes.Buffer
+	records := make([]*Record, 0, len(values))
 
 	for _, v := range values {
 		record, err := CreateRecord(v)
 
 		if err != nil {
-			return 0, err
+			return err
 		}
 
-		if err = record.Encode(&payload); err != nil {
-			return 0, err
+		records = append(records, record)
+	}
+
+	return writePacket(w, 0, cookie, records)
+}
+
+// WritePacketArgs is like WritePacket, but writes a call of method with args of any type accepted by Client.Call,
+// which can be mixed: ints, strings, doubles, structs (map[string]any or []StructItem) and arrays (slices):
+//
+//	cookie, err := binrpc.WritePacketArgs(conn, "htable.seti", "ipban", "10.0.0.1", 1)
+func WritePacketArgs(w io.Writer, method string, args ...any) (uint32, error) {
+	request, err := NewRequest(method, args...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = request.WriteTo(w); err != nil {
+		return 0, err
+	}
+
+	return request.Cookie, nil
+}
+
+// writePacket encodes records in a BINRPC packet using flags and cookie, and writes it to w.
+func writePacket(w io.Writer, flags uint8, cookie uint32, records []*Record) error {
+	var err error
+
+	packet := startPacket(nil)
+
+	for _, record := range records {
+		if packet, err = appendRecord(packet, record); err != nil {
+			return err
 		}
 	}
 
-	cookie := rand.Uint32()
-	cookieBytes := intToBytesBE(int(cookie))
-	lengthBE := intToBytesBE(payload.Len())
+	if packet, err = finishPacket(packet, 0, flags, cookie); err != nil {
+		return err
+	}
+
+	if _, err = w.Write(packet); err != nil {
+		return fmt.Errorf("cannot write packet: %w", err)
+	}
+
+	return nil
+}
+
+// AppendPacket appends a BINRPC packet containing records, with cookie, to dst and returns the extended buffer,
+// allocating only to grow dst. On error, dst is returned unchanged. Reusing the buffer, a poller encodes its requests
+// without allocating:
+//
+//	buffer, err = binrpc.AppendPacket(buffer[:0], cookie, method, arg)
+func AppendPacket(dst []byte, cookie uint32, records ...Record) ([]byte, error) {
+	packet, err := appendPacket(dst, 0, cookie, records)
 
-	if len(lengthBE) > MaxSizeOfLength {
-		return 0, fmt.Errorf("packet length too big: %d/%d bytes", len(lengthBE), MaxSizeOfLength)
+	if err != nil {
+		return dst, err
 	}
 
-	header.WriteByte(BinRPCMagic<<4 | BinRPCVersion)
-	header.WriteByte(byte((len(lengthBE)-1)<<2 | len(cookieBytes) - 1))
-	header.Write(lengthBE)
-	header.Write(cookieBytes)
+	return packet, nil
+}
 
-	writer := bufio.NewWriter(w)
+// appendPacket is the implementation of AppendPacket, with flags.
+func appendPacket(dst []byte, flags uint8, cookie uint32, records []Record) ([]byte, error) {
+	var err error
 
-	if _, err := writer.Write(header.Bytes()); err != nil {
-		return 0, fmt.Errorf("cannot write header: err=%v", err)
+	start := len(dst)
+	dst = startPacket(dst)
+
+	for i := range records {
+		if dst, err = appendRecord(dst, &records[i]); err != nil {
+			return nil, err
+		}
 	}
-	if _, err := writer.Write(payload.Bytes()); err != nil {
-		return 0, fmt.Errorf("cannot write payload: err=%v", err)
+
+	return finishPacket(dst, start, flags, cookie)
+}
+
+// maxHeaderSize is the size of the largest header: magic and version, flags and sizes, length, and cookie.
+const maxHeaderSize = 2 + MaxSizeOfLength + 4
+
+// startPacket reserves room for the header of a packet at the end of dst. The payload is appended after it.
+func startPacket(dst []byte) []byte {
+	return append(dst, make([]byte, maxHeaderSize)...)
+}
+
+// finishPacket writes the header of the packet started at start with flags and cookie, the payload following the
+// room reserved by startPacket. The payload is moved to follow the header, as the header is often shorter.
+func finishPacket(dst []byte, start int, flags uint8, cookie uint32) ([]byte, error) {
+	payload := start + maxHeaderSize
+
+	header, err := appendHeader(dst[start:start], flags, cookie, len(dst)-payload)
+
+	if err != nil {
+		return nil, err
 	}
-	if err := writer.Flush(); err != nil {
-		return 0, err
+
+	n := copy(dst[start+len(header):], dst[payload:])
+
+	return dst[:start+len(header)+n], nil
+}
+
+// appendHeader appends the header of a packet with flags, cookie, and a payload of length bytes.
+func appendHeader(dst []byte, flags uint8, cookie uint32, length int) ([]byte, error) {
+	sizeOfCookie := getMinBinarySizeOfInt(int(cookie))
+	sizeOfLength := getMinBinarySizeOfInt(length)
+
+	if int64(length) > math.MaxUint32 {
+		return nil, fmt.Errorf("packet length too big: %d bytes", length)
 	}
 
-	return cookie, nil
+	// the header has at least one byte for each, even when zero
+	sizeOfCookie = max(sizeOfCookie, 1)
+	sizeOfLength = max(sizeOfLength, 1)
+
+	dst = append(dst, BinRPCMagic<<4|BinRPCVersion)
+	dst = append(dst, flags<<4|(sizeOfLength-1)<<2|(sizeOfCookie-1))
+	dst = appendIntBESize(dst, length, sizeOfLength)
+
+	return appendIntBESize(dst, int(cookie), sizeOfCookie), nil
+}
+
+// decodeInt returns the int encoded in buf, in big endian. The value is read unsigned: ff ff ff ff is 4294967295, as
+// the unsigned counters of Kamailio, such as the memory sizes, may exceed 2^31.
+func decodeInt(buf []byte) int {
+	n := 0
+
+	for _, b := range buf {
+		n = n<<8 + int(b)
+	}
+
+	return n
 }
 
 // getMinBinarySizeOfInt returns the minimum size in bytes required to store an integer.
@@ -568,15 +1096,16 @@ func getMinBinarySizeOfInt(value int) uint8 {
 	return size
 }
 
-func intToBytesBE(n int) []byte {
-	size := getMinBinarySizeOfInt(n)
-	bytes := make([]byte, size)
+// appendIntBE appends n in big endian, on the minimum number of bytes: none for zero.
+func appendIntBE(dst []byte, n int) []byte {
+	return appendIntBESize(dst, n, getMinBinarySizeOfInt(n))
+}
 
+// appendIntBESize appends the size least significant bytes of n in big endian.
+func appendIntBESize(dst []byte, n int, size uint8) []byte {
 	for ; size > 0; size-- {
-		// big endian
-		bytes[size-1] = byte(n)
-		n >>= 8
+		dst = append(dst, byte(n>>(8*(size-1))))
 	}
 
-	return bytes
+	return dst
 }
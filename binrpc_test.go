@@ -3,9 +3,13 @@ package binrpc
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestReadHeader(t *testing.T) {
@@ -78,6 +82,49 @@ func TestReadRecordInt(t *testing.T) {
 	}
 }
 
+func TestReadRecordUnsigned(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected any
+	}{
+		// 4 bytes are read unsigned
+		{"40ffffffff", 0xffffffff},
+		{"40c0000000", 0xc0000000},
+		{"42c0000000", 3221225.472},
+		{"30ffffff", 0xffffff},
+	}
+
+	for _, test := range tests {
+		data, _ := hex.DecodeString(test.raw)
+
+		record, err := ReadRecord(bytes.NewReader(data))
+
+		if err != nil {
+			t.Errorf("%s: %v", test.raw, err)
+		} else if record.Value != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.raw, test.expected, record.Value)
+		}
+	}
+
+	// a shm size of 3 GiB
+	data, _ := hex.DecodeString("40c0000000")
+	record, err := ReadRecord(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var shm struct {
+		Total uint64
+	}
+
+	shmem := Record{Type: TypeStruct, Value: []StructItem{{Key: "total", Value: *record}}}
+
+	if err = shmem.Scan(&shm); err != nil || shm.Total != 0xc0000000 {
+		t.Errorf("expected %d, got %d (%v)", uint64(0xc0000000), shm.Total, err)
+	}
+}
+
 func TestReadRecordStruct(t *testing.T) {
 	raw := "03950863757272656e74001001950877616974696e6700100165746f74616c00308929f5950c746f74616c5f6c6f63616c00302396c7950d72706c5f7265636569766564004001276f74950e72706c5f67656e65726174656400304b8e01950972706c5f73656e74004001277f7e4536787800201cea45357878003022e3d24534787800300e98fa45337878000045327878003057b03895086372656174656400308929f565667265656400308929f4950d64656c617965645f66726565000083"
 	data, _ := hex.DecodeString(raw)
@@ -145,6 +192,31 @@ func TestWritePacket(t *testing.T) {
 	}
 }
 
+func TestWritePacketLarge(t *testing.T) {
+	var buffer bytes.Buffer
+
+	// a payload needing 2 bytes for its length, and a cookie needing 4 bytes
+	value := strings.Repeat("x", 1000)
+
+	if err := writePacket(&buffer, 0, 0x12345678, []*Record{{Type: TypeString, Value: value}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := byte(0x07); buffer.Bytes()[1] != expected {
+		t.Errorf("expected sizes byte %02x, got %02x", expected, buffer.Bytes()[1])
+	}
+
+	records, err := ReadPacket(&buffer, 0x12345678)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, _ := records[0].String(); s != value {
+		t.Errorf("expected a string of %d bytes, got %d bytes", len(value), len(s))
+	}
+}
+
 func TestWritePacketInt(t *testing.T) {
 	expectedHeader, _ := hex.DecodeString("a10302")
 	expectedRecord, _ := hex.DecodeString("108e")
@@ -202,6 +274,49 @@ func TestCreateRecord(t *testing.T) {
 	}
 }
 
+func TestReadShortReads(t *testing.T) {
+	var buf bytes.Buffer
+
+	cookie, err := WritePacketArgs(&buf, "core.echo", strings.Repeat("x", 300), 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each Read returns a single byte, like a slow TCP link
+	r := iotest.OneByteReader(bytes.NewReader(buf.Bytes()))
+
+	header, err := ReadHeader(r)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Cookie != cookie {
+		t.Errorf("expected cookie %d, got %d", cookie, header.Cookie)
+	}
+
+	var values []any
+
+	for i := 0; i < 3; i++ {
+		record, err := ReadRecord(r)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		values = append(values, record.Value)
+	}
+
+	if fmt.Sprint(values) != fmt.Sprint([]any{"core.echo", strings.Repeat("x", 300), 42}) {
+		t.Errorf("unexpected records %v", values)
+	}
+
+	if _, err = ReadHeader(iotest.OneByteReader(bytes.NewReader(buf.Bytes()[:3]))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
 func TestReadPacket(t *testing.T) {
 	raw := "a1322a9883af2001f49125636f6d6d616e6420636f72652e6563686f20626f6e6a6f757273206e6f7420666f756e6400"
 	data, _ := hex.DecodeString(raw)
@@ -209,8 +324,14 @@ func TestReadPacket(t *testing.T) {
 
 	response, err := ReadPacket(bytes.NewReader(data), cookie)
 
-	if err != nil {
-		t.Error(err)
+	var fault *RPCError
+
+	if !errors.As(err, &fault) {
+		t.Fatalf("expected an *RPCError, got %v", err)
+	}
+
+	if fault.Code != 500 || fault.Message != "command core.echo bonjours not found" {
+		t.Errorf(`expected "500 command core.echo bonjours not found", got "%s"`, fault)
 	}
 
 	if len(response) != 2 {
@@ -421,3 +542,188 @@ func ExampleWritePacket_structResponseScan() {
 		)
 	}
 }
+
+func TestReadRecordArray(t *testing.T) {
+	// an array containing a named int ("SET" = 1) and a string
+	data, _ := hex.DecodeString("04" + "455345540010012161" + "00" + "84")
+
+	record, err := ReadRecord(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, err := record.Array()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(elements) != 2 {
+		t.Fatalf("expected 2 elements, got %d", len(elements))
+	}
+
+	items, err := elements[0].StructItems()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if items[0].Key != "SET" || items[0].Value.Value != 1 {
+		t.Errorf(`expected "SET" = 1, got "%s" = %v`, items[0].Key, items[0].Value.Value)
+	}
+
+	if value, _ := elements[1].String(); value != "a" {
+		t.Errorf(`expected "a", got "%s"`, value)
+	}
+
+	if record.size != len(data) {
+		t.Errorf("expected size %d, got %d", len(data), record.size)
+	}
+}
+
+func TestReadRecordArrayNested(t *testing.T) {
+	// an array containing a struct ("a" = 1), as returned by dlg.list, and an array containing a string
+	data, _ := hex.DecodeString("04" + "03" + "256100" + "1001" + "83" + "04" + "217800" + "84" + "84")
+
+	record, err := ReadRecord(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, err := record.Array()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(elements) != 2 {
+		t.Fatalf("expected 2 elements, got %d", len(elements))
+	}
+
+	if items, err := elements[0].StructItems(); err != nil || items[0].Key != "a" || items[0].Value.Value != 1 {
+		t.Errorf(`expected "a" = 1, got %v (%v)`, items, err)
+	}
+
+	inner, err := elements[1].Array()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := inner[0].String(); len(inner) != 1 || value != "x" {
+		t.Errorf(`expected ["x"], got %v`, inner)
+	}
+
+	if record.size != len(data) {
+		t.Errorf("expected size %d, got %d", len(data), record.size)
+	}
+}
+
+func TestEncodeStruct(t *testing.T) {
+	record := Record{
+		Type: TypeStruct,
+		Value: []StructItem{
+			{Key: "id", Value: Record{Type: TypeInt, Value: 1}},
+		},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := record.Encode(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	// struct start, avp "id", int 1, struct end
+	expected, _ := hex.DecodeString("03356964001001" + "83")
+
+	if !bytes.Equal(expected, buffer.Bytes()) {
+		t.Errorf("output differ, expected %x, got %x", expected, buffer.Bytes())
+	}
+
+	decoded, err := ReadRecord(&buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := decoded.StructItems()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].Key != "id" {
+		t.Errorf("expected item id, got %v", items)
+	}
+}
+
+func TestWritePacketArgs(t *testing.T) {
+	var buffer bytes.Buffer
+
+	cookie, err := WritePacketArgs(&buffer, "app.update", 1, map[string]any{"uri": "sip:10.0.0.1"}, []int{1, 2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadPacket(&buffer, cookie)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 4 || records[2].Type != TypeStruct || records[3].Type != TypeArray {
+		t.Fatalf("unexpected records %v", records)
+	}
+
+	var elements []int
+
+	if err = records[3].Scan(&elements); err != nil || len(elements) != 2 || elements[1] != 2 {
+		t.Errorf("expected [1 2], got %v (%v)", elements, err)
+	}
+
+	if _, err = WritePacketArgs(&buffer, "app.update", struct{}{}); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestTypeBytes(t *testing.T) {
+	value := []byte{'a', 0x00, 'b', 0xFF}
+	expectedRecord := []byte{0x46, 'a', 0x00, 'b', 0xFF}
+
+	record, err := CreateRecord(value)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	if err = record.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), expectedRecord) {
+		t.Errorf("expected record %x, got %x", expectedRecord, buf.Bytes())
+	}
+
+	decoded, err := ReadRecord(&buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := decoded.Bytes(); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(b, value) {
+		t.Errorf("expected value %q, got %q", value, b)
+	}
+
+	var s string
+
+	if err = decoded.Scan(&s); err != nil || s != string(value) {
+		t.Errorf("expected string %q, got %q (%v)", value, s, err)
+	}
+}
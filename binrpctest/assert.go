@@ -0,0 +1,104 @@
+package binrpctest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// AssertCalled fails the test if no call of method with args was received. Args are compared as in Call.Matches.
+func (s *Server) AssertCalled(t testing.TB, method string, args ...any) {
+	t.Helper()
+
+	for _, call := range s.CallsTo(method) {
+		if call.Matches(method, args...) {
+			return
+		}
+	}
+
+	t.Errorf("binrpctest: expected a call of %s %v, got %s", method, args, s.describe())
+}
+
+// AssertNotCalled fails the test if a call of method was received.
+func (s *Server) AssertNotCalled(t testing.TB, method string) {
+	t.Helper()
+
+	if calls := s.CallsTo(method); len(calls) > 0 {
+		t.Errorf("binrpctest: expected no call of %s, got %d", method, len(calls))
+	}
+}
+
+// AssertCalls fails the test unless the methods of the calls received are exactly methods, in order.
+func (s *Server) AssertCalls(t testing.TB, methods ...string) {
+	t.Helper()
+
+	calls := s.Calls()
+	received := make([]string, 0, len(calls))
+
+	for _, call := range calls {
+		received = append(received, call.Method)
+	}
+
+	if strings.Join(received, ",") != strings.Join(methods, ",") {
+		t.Errorf("binrpctest: expected calls %v, got %v", methods, received)
+	}
+}
+
+// describe returns the calls received, for error messages.
+func (s *Server) describe() string {
+	calls := s.Calls()
+
+	if len(calls) == 0 {
+		return "no calls"
+	}
+
+	var description strings.Builder
+
+	for i, call := range calls {
+		if i > 0 {
+			description.WriteString(", ")
+		}
+
+		description.WriteString(call.Method)
+
+		for _, arg := range call.Args {
+			description.WriteString(" ")
+			description.WriteString(describeRecord(arg))
+		}
+	}
+
+	return description.String()
+}
+
+// describeRecord returns the value of record, in Go syntax for scalars.
+func describeRecord(record binrpc.Record) string {
+	switch record.Type {
+	case binrpc.TypeString:
+		return strconv.Quote(record.Value.(string))
+	case binrpc.TypeBytes:
+		return fmt.Sprintf("[]byte(%q)", record.Value.([]byte))
+	case binrpc.TypeStruct:
+		items, _ := record.StructItems()
+		parts := make([]string, 0, len(items))
+
+		for _, item := range items {
+			parts = append(parts, item.Key+": "+describeRecord(item.Value))
+		}
+
+		return "{" + strings.Join(parts, ", ") + "}"
+	case binrpc.TypeArray:
+		elements, _ := record.Array()
+		parts := make([]string, 0, len(elements))
+
+		for _, element := range elements {
+			parts = append(parts, describeRecord(element))
+		}
+
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprint(record.Value)
+	}
+}
@@ -0,0 +1,196 @@
+// Package binrpctest provides a BINRPC server for testing code that calls Kamailio.
+//
+// The server replies to calls with the handlers registered for their method, and records the calls it receives so
+// that tests can check them:
+//
+//	server := binrpctest.NewServer()
+//	defer server.Close()
+//
+//	server.Reply("dispatcher.reload", "ok")
+//
+//	client, err := binrpc.Dial("tcp", server.Addr)
+//
+//	// ... code under test
+//
+//	server.AssertCalled(t, "dispatcher.reload")
+package binrpctest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// Call is a call received by a Server.
+type Call struct {
+	Method string
+	Args   []binrpc.Record
+}
+
+// Handler returns the values of the reply to call, or an error. An *binrpc.RPCError is sent as a fault, other errors
+// as faults with code 500.
+type Handler func(call Call) ([]any, error)
+
+// Server is a BINRPC server listening on the loopback interface, built on binrpc.Server.
+// Methods without handler are replied with a "500 command not found" fault, like Kamailio does, and
+// system.listMethods returns the methods registered, unless it has its own handler.
+type Server struct {
+	// Addr is the TCP address of the server, such as "127.0.0.1:43210".
+	Addr string
+
+	server *binrpc.Server
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewServer starts and returns a Server. It must be closed with Close.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		panic(fmt.Sprintf("binrpctest: failed to listen: %v", err))
+	}
+
+	s := &Server{
+		Addr:   listener.Addr().String(),
+		server: binrpc.NewServer(),
+	}
+
+	s.server.Intercept(s.record)
+
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		s.server.Serve(listener)
+	}()
+
+	return s
+}
+
+// Close stops the server, and closes its connections.
+func (s *Server) Close() {
+	s.server.Close()
+	s.wg.Wait()
+}
+
+// Handle registers handler for method, replacing the previous one if any.
+func (s *Server) Handle(method string, handler Handler) {
+	s.server.Handle(method, func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+		return handler(newCall(request))
+	})
+}
+
+// Reply registers a handler replying to method with values.
+func (s *Server) Reply(method string, values ...any) {
+	s.Handle(method, func(Call) ([]any, error) {
+		return values, nil
+	})
+}
+
+// Fail registers a handler replying to method with a fault.
+func (s *Server) Fail(method string, code int, message string) {
+	s.Handle(method, func(Call) ([]any, error) {
+		return nil, &binrpc.RPCError{Code: code, Message: message}
+	})
+}
+
+// record is the interceptor recording the requests, whether their method has a handler or not.
+func (s *Server) record(next binrpc.Handler) binrpc.Handler {
+	return func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+		s.mu.Lock()
+		s.calls = append(s.calls, newCall(request))
+		s.mu.Unlock()
+
+		return next(ctx, request)
+	}
+}
+
+// newCall returns the call of request.
+func newCall(request *binrpc.Request) Call {
+	call := Call{
+		Method: request.Method,
+		Args:   make([]binrpc.Record, 0, len(request.Args)),
+	}
+
+	for _, arg := range request.Args {
+		call.Args = append(call.Args, arg.(binrpc.Record))
+	}
+
+	return call
+}
+
+// Calls returns the calls received, in order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the calls of method received, in order.
+func (s *Server) CallsTo(method string) []Call {
+	var calls []Call
+
+	for _, call := range s.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset forgets the calls received.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+}
+
+// Matches reports whether call is a call of method with args. Args are compared once encoded, so valid args are those
+// of binrpc.Client.Call.
+func (call Call) Matches(method string, args ...any) bool {
+	if call.Method != method {
+		return false
+	}
+
+	expected, err := binrpc.NewRequest(method, args...)
+
+	if err != nil {
+		return false
+	}
+
+	actual := make([]any, 0, len(call.Args))
+
+	for _, arg := range call.Args {
+		actual = append(actual, arg)
+	}
+
+	received, err := binrpc.NewRequest(method, actual...)
+
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(decode(received), decode(expected))
+}
+
+// decode returns the args of request once encoded and decoded, so that they can be compared.
+func decode(request *binrpc.Request) []any {
+	var packet bytes.Buffer
+
+	request.WriteTo(&packet)
+	decoded, _ := binrpc.ReadRequest(&packet)
+
+	return decoded.Args
+}
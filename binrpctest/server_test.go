@@ -0,0 +1,142 @@
+package binrpctest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// recorder records the failures of assertions.
+type recorder struct {
+	testing.TB
+
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func dial(t *testing.T, server *Server) *binrpc.Client {
+	client, err := binrpc.Dial("tcp", server.Addr)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Reply("core.uptime", map[string]any{"uptime": 42})
+	server.Fail("dispatcher.reload", 500, "Reload failed")
+
+	client := dial(t, server)
+
+	records, err := client.Call("core.uptime")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var uptime struct {
+		Uptime int
+	}
+
+	if err = records[0].Scan(&uptime); err != nil || uptime.Uptime != 42 {
+		t.Errorf("expected uptime 42, got %d (%v)", uptime.Uptime, err)
+	}
+
+	var fault *binrpc.RPCError
+
+	if _, err = client.Call("dispatcher.reload"); !errors.As(err, &fault) || fault.Message != "Reload failed" {
+		t.Errorf("expected the registered fault, got %v", err)
+	}
+
+	if _, err = client.Call("core.unknown"); !errors.As(err, &fault) || fault.Code != 500 {
+		t.Errorf("expected a 500 fault, got %v", err)
+	}
+
+	server.Handle("core.echo", func(call Call) ([]any, error) {
+		return []any{call.Args[0]}, nil
+	})
+
+	if records, err = client.Call("core.echo", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "hello" {
+		t.Errorf(`expected "hello", got "%s"`, value)
+	}
+
+	if records, err = client.Call("system.listMethods"); err != nil {
+		t.Fatal(err)
+	}
+
+	var methods []string
+
+	if err = binrpc.Unmarshal(records, &methods); err != nil || len(methods) != 3 {
+		t.Errorf("expected 3 methods, got %v (%v)", methods, err)
+	}
+
+	server.AssertCalls(t, "core.uptime", "dispatcher.reload", "core.unknown", "core.echo", "system.listMethods")
+}
+
+func TestServerAssertions(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Reply("htable.sets", "ok")
+	server.Reply("htable.reload", "ok")
+
+	client := dial(t, server)
+
+	client.Call("htable.sets", "users", "alice", "active")
+	client.Call("htable.reload", "users")
+
+	server.AssertCalls(t, "htable.sets", "htable.reload")
+	server.AssertCalled(t, "htable.sets", "users", "alice", "active")
+	server.AssertNotCalled(t, "dispatcher.reload")
+
+	if calls := server.CallsTo("htable.reload"); len(calls) != 1 || !calls[0].Matches("htable.reload", "users") {
+		t.Errorf("expected one call of htable.reload, got %v", calls)
+	}
+
+	r := &recorder{TB: t}
+
+	server.AssertCalled(r, "htable.sets", "users", "bob", "active")
+	server.AssertCalled(r, "htable.reload", 1)
+	server.AssertNotCalled(r, "htable.reload")
+	server.AssertCalls(r, "htable.reload", "htable.sets")
+
+	if len(r.failures) != 4 {
+		t.Errorf("expected 4 failures, got %d: %v", len(r.failures), r.failures)
+	}
+
+	server.Reset()
+	server.AssertCalls(t)
+}
+
+func TestCallMatchesComposite(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Reply("app.update", "ok")
+
+	client := dial(t, server)
+
+	if _, err := client.Call("app.update", map[string]any{"id": 1, "tags": []string{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.AssertCalled(t, "app.update", map[string]any{"id": 1, "tags": []any{"a", "b"}})
+}
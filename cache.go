@@ -0,0 +1,174 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache wraps a Client and memoizes the replies of configured methods for a duration.
+// Identical calls made while a call is in flight wait for its reply instead of issuing their own.
+//
+// Calling a mutating method through a Cache invalidates the cached replies it makes stale, see RegisterMutating.
+//
+// Records returned by a Cache are shared between callers and must not be modified.
+type Cache struct {
+	client *Client
+	ttls   map[string]time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	method string
+
+	// done is closed once records and err are set
+	done    chan struct{}
+	records []Record
+	err     error
+	expires time.Time
+}
+
+// NewCache returns a Cache calling client. ttls maps the methods to cache to the duration their replies are kept.
+// Calls to other methods are passed through.
+func NewCache(client *Client, ttls map[string]time.Duration) *Cache {
+	return &Cache{
+		client:  client,
+		ttls:    ttls,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Call is like Client.Call, but returns the cached reply of an identical call if any.
+func (c *Cache) Call(method string, args ...any) ([]Record, error) {
+	return c.CallContext(context.Background(), method, args...)
+}
+
+// CallContext is like Client.CallContext, but returns the cached reply of an identical call if any.
+// Errors are not cached. If the call in flight fails because the context of its caller is done, the calls waiting for
+// it make their own call.
+func (c *Cache) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	if invalidates, ok := invalidatedBy(method); ok {
+		records, err := c.client.CallContext(ctx, method, args...)
+
+		// even a failed call may have changed something
+		c.invalidate(invalidates)
+
+		return records, err
+	}
+
+	ttl, ok := c.ttls[method]
+
+	if !ok {
+		return c.client.CallContext(ctx, method, args...)
+	}
+
+	key := cacheKey(method, args)
+
+	c.mu.Lock()
+
+	now := time.Now()
+
+	if entry, ok := c.entries[key]; ok && !(isDone(entry.done) && now.After(entry.expires)) {
+		c.mu.Unlock()
+
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// the call failed because the context of its caller is done, not ctx
+		if isContextError(entry.err) && ctx.Err() == nil {
+			return c.CallContext(ctx, method, args...)
+		}
+
+		return entry.records, entry.err
+	}
+
+	c.expire(now)
+
+	entry := &cacheEntry{
+		method: method,
+		done:   make(chan struct{}),
+	}
+
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	entry.records, entry.err = c.client.CallContext(ctx, method, args...)
+	entry.expires = time.Now().Add(ttl)
+
+	c.mu.Lock()
+
+	if entry.err != nil && c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+
+	c.mu.Unlock()
+	close(entry.done)
+
+	return entry.records, entry.err
+}
+
+// Purge removes all the cached replies.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if isDone(entry.done) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidate removes the replies of the methods matching patterns, including the calls in flight.
+func (c *Cache) invalidate(patterns []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		for _, pattern := range patterns {
+			if matchMethod(pattern, entry.method) {
+				delete(c.entries, key)
+				break
+			}
+		}
+	}
+}
+
+// expire removes the expired replies. It must be called with c.mu held.
+func (c *Cache) expire(now time.Time) {
+	for key, entry := range c.entries {
+		if isDone(entry.done) && now.After(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// isDone reports whether done is closed.
+func isDone(done chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// cacheKey returns the key identifying a call of method with args.
+func cacheKey(method string, args []any) string {
+	var key strings.Builder
+
+	key.WriteString(method)
+
+	for _, arg := range args {
+		fmt.Fprintf(&key, "\x00%T:%v", arg, arg)
+	}
+
+	return key.String()
+}
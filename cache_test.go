@@ -0,0 +1,151 @@
+package binrpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	var calls atomic.Int32
+
+	client := serve(t, func(records []Record) []any {
+		calls.Add(1)
+		return echo(records)
+	})
+
+	cache := NewCache(client, map[string]time.Duration{
+		"tm.stats": 50 * time.Millisecond,
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.Call("tm.stats"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+
+	// different args are a different key
+	if _, err := cache.Call("tm.stats", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// not cached
+	if _, err := cache.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := calls.Load(); n != 4 {
+		t.Errorf("expected 4 calls, got %d", n)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if _, err := cache.Call("tm.stats"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := calls.Load(); n != 5 {
+		t.Errorf("expected 5 calls after expiration, got %d", n)
+	}
+}
+
+func TestCacheInFlight(t *testing.T) {
+	var calls atomic.Int32
+
+	client := serve(t, func(records []Record) []any {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return echo(records)
+	})
+
+	cache := NewCache(client, map[string]time.Duration{
+		"tm.stats": time.Minute,
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := cache.Call("tm.stats"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected 1 call, got %d", n)
+	}
+}
+
+func TestCacheInFlightCanceled(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		time.Sleep(50 * time.Millisecond)
+		return echo(records)
+	})
+
+	cache := NewCache(client, map[string]time.Duration{
+		"tm.stats": time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	first := make(chan error, 1)
+
+	go func() {
+		_, err := cache.CallContext(ctx, "tm.stats")
+		first <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+
+	// the first call times out: the second one makes its own call
+	if _, err := cache.Call("tm.stats"); err != nil {
+		t.Error(err)
+	}
+
+	if err := <-first; err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	var calls atomic.Int32
+
+	client := serve(t, func(records []Record) []any {
+		calls.Add(1)
+		return echo(records)
+	})
+
+	cache := NewCache(client, map[string]time.Duration{
+		"dispatcher.list": time.Minute,
+		"htable.dump":     time.Minute,
+	})
+
+	for _, method := range []string{"dispatcher.list", "htable.dump", "dispatcher.reload", "dispatcher.list", "htable.dump"} {
+		if _, err := cache.Call(method); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// dispatcher.list is called again after dispatcher.reload, htable.dump is still cached
+	if n := calls.Load(); n != 4 {
+		t.Errorf("expected 4 calls, got %d", n)
+	}
+}
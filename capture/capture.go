@@ -0,0 +1,402 @@
+// Package capture decodes the BINRPC packets exchanged in a pcap capture, such as one written by
+// "tcpdump -i any -w ctl.pcap port 2049".
+//
+// TCP streams are reassembled before decoding, so packets spanning several segments are found. Only the classic pcap
+// format is supported, with Ethernet, Linux cooked (v1 and v2), BSD loopback and raw IP link types. Use
+// "tshark -F pcap" or "editcap -F pcap" to convert a pcapng capture.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// Packet is a BINRPC packet found in a capture.
+type Packet struct {
+	// Time is the capture time of the first byte of the packet.
+	Time time.Time
+
+	// Transport is "tcp" or "udp", Src and Dst are the addresses of the endpoints, such as "10.0.0.1:2049".
+	Transport string
+	Src       string
+	Dst       string
+
+	Header  *binrpc.Header
+	Records []binrpc.Record
+}
+
+// ErrNotPcap is returned when the data read is not a pcap capture.
+var ErrNotPcap = errors.New("not a pcap capture")
+
+const (
+	linkTypeNull   = 0
+	linkTypeEther  = 1
+	linkTypeRaw    = 101
+	linkTypeSLL    = 113
+	linkTypeSLL2   = 276
+	linkTypeRawAlt = 12
+)
+
+// IsPcap reports whether data starts like a pcap capture.
+func IsPcap(data []byte) bool {
+	_, _, err := readMagic(data)
+
+	return err == nil
+}
+
+// Read reads a pcap capture from r, and returns the BINRPC packets sent from or to port, in capture order.
+// If port is zero, all the TCP and UDP traffic is decoded, which may find packets in unrelated traffic.
+//
+// Bytes that cannot be decoded, for instance when the capture starts in the middle of a packet, are skipped.
+func Read(r io.Reader, port int) ([]Packet, error) {
+	reader := bufio.NewReader(r)
+	globalHeader := make([]byte, 24)
+
+	if _, err := io.ReadFull(reader, globalHeader); err != nil {
+		return nil, ErrNotPcap
+	}
+
+	order, nanoseconds, err := readMagic(globalHeader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	linkType := order.Uint32(globalHeader[20:24]) & 0x0FFFFFFF
+
+	flows := make(map[flowKey]*flow)
+
+	var keys []flowKey
+	var packets []Packet
+
+	recordHeader := make([]byte, 16)
+
+	for {
+		if _, err = io.ReadFull(reader, recordHeader); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("truncated capture: %w", err)
+		}
+
+		seconds := int64(order.Uint32(recordHeader[0:4]))
+		fraction := int64(order.Uint32(recordHeader[4:8]))
+		length := order.Uint32(recordHeader[8:12])
+
+		if !nanoseconds {
+			fraction *= 1000
+		}
+
+		data := make([]byte, length)
+
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return nil, fmt.Errorf("truncated capture: %w", err)
+		}
+
+		s, ok := parseFrame(linkType, data)
+
+		if !ok || (port != 0 && s.key.srcPort != port && s.key.dstPort != port) {
+			continue
+		}
+
+		s.time = time.Unix(seconds, fraction)
+
+		if s.key.transport == "udp" {
+			packets = append(packets, decodeStream(s.key, []segment{s})...)
+			continue
+		}
+
+		f, ok := flows[s.key]
+
+		if !ok {
+			f = &flow{}
+			flows[s.key] = f
+			keys = append(keys, s.key)
+		}
+
+		f.segments = append(f.segments, s)
+	}
+
+	for _, key := range keys {
+		packets = append(packets, decodeStream(key, flows[key].reassemble())...)
+	}
+
+	sort.SliceStable(packets, func(i, j int) bool {
+		return packets[i].Time.Before(packets[j].Time)
+	})
+
+	return packets, nil
+}
+
+// readMagic returns the byte order and the timestamp precision of a pcap capture.
+func readMagic(data []byte) (binary.ByteOrder, bool, error) {
+	if len(data) < 4 {
+		return nil, false, ErrNotPcap
+	}
+
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(data) {
+		case 0xa1b2c3d4:
+			return order, false, nil
+		case 0xa1b23c4d:
+			return order, true, nil
+		}
+	}
+
+	return nil, false, ErrNotPcap
+}
+
+// flowKey identifies one direction of a TCP or UDP flow.
+type flowKey struct {
+	transport string
+	src, dst  string
+	srcPort   int
+	dstPort   int
+}
+
+// segment is the payload of a TCP segment or of a UDP datagram.
+type segment struct {
+	key     flowKey
+	time    time.Time
+	seq     uint32
+	payload []byte
+}
+
+type flow struct {
+	segments []segment
+}
+
+// reassemble returns the segments of the flow in sequence order, without retransmissions and overlaps.
+func (f *flow) reassemble() []segment {
+	if len(f.segments) == 0 {
+		return nil
+	}
+
+	// sequence numbers relative to the first segment, to handle wrapping
+	first := f.segments[0].seq
+
+	sort.SliceStable(f.segments, func(i, j int) bool {
+		return int32(f.segments[i].seq-first) < int32(f.segments[j].seq-first)
+	})
+
+	var ordered []segment
+
+	next := f.segments[0].seq
+
+	for _, s := range f.segments {
+		offset := int32(next - s.seq)
+
+		if offset >= int32(len(s.payload)) {
+			// retransmission of data already seen
+			continue
+		}
+
+		if offset > 0 {
+			s.payload = s.payload[offset:]
+			s.seq = next
+		}
+
+		ordered = append(ordered, s)
+		next = s.seq + uint32(len(s.payload))
+	}
+
+	return ordered
+}
+
+// decodeStream decodes the packets contained in the payloads of segments, which are contiguous.
+func decodeStream(key flowKey, segments []segment) []Packet {
+	var stream []byte
+	var starts []int
+
+	for _, s := range segments {
+		starts = append(starts, len(stream))
+		stream = append(stream, s.payload...)
+	}
+
+	var packets []Packet
+
+	for offset := 0; offset < len(stream); {
+		if stream[offset] != binrpc.BinRPCMagic<<4|binrpc.BinRPCVersion {
+			offset++
+			continue
+		}
+
+		reader := bytes.NewReader(stream[offset:])
+		header, records, err := binrpc.DecodePacket(reader, binrpc.ReaderOptions{})
+
+		if err != nil {
+			offset++
+			continue
+		}
+
+		// the segment containing the first byte of the packet
+		i := sort.Search(len(starts), func(i int) bool { return starts[i] > offset }) - 1
+
+		packets = append(packets, Packet{
+			Time:      segments[i].time,
+			Transport: key.transport,
+			Src:       net.JoinHostPort(key.src, strconv.Itoa(key.srcPort)),
+			Dst:       net.JoinHostPort(key.dst, strconv.Itoa(key.dstPort)),
+			Header:    header,
+			Records:   records,
+		})
+
+		offset += header.Size() + header.PayloadLength
+	}
+
+	return packets
+}
+
+// parseFrame returns the TCP or UDP payload of a frame of linkType.
+func parseFrame(linkType uint32, data []byte) (segment, bool) {
+	var etherType uint16
+
+	switch linkType {
+	case linkTypeEther:
+		if len(data) < 14 {
+			return segment{}, false
+		}
+
+		etherType, data = binary.BigEndian.Uint16(data[12:14]), data[14:]
+
+		// 802.1Q VLAN tags
+		for etherType == 0x8100 && len(data) >= 4 {
+			etherType, data = binary.BigEndian.Uint16(data[2:4]), data[4:]
+		}
+	case linkTypeSLL:
+		if len(data) < 16 {
+			return segment{}, false
+		}
+
+		etherType, data = binary.BigEndian.Uint16(data[14:16]), data[16:]
+	case linkTypeSLL2:
+		if len(data) < 20 {
+			return segment{}, false
+		}
+
+		etherType, data = binary.BigEndian.Uint16(data[0:2]), data[20:]
+	case linkTypeNull:
+		if len(data) < 4 {
+			return segment{}, false
+		}
+
+		// the address family is in host byte order, 2 is AF_INET and larger values are AF_INET6 on all systems
+		family := binary.LittleEndian.Uint32(data[0:4])
+
+		if family > 0xFFFF {
+			family = binary.BigEndian.Uint32(data[0:4])
+		}
+
+		etherType, data = 0x0800, data[4:]
+
+		if family != 2 {
+			etherType = 0x86DD
+		}
+	case linkTypeRaw, linkTypeRawAlt:
+		if len(data) == 0 {
+			return segment{}, false
+		}
+
+		etherType = 0x0800
+
+		if data[0]>>4 == 6 {
+			etherType = 0x86DD
+		}
+	default:
+		return segment{}, false
+	}
+
+	return parseIP(etherType, data)
+}
+
+// parseIP returns the TCP or UDP payload of an IPv4 or IPv6 packet.
+func parseIP(etherType uint16, data []byte) (segment, bool) {
+	var s segment
+	var protocol uint8
+
+	switch etherType {
+	case 0x0800:
+		if len(data) < 20 {
+			return s, false
+		}
+
+		headerLength := int(data[0]&0x0F) * 4
+		totalLength := int(binary.BigEndian.Uint16(data[2:4]))
+
+		// fragments are not reassembled
+		if flagsOffset := binary.BigEndian.Uint16(data[6:8]); flagsOffset&0x3FFF != 0 {
+			return s, false
+		}
+
+		if headerLength < 20 || totalLength < headerLength || len(data) < headerLength {
+			return s, false
+		}
+
+		if totalLength < len(data) {
+			// Ethernet padding
+			data = data[:totalLength]
+		}
+
+		protocol = data[9]
+		s.key.src = net.IP(data[12:16]).String()
+		s.key.dst = net.IP(data[16:20]).String()
+		data = data[headerLength:]
+	case 0x86DD:
+		if len(data) < 40 {
+			return s, false
+		}
+
+		payloadLength := int(binary.BigEndian.Uint16(data[4:6]))
+
+		protocol = data[6]
+		s.key.src = net.IP(data[8:24]).String()
+		s.key.dst = net.IP(data[24:40]).String()
+		data = data[40:]
+
+		if payloadLength < len(data) {
+			data = data[:payloadLength]
+		}
+	default:
+		return s, false
+	}
+
+	switch protocol {
+	case 6:
+		if len(data) < 20 {
+			return s, false
+		}
+
+		offset := int(data[12]>>4) * 4
+
+		if offset < 20 || len(data) < offset {
+			return s, false
+		}
+
+		s.key.transport = "tcp"
+		s.seq = binary.BigEndian.Uint32(data[4:8])
+		s.payload = data[offset:]
+	case 17:
+		if len(data) < 8 {
+			return s, false
+		}
+
+		s.key.transport = "udp"
+		s.payload = data[8:]
+	default:
+		return s, false
+	}
+
+	s.key.srcPort = int(binary.BigEndian.Uint16(data[0:2]))
+	s.key.dstPort = int(binary.BigEndian.Uint16(data[2:4]))
+
+	return s, len(s.payload) > 0
+}
@@ -0,0 +1,130 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// pcapWriter writes a capture with the Ethernet link type.
+type pcapWriter struct {
+	bytes.Buffer
+
+	time time.Time
+}
+
+func newPcapWriter() *pcapWriter {
+	w := &pcapWriter{time: time.Unix(1700000000, 0)}
+
+	binary.Write(w, binary.LittleEndian, []uint32{0xa1b2c3d4, 0x00040002, 0, 0, 65535, linkTypeEther})
+
+	return w
+}
+
+// frame writes an Ethernet frame containing an IPv4 packet with a TCP segment (protocol 6) or a UDP datagram (17).
+func (w *pcapWriter) frame(protocol uint8, srcPort, dstPort uint16, seq uint32, payload []byte) {
+	var transport bytes.Buffer
+
+	if protocol == 6 {
+		binary.Write(&transport, binary.BigEndian, []uint16{srcPort, dstPort})
+		binary.Write(&transport, binary.BigEndian, []uint32{seq, 0})
+		binary.Write(&transport, binary.BigEndian, []uint16{5 << 12, 0xFFFF, 0, 0})
+	} else {
+		binary.Write(&transport, binary.BigEndian, []uint16{srcPort, dstPort, uint16(8 + len(payload)), 0})
+	}
+
+	transport.Write(payload)
+
+	var frame bytes.Buffer
+
+	frame.Write(make([]byte, 12))
+	binary.Write(&frame, binary.BigEndian, uint16(0x0800))
+	frame.Write([]byte{0x45, 0})
+	binary.Write(&frame, binary.BigEndian, uint16(20+transport.Len()))
+	frame.Write([]byte{0, 0, 0, 0, 64, protocol, 0, 0, 127, 0, 0, 1, 127, 0, 0, 2})
+	frame.Write(transport.Bytes())
+
+	w.time = w.time.Add(time.Millisecond)
+
+	binary.Write(w, binary.LittleEndian, []uint32{
+		uint32(w.time.Unix()),
+		uint32(w.time.Nanosecond() / 1000),
+		uint32(frame.Len()),
+		uint32(frame.Len()),
+	})
+
+	w.Write(frame.Bytes())
+}
+
+func vector(t *testing.T, cookie uint32, values ...any) []byte {
+	vector, err := binrpc.VectorHex(cookie, values...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := hex.DecodeString(vector)
+
+	return data
+}
+
+func TestRead(t *testing.T) {
+	request := vector(t, 1, "core.uptime")
+	reply := vector(t, 1, 42)
+	udp := vector(t, 2, "core.version")
+
+	w := newPcapWriter()
+
+	// the request split in two segments, the second one first, then retransmitted
+	w.frame(6, 40000, 2049, 1000+5, request[5:])
+	w.frame(6, 40000, 2049, 1000, request[:5])
+	w.frame(6, 40000, 2049, 1000, request)
+	w.frame(6, 2049, 40000, 5000, reply)
+	w.frame(17, 40001, 2046, 0, udp)
+	w.frame(6, 40002, 80, 0, vector(t, 3, "not.ctl"))
+
+	packets, err := Read(bytes.NewReader(w.Bytes()), 2049)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(packets) != 2 {
+		t.Fatalf("expected 2 packets, got %d", len(packets))
+	}
+
+	if method, _ := packets[0].Records[0].String(); method != "core.uptime" {
+		t.Errorf(`expected "core.uptime", got "%s"`, method)
+	}
+
+	if packets[0].Src != "127.0.0.1:40000" || packets[0].Dst != "127.0.0.2:2049" || packets[0].Transport != "tcp" {
+		t.Errorf("unexpected endpoints %s %s -> %s", packets[0].Transport, packets[0].Src, packets[0].Dst)
+	}
+
+	if expected := time.Unix(1700000000, 0).Add(2 * time.Millisecond); !packets[0].Time.Equal(expected) {
+		t.Errorf("expected time %s, got %s", expected, packets[0].Time)
+	}
+
+	if uptime, _ := packets[1].Records[0].Int(); uptime != 42 || packets[1].Header.Cookie != 1 {
+		t.Errorf("expected the reply 42 with cookie 1, got %d with cookie %d", uptime, packets[1].Header.Cookie)
+	}
+
+	if packets, _ = Read(bytes.NewReader(w.Bytes()), 0); len(packets) != 4 {
+		t.Errorf("expected 4 packets on all ports, got %d", len(packets))
+	}
+}
+
+func TestReadNotPcap(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("not a capture at all, really")), 0); !errors.Is(err, ErrNotPcap) {
+		t.Errorf("expected ErrNotPcap, got %v", err)
+	}
+
+	if IsPcap([]byte{0xa1, 0x01}) {
+		t.Error("a BINRPC packet must not be detected as a capture")
+	}
+}
@@ -0,0 +1,872 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// drainQuietPeriod is how long Drain waits for a stray packet before considering the connection clean.
+	drainQuietPeriod = 100 * time.Millisecond
+
+	// maxAbandonedCookies is the number of timed out calls whose late replies are remembered and discarded.
+	maxAbandonedCookies = 16
+)
+
+// ErrCookieInUse is returned by calls whose cookie, set by WithCookie, is the one of a pending call.
+var ErrCookieInUse = errors.New("cookie in use by a pending call")
+
+// Client is a connection to the ctl module of a Kamailio instance.
+//
+// A Client is safe for concurrent use, but calls are serialized: a call writes its request and waits for the reply
+// before the next one starts.
+type Client struct {
+	mu sync.Mutex
+
+	conn   net.Conn
+	reader *bufio.Reader
+
+	// dial opens a new connection to replace a lost one, nil if the Client was created by NewClient
+	dial dialFunc
+
+	// network and address are the endpoint of the connection, which changes with a failover, see DialFailover
+	network  string
+	address  string
+	failover *failover
+
+	// connMu guards conn, connected and closed, which Close accesses without holding mu, and the endpoint
+	connMu    sync.Mutex
+	connected bool
+	closed    bool
+
+	// err is set when the stream is no longer aligned on packet boundaries, which makes the connection unusable
+	err error
+
+	// cookies of calls that timed out before their reply was read
+	abandoned []uint32
+
+	timeout         time.Duration
+	timeouts        Timeouts
+	retry           RetryPolicy
+	reconnectPolicy ReconnectPolicy
+	retransmit      RetransmitPolicy
+	logger          *slog.Logger
+	hooks           Hooks
+	connHooks       ConnHooks
+	readerOptions   ReaderOptions
+	cookies         CookieSource
+
+	// invoke is callContext wrapped by the interceptors, see WithInterceptors
+	interceptors []Interceptor
+	invoke       CallFunc
+
+	// stats are nil unless WithStats is set
+	stats *callStats
+
+	// flights are nil unless WithSingleflight is set
+	flights *flightGroup
+
+	// limiter and breaker are nil unless WithRateLimit and WithCircuitBreaker are set
+	limiter *rateLimiter
+	breaker *circuitBreaker
+
+	// keepAlive is the idle duration after which the connection is kept alive, see WithKeepAlive, and used the time
+	// of the end of the last call, guarded by mu
+	keepAlive     time.Duration
+	keepAliveStop chan struct{}
+	used          time.Time
+
+	// signatures caches the signatures fetched from Kamailio when validate is set, see WithValidation
+	validate     bool
+	signaturesMu sync.Mutex
+	signatures   map[string][]Signature
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithTimeout sets the maximum duration of a call, from writing the request to reading the reply.
+// A reply arriving after its call timed out is discarded automatically by the next call.
+//
+// See WithTimeouts to limit the steps of a call separately.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// SetTimeout changes the maximum duration of the calls, as set by WithTimeout, for instance when a configuration is
+// reloaded. A call in progress keeps its timeout, zero meaning no limit.
+//
+// To limit a single call, use CallContext with a context deadline, or WithCallTimeouts.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timeout = timeout
+}
+
+// WithLogger sets the logger used to report calls at debug level. Each entry has a "correlation_id" attribute, and a
+// "metadata" group if the context of the call carries metadata (see WithMetadata).
+//
+// The steps of the calls are logged at debug level too: the dials, the packets written and read, the decoding errors
+// and the retries. The hex dumps of the packets are logged at LevelTrace.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithReaderOptions sets the options used to decode replies.
+func WithReaderOptions(options ReaderOptions) Option {
+	return func(c *Client) {
+		c.readerOptions = options
+	}
+}
+
+// Dial connects to the ctl module listening on address and returns a Client. See net.Dial for network and address.
+//
+// When the connection is lost, the next call dials address again.
+//
+// On the unixgram network, the Client binds a temporary reply socket in os.TempDir, so that Kamailio can reply, and
+// removes it on Close. The socket is writable by all users, as Kamailio usually runs as another user.
+func Dial(network, address string, options ...Option) (*Client, error) {
+	return DialWith(&net.Dialer{}, network, address, options...)
+}
+
+// NewClient returns a Client using conn. The Client takes ownership of conn and closes it on Close.
+//
+// As the Client cannot open a new connection, it is unusable once conn is lost.
+func NewClient(conn net.Conn, options ...Option) *Client {
+	var network, address string
+
+	if addr := conn.RemoteAddr(); addr != nil {
+		network, address = addr.Network(), addr.String()
+	}
+
+	c := newClient(network, address, nil, options)
+	c.connect(conn)
+
+	return c
+}
+
+// newClient returns a Client configured with options, which is not connected yet.
+func newClient(network, address string, dial dialFunc, options []Option) *Client {
+	c := &Client{
+		dial:    dial,
+		network: network,
+		address: address,
+		cookies: RandomCookies,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	c.invoke = chainInterceptors(c.interceptors, c.callContext)
+
+	return c
+}
+
+// connect sets the first connection of the Client, and calls OnConnect.
+func (c *Client) connect(conn net.Conn) {
+	c.conn = conn
+	c.reader = newReader(c.network, conn)
+	c.connected = true
+	c.used = time.Now()
+
+	if c.connHooks.OnConnect != nil {
+		c.connHooks.OnConnect(c.connInfo(nil))
+	}
+
+	c.startKeepAlive()
+}
+
+// open opens a new connection with dial, within the dial timeout, and returns it with its endpoint.
+func (c *Client) open(ctx context.Context, timeout time.Duration) (net.Conn, endpoint, error) {
+	start := time.Now()
+	conn, e, err := c.dial(ctx, timeout)
+
+	if c.logEnabled(ctx, slog.LevelDebug) {
+		attrs := []slog.Attr{
+			slog.String("network", e.network),
+			slog.String("address", e.address),
+			slog.Duration("duration", time.Since(start)),
+		}
+
+		if err != nil {
+			attrs = append(attrs, errorAttr(err))
+		}
+
+		c.log(ctx, slog.LevelDebug, "binrpc dial", attrs...)
+	}
+
+	return conn, e, err
+}
+
+// Close closes the underlying connection. The Client does not reconnect after Close.
+func (c *Client) Close() error {
+	c.connMu.Lock()
+	closed := c.closed
+	c.closed = true
+	c.connMu.Unlock()
+
+	if !closed && c.keepAliveStop != nil {
+		close(c.keepAliveStop)
+	}
+
+	return c.disconnect(nil)
+}
+
+// Call invokes the RPC function method with args, and returns the records of the reply.
+// Valid args types are int, string, float64, []byte, Record, *Record, map[string]any or []StructItem for structs, and slices
+// of valid types (such as []any or []string) for arrays.
+// If Kamailio replies with a fault, the error is an *RPCError.
+func (c *Client) Call(method string, args ...any) ([]Record, error) {
+	return c.CallContext(context.Background(), method, args...)
+}
+
+// CallContext is like Call, but the call is aborted when ctx is done.
+// The correlation ID of the call is taken from ctx (see WithCorrelationID), or derived from the cookie.
+// The cookie is random, unless set by WithCookie or WithCookieSource.
+// The timeouts set by WithCallTimeouts override those of the Client.
+// The call goes through the interceptors set by WithInterceptors.
+func (c *Client) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	if c.flights != nil && IsIdempotent(method) {
+		if _, ok := cookieFromContext(ctx); !ok {
+			return c.flights.do(ctx, c.invoke, method, args)
+		}
+	}
+
+	return c.invoke(ctx, method, args...)
+}
+
+// callContext is the implementation of CallContext, once intercepted.
+func (c *Client) callContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	info := CallInfo{
+		CorrelationID: CorrelationID(ctx),
+		Method:        method,
+		Args:          args,
+		Cookie:        c.cookies.Cookie(),
+		Metadata:      Metadata(ctx),
+	}
+
+	cookie, hasCookie := cookieFromContext(ctx)
+
+	if hasCookie {
+		info.Cookie = cookie
+	}
+
+	if info.CorrelationID == "" {
+		info.CorrelationID = correlationIDFromCookie(info.Cookie)
+		ctx = WithCorrelationID(ctx, info.CorrelationID)
+	}
+
+	if c.hooks.OnCallStart != nil {
+		ctx = c.hooks.OnCallStart(ctx, info)
+	}
+
+	start := time.Now()
+
+	var records []Record
+	var attempts int
+
+	c.stats.start(method)
+
+	err := c.checkArgs(ctx, method, args)
+
+	var release func(err error)
+
+	if err == nil {
+		release, err = c.admit(ctx)
+	}
+
+	if err == nil {
+		records, attempts, err = c.callWithRetry(ctx, info, hasCookie)
+		release(err)
+	}
+
+	c.stats.done(method, time.Since(start), err)
+
+	if c.hooks.OnCallDone != nil {
+		c.hooks.OnCallDone(ctx, info, err)
+	}
+
+	if c.logger != nil {
+		attrs := []slog.Attr{
+			slog.String("correlation_id", info.CorrelationID),
+			slog.String("method", method),
+			slog.Any("cookie", info.Cookie),
+			slog.Duration("duration", time.Since(start)),
+		}
+
+		if attempts > 1 {
+			attrs = append(attrs, slog.Int("attempts", attempts))
+		}
+
+		if len(info.Metadata) > 0 {
+			attrs = append(attrs, metadataGroup(info.Metadata))
+		}
+
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "binrpc call", attrs...)
+	}
+
+	return records, err
+}
+
+// metadataGroup returns a "metadata" attribute holding metadata, sorted by key.
+func metadataGroup(metadata map[string]any) slog.Attr {
+	keys := make([]string, 0, len(metadata))
+
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	attrs := make([]any, 0, len(keys))
+
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, metadata[key]))
+	}
+
+	return slog.Group("metadata", attrs...)
+}
+
+// call writes the request described by info and reads its reply.
+func (c *Client) call(ctx context.Context, info CallInfo) ([]Record, error) {
+	request, err := newRequest(info.Cookie, info.Method, info.Args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.markUsed()
+
+	timeouts := c.timeouts.override(callTimeouts(ctx))
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, abandoned := range c.abandoned {
+		if abandoned == info.Cookie {
+			return nil, ErrCookieInUse
+		}
+	}
+
+	if err = c.ready(ctx, timeouts.Dial); err != nil {
+		return nil, err
+	}
+
+	deadline, hasDeadline := c.deadline(ctx)
+
+	c.conn.SetWriteDeadline(stepDeadline(deadline, hasDeadline, timeouts.Write))
+
+	defer c.conn.SetDeadline(time.Time{})
+
+	stop := c.watch(ctx)
+	defer stop()
+
+	if err = c.writeRequest(ctx, request); err != nil {
+		// a partial request may have been written
+		c.fail(err)
+		return nil, contextError(ctx, err)
+	}
+
+	readDeadline := stepDeadline(deadline, hasDeadline, timeouts.Read)
+
+	if c.retransmits(ctx, info.Method) {
+		records, err := c.readReplyRetransmit(ctx, request, readDeadline)
+
+		return records, contextError(ctx, err)
+	}
+
+	c.conn.SetReadDeadline(readDeadline)
+
+	// the watcher may have interrupted the call before the read deadline was set
+	if ctx.Err() != nil {
+		c.conn.SetReadDeadline(time.Unix(1, 0))
+	}
+
+	records, err := c.readReply(ctx, info.Cookie)
+
+	return records, contextError(ctx, err)
+}
+
+// ready makes the connection usable, dialing again within timeout if it was lost, or moves it to a preferred endpoint
+// of a failover. It must be called with c.mu held.
+func (c *Client) ready(ctx context.Context, timeout time.Duration) error {
+	if c.err == nil {
+		c.failback(ctx, timeout)
+		return nil
+	}
+
+	if c.dial == nil || c.isClosed() {
+		return fmt.Errorf("connection unusable: %w", c.err)
+	}
+
+	if err := c.redial(ctx, timeout); err != nil {
+		return fmt.Errorf("reconnect: %w", contextError(ctx, err))
+	}
+
+	return nil
+}
+
+// deadline returns the deadline of a call made now with ctx, the earliest of the one of ctx and the timeout of the
+// Client.
+func (c *Client) deadline(ctx context.Context) (time.Time, bool) {
+	deadline, hasDeadline := ctx.Deadline()
+
+	if c.timeout > 0 && (!hasDeadline || time.Now().Add(c.timeout).Before(deadline)) {
+		deadline, hasDeadline = time.Now().Add(c.timeout), true
+	}
+
+	return deadline, hasDeadline
+}
+
+// writeRequest writes request to the connection.
+func (c *Client) writeRequest(ctx context.Context, request *Request) error {
+	_, err := request.WriteTo(c.conn)
+
+	c.logWrite(ctx, request, err)
+
+	return err
+}
+
+// logWrite logs request, written to the connection with err.
+func (c *Client) logWrite(ctx context.Context, request *Request, err error) {
+	if c.logEnabled(ctx, slog.LevelDebug) {
+		attrs := []slog.Attr{
+			slog.String("method", request.Method),
+			slog.Any("cookie", request.Cookie),
+			slog.Int("size", len(request.packet)),
+		}
+
+		if err != nil {
+			attrs = append(attrs, errorAttr(err))
+		}
+
+		c.log(ctx, slog.LevelDebug, "binrpc write", attrs...)
+		c.logPacket(ctx, "binrpc write packet", request.Cookie, request.packet)
+	}
+}
+
+// watch interrupts the pending I/O of the connection when ctx is done, until stop is called.
+func (c *Client) watch(ctx context.Context) (stop func()) {
+	return watchConn(ctx, c.conn)
+}
+
+// watchConn interrupts the pending I/O of conn when ctx is done, until stop is called.
+func watchConn(ctx context.Context, conn net.Conn) (stop func()) {
+	return watchDeadline(ctx, conn.SetDeadline)
+}
+
+// watchDeadline calls setDeadline with a past deadline when ctx is done, until stop is called.
+func watchDeadline(ctx context.Context, setDeadline func(t time.Time) error) (stop func()) {
+	if ctx.Done() == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		select {
+		case <-ctx.Done():
+			setDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+// Drain reads and discards the packets pending on the connection, such as late replies of calls that timed out.
+// It returns once no packet arrived for a short period, or when ctx is done.
+func (c *Client) Drain(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return fmt.Errorf("connection unusable: %w", c.err)
+	}
+
+	defer c.conn.SetReadDeadline(time.Time{})
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		deadline := time.Now().Add(drainQuietPeriod)
+
+		if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+
+		c.conn.SetReadDeadline(deadline)
+
+		if _, err := c.reader.Peek(1); err != nil {
+			if !isTimeout(err) {
+				c.fail(err)
+				return err
+			}
+
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			// nothing else is coming, the replies we are still waiting for are considered lost
+			c.abandoned = nil
+			return nil
+		}
+
+		if ctxDeadline, ok := ctx.Deadline(); ok {
+			c.conn.SetReadDeadline(ctxDeadline)
+		} else {
+			c.conn.SetReadDeadline(time.Time{})
+		}
+
+		buffer := buffers.get()
+		header, err := readPayloadTo(c.reader, buffer, c.readerOptions.MaxPayload)
+		buffers.put(buffer)
+
+		if err != nil {
+			c.fail(err)
+			return err
+		}
+
+		c.forget(header.Cookie)
+	}
+}
+
+// readReply reads packets until the reply matching cookie is found, skipping late replies of abandoned calls. On
+// datagram networks, where each packet is a datagram, replies with an unknown cookie, such as the duplicate replies of
+// a retransmitted request, are skipped too.
+func (c *Client) readReply(ctx context.Context, cookie uint32) ([]Record, error) {
+	for {
+		header, buffer, err := c.readPacket()
+
+		if err != nil {
+			if c.err == nil {
+				c.abandon(cookie)
+			}
+
+			c.log(ctx, slog.LevelDebug, "binrpc read", slog.Any("cookie", cookie), errorAttr(err))
+
+			return nil, err
+		}
+
+		c.logRead(ctx, header, buffer.Bytes(), header.Cookie == cookie)
+
+		if header.Cookie == cookie {
+			records, err := decodePayload(header, buffer.Bytes(), c.readerOptions)
+			buffers.put(buffer)
+
+			if err != nil {
+				c.log(ctx, slog.LevelDebug, "binrpc decode", slog.Any("cookie", cookie), errorAttr(err))
+			} else if header.Fault() {
+				return nil, faultError(records)
+			}
+
+			return records, err
+		}
+
+		buffers.put(buffer)
+
+		if !c.forget(header.Cookie) && !isDatagram(c.network) {
+			return nil, errors.New("expected cookie did not match")
+		}
+	}
+}
+
+// readPacket reads the next packet, and returns its header and its payload, whose buffer must be released with
+// buffers.put. A timeout while waiting for the packet leaves the stream aligned: other errors make the connection
+// unusable. It must be called with c.mu held.
+func (c *Client) readPacket() (*Header, *bytes.Buffer, error) {
+	// wait for the first byte, so that a timeout here leaves the stream aligned
+	if _, err := c.reader.Peek(1); err != nil {
+		if !isTimeout(err) {
+			c.fail(err)
+		}
+
+		return nil, nil, err
+	}
+
+	// records do not reference the payload, so its buffer is released once decoded
+	buffer := buffers.get()
+	header, err := readPayloadTo(c.reader, buffer, c.readerOptions.MaxPayload)
+
+	if err != nil {
+		buffers.put(buffer)
+		c.fail(err)
+
+		return nil, nil, err
+	}
+
+	return header, buffer, nil
+}
+
+// logRead logs a packet read, with header and payload. expected is false if the packet is skipped.
+func (c *Client) logRead(ctx context.Context, header *Header, payload []byte, expected bool) {
+	if !c.logEnabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	c.log(ctx, slog.LevelDebug, "binrpc read",
+		slog.Any("cookie", header.Cookie),
+		slog.Int("size", header.Size()+len(payload)),
+		slog.Bool("fault", header.Fault()),
+		slog.Bool("skipped", !expected),
+	)
+
+	if c.logEnabled(ctx, LevelTrace) {
+		packet, _ := appendHeader(nil, header.flags, header.Cookie, len(payload))
+		c.logPacket(ctx, "binrpc read packet", header.Cookie, append(packet, payload...))
+	}
+}
+
+// fail makes the connection unusable because of err, and closes it. It must be called with c.mu held.
+func (c *Client) fail(err error) {
+	c.err = err
+	c.disconnect(err)
+}
+
+// disconnect closes the connection, and calls OnDisconnect with err if it was connected.
+func (c *Client) disconnect(err error) error {
+	c.connMu.Lock()
+	conn, connected := c.conn, c.connected
+	c.connected = false
+	c.connMu.Unlock()
+
+	if !connected {
+		return nil
+	}
+
+	closeErr := conn.Close()
+
+	if c.connHooks.OnDisconnect != nil {
+		c.connHooks.OnDisconnect(c.connInfo(err))
+	}
+
+	return closeErr
+}
+
+// reconnect replaces the lost connection with a new one, opened within timeout. It must be called with c.mu held.
+func (c *Client) reconnect(ctx context.Context, timeout time.Duration) error {
+	conn, e, err := c.open(ctx, timeout)
+
+	return c.replace(conn, e, err)
+}
+
+// replace replaces the lost connection with conn, opened to e, unless the dial failed with err, and calls
+// OnReconnect. It must be called with c.mu held.
+func (c *Client) replace(conn net.Conn, e endpoint, err error) error {
+	c.connMu.Lock()
+
+	if err == nil && c.closed {
+		conn.Close()
+		err = net.ErrClosed
+	}
+
+	if err == nil {
+		c.conn = conn
+		c.connected = true
+		c.network, c.address = e.network, e.address
+	}
+
+	c.connMu.Unlock()
+
+	if err == nil {
+		c.reader = newReader(c.network, conn)
+		c.err = nil
+		c.abandoned = nil
+	}
+
+	if c.connHooks.OnReconnect != nil {
+		c.connHooks.OnReconnect(c.connInfo(err))
+	}
+
+	return err
+}
+
+func (c *Client) isClosed() bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	return c.closed
+}
+
+func (c *Client) connInfo(err error) ConnInfo {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+
+	return ConnInfo{
+		Network: c.network,
+		Address: c.address,
+		Err:     err,
+	}
+}
+
+// abandon remembers cookie so that its reply is discarded when it arrives.
+func (c *Client) abandon(cookie uint32) {
+	if len(c.abandoned) == maxAbandonedCookies {
+		c.abandoned = c.abandoned[1:]
+	}
+
+	c.abandoned = append(c.abandoned, cookie)
+}
+
+// forget removes cookie from the abandoned calls, and reports whether it was found.
+func (c *Client) forget(cookie uint32) bool {
+	for i, abandoned := range c.abandoned {
+		if abandoned == cookie {
+			c.abandoned = append(c.abandoned[:i], c.abandoned[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// createRecords creates a Record for each value. Values can be of a type in ValidTypes, a Record, a *Record, a
+// map[string]any or []StructItem for a struct, or a slice of such values for an array.
+func createRecords(values []any) ([]*Record, error) {
+	records := make([]*Record, 0, len(values))
+
+	for _, value := range values {
+		var record *Record
+		var err error
+
+		switch v := value.(type) {
+		case int:
+			record, err = CreateRecord(v)
+		case string:
+			record, err = CreateRecord(v)
+		case float64:
+			record, err = CreateRecord(v)
+		case []byte:
+			record, err = CreateRecord(v)
+		case Record:
+			record = &v
+		case *Record:
+			record = v
+		case map[string]any:
+			record, err = createStruct(v)
+		case []StructItem:
+			record = &Record{Type: TypeStruct, Value: v}
+		default:
+			if slice := reflect.ValueOf(value); slice.Kind() == reflect.Slice {
+				record, err = createArray(slice)
+			} else {
+				err = fmt.Errorf("type error: type %T not implemented", value)
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// createStruct creates a struct Record from m. Items are sorted by key, and their types are inferred like those of
+// the args of a call.
+func createStruct(m map[string]any) (*Record, error) {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	items := make([]StructItem, 0, len(keys))
+
+	for _, key := range keys {
+		records, err := createRecords([]any{m[key]})
+
+		if err != nil {
+			return nil, fmt.Errorf("struct item %s: %w", key, err)
+		}
+
+		items = append(items, StructItem{
+			Key:   key,
+			Value: *records[0],
+		})
+	}
+
+	return &Record{Type: TypeStruct, Value: items}, nil
+}
+
+// createArray creates an array Record from the elements of slice, whose types are inferred like those of the args
+// of a call.
+func createArray(slice reflect.Value) (*Record, error) {
+	elements := make([]Record, 0, slice.Len())
+
+	for i := 0; i < slice.Len(); i++ {
+		records, err := createRecords([]any{slice.Index(i).Interface()})
+
+		if err != nil {
+			return nil, fmt.Errorf("array element %d: %w", i, err)
+		}
+
+		elements = append(elements, *records[0])
+	}
+
+	return &Record{Type: TypeArray, Value: elements}, nil
+}
+
+// contextError returns the error of ctx if err was caused by ctx being done, and err otherwise.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || !isTimeout(err) {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// the deadline of the connection may expire slightly before the one of ctx
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+
+	return err
+}
+
+// isTimeout reports whether err is caused by a deadline.
+func isTimeout(err error) bool {
+	var netErr net.Error
+
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
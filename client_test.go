@@ -0,0 +1,334 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve starts a server with listen, and returns a Client connected to it.
+func serve(t *testing.T, handler func(records []Record) []any, options ...Option) *Client {
+	client, err := Dial("tcp", listen(t, handler), options...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+// listen starts a TCP server that calls handler for every request received on any connection, and returns its address.
+// The handler returns the values of the reply, a single *RPCError to reply with a fault, or nil to send no reply.
+func listen(t *testing.T, handler func(records []Record) []any) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go handle(conn, handler)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// handle replies to the requests received on conn with handler, see listen.
+func handle(conn net.Conn, handler func(records []Record) []any) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		header, payload, err := readPayload(reader, 0)
+
+		if err != nil {
+			return
+		}
+
+		request, err := decodePayload(header, payload, ReaderOptions{})
+
+		if err != nil {
+			return
+		}
+
+		values := handler(request)
+		flags := uint8(0)
+
+		if values == nil {
+			continue
+		}
+
+		if fault, ok := values[0].(*RPCError); ok {
+			values = []any{fault.Code, fault.Message}
+			flags = flagFault
+		}
+
+		records, err := createRecords(values)
+
+		if err != nil {
+			return
+		}
+
+		if err = writePacket(conn, flags, header.Cookie, records); err != nil {
+			return
+		}
+	}
+}
+
+// echo replies with the method name, after sleeping for the duration given as first arg (in milliseconds) if any.
+func echo(records []Record) []any {
+	if len(records) > 1 {
+		delay, _ := records[1].Int()
+		time.Sleep(time.Duration(delay) * time.Millisecond)
+	}
+
+	method, _ := records[0].String()
+
+	return []any{method}
+}
+
+func TestClientCall(t *testing.T) {
+	client := serve(t, echo)
+
+	records, err := client.Call("core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.echo" {
+		t.Errorf(`expected "core.echo", got "%s"`, value)
+	}
+}
+
+func TestClientCallBytes(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		value, _ := records[1].Bytes()
+		return []any{value}
+	})
+
+	value := []byte{0x00, 0x01, 0xFF, 0x00}
+	records, err := client.Call("bytes.echo", value)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := records[0].Bytes(); !bytes.Equal(b, value) {
+		t.Errorf("expected %x, got %x", value, b)
+	}
+}
+
+func TestClientCallInvalidArg(t *testing.T) {
+	client := serve(t, echo)
+
+	if _, err := client.Call("core.echo", true); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestClientTimeoutSkipsLateReply(t *testing.T) {
+	client := serve(t, echo, WithTimeout(100*time.Millisecond))
+
+	if _, err := client.Call("slow", 150); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	records, err := client.Call("fast")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "fast" {
+		t.Errorf(`expected "fast", got "%s"`, value)
+	}
+}
+
+func TestClientSetTimeout(t *testing.T) {
+	client := serve(t, echo)
+
+	client.SetTimeout(20 * time.Millisecond)
+
+	if _, err := client.Call("slow", 50); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	client.SetTimeout(0)
+
+	if _, err := client.Call("slow", 50); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClientDrain(t *testing.T) {
+	client := serve(t, echo, WithTimeout(20*time.Millisecond))
+
+	if _, err := client.Call("slow", 50); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	if err := client.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(client.abandoned) != 0 {
+		t.Errorf("expected no abandoned call, got %d", len(client.abandoned))
+	}
+
+	records, err := client.Call("fast")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "fast" {
+		t.Errorf(`expected "fast", got "%s"`, value)
+	}
+}
+
+func TestClientDrainContext(t *testing.T) {
+	client := serve(t, echo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := client.Drain(ctx); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestClientCallContextCanceled(t *testing.T) {
+	client := serve(t, echo)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.CallContext(ctx, "slow", 100); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestClientCallStructArg(t *testing.T) {
+	// reply with the first arg
+	client := serve(t, func(records []Record) []any {
+		return []any{records[1]}
+	})
+
+	records, err := client.Call("core.echo", map[string]any{
+		"name":  "gw1",
+		"flags": 2,
+		"attrs": map[string]any{"weight": 0.5},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := records[0].StructItems()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+
+	if expected := "attrs,flags,name"; strings.Join(keys, ",") != expected {
+		t.Errorf("expected keys %s, got %s", expected, strings.Join(keys, ","))
+	}
+
+	var attrs struct {
+		Weight float64
+	}
+
+	if err = items[0].Value.Scan(&attrs); err != nil || attrs.Weight != 0.5 {
+		t.Errorf("expected weight 0.5, got %v (%v)", attrs.Weight, err)
+	}
+
+	if _, err = client.Call("core.echo", map[string]any{"invalid": []int64{1}}); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestClientCallArrayArg(t *testing.T) {
+	// reply with the first arg
+	client := serve(t, func(records []Record) []any {
+		return []any{records[1]}
+	})
+
+	records, err := client.Call("core.echo", []any{1, "two", []string{"three"}, map[string]any{"four": 4}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, err := records[0].Array()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []uint8{TypeInt, TypeString, TypeArray, TypeStruct}
+
+	if len(elements) != len(expected) {
+		t.Fatalf("expected %d elements, got %d", len(expected), len(elements))
+	}
+
+	for i, element := range elements {
+		if element.Type != expected[i] {
+			t.Errorf("element %d: expected type %d, got %d", i, expected[i], element.Type)
+		}
+	}
+
+	if _, err = client.Call("core.echo", []any{1, true}); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestReadPacketContext(t *testing.T) {
+	address := listen(t, func(records []Record) []any {
+		return nil
+	})
+
+	conn, err := net.Dial("tcp", address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	cookie, err := WritePacket(conn, "core.uptime")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err = ReadPacketContext(ctx, conn, cookie); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
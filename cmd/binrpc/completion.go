@@ -0,0 +1,258 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// completionScripts are the scripts printed by the completion subcommand, keyed by shell.
+// The scripts call "binrpc completion methods" and "binrpc completion instances" to complete method and instance
+// names, passing the -s, -config, -instance and -timeout flags found on the command line. The subcommands completion
+// and inspect are completed with the methods.
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+// runCompletion executes the completion subcommand with args, and returns the exit code.
+func runCompletion(args []string, selection targetFlags, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		fmt.Fprintln(stderr, "usage: binrpc completion bash|zsh|fish")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "methods":
+		return completeMethods(selection, stdout, stderr)
+	case "instances":
+		return completeInstances(selection, stdout, stderr)
+	}
+
+	script, ok := completionScripts[args[0]]
+
+	if !ok {
+		fmt.Fprintf(stderr, "binrpc: unknown shell %s\n", args[0])
+		return exitUsage
+	}
+
+	io.WriteString(stdout, script)
+
+	return exitOK
+}
+
+// completeMethods prints the methods returned by system.listMethods on the first selected instance, one per line.
+func completeMethods(selection targetFlags, stdout, stderr io.Writer) int {
+	targets, err := selection.targets()
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpc: %v\n", err)
+		return exitUsage
+	}
+
+	records, err := targets[0].call("system.listMethods", nil)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpc: %v\n", err)
+		return exitCode(err)
+	}
+
+	var methods strings.Builder
+
+	for _, record := range records {
+		var method string
+
+		if record.Scan(&method) == nil {
+			methods.WriteString(method + "\n")
+		}
+	}
+
+	io.WriteString(stdout, methods.String())
+
+	return exitOK
+}
+
+// completeInstances prints the names of the instances of the config file, one per line.
+func completeInstances(selection targetFlags, stdout, stderr io.Writer) int {
+	config, err := loadConfig(selection.configPath)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpc: %v\n", err)
+		return exitUsage
+	}
+
+	for _, name := range config.names() {
+		fmt.Fprintln(stdout, name)
+	}
+
+	return exitOK
+}
+
+const bashCompletion = `# bash completion for binrpc
+
+_binrpc() {
+	local words=() args=() config=() cur="" i
+
+	# COMP_WORDS splits addresses like tcp:host:port, split the line on blanks instead
+	read -ra words <<< "${COMP_LINE:0:COMP_POINT}"
+
+	if [[ "${COMP_LINE:COMP_POINT-1:1}" != [[:blank:]] ]]; then
+		cur="${words[-1]}"
+		unset 'words[-1]'
+	fi
+
+	case "${words[-1]}" in
+	-output | --output)
+		COMPREPLY=($(compgen -W "text json yaml table flat" -- "$cur"))
+		return
+		;;
+	-config | --config)
+		COMPREPLY=($(compgen -f -- "$cur"))
+		return
+		;;
+	-s | --s | -timeout | --timeout)
+		return
+		;;
+	esac
+
+	for ((i = 1; i < ${#words[@]}; i++)); do
+		case "${words[i]}" in
+		-config | --config)
+			config=("${words[i]}" "${words[i+1]}")
+			args+=("${words[i]}" "${words[i+1]}")
+			((i++))
+			;;
+		-s | --s | -instance | --instance | -timeout | --timeout)
+			args+=("${words[i]}" "${words[i+1]}")
+			((i++))
+			;;
+		-output | --output)
+			((i++))
+			;;
+		-*)
+			;;
+		*)
+			# the method is already typed
+			return
+			;;
+		esac
+	done
+
+	case "${words[-1]}" in
+	-instance | --instance)
+		COMPREPLY=($(compgen -W "$(binrpc "${config[@]}" completion instances 2>/dev/null)" -- "$cur"))
+		return
+		;;
+	esac
+
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -W "-s -output -timeout -config -instance -all" -- "$cur"))
+		return
+	fi
+
+	COMPREPLY=($(compgen -W "completion inspect $(binrpc "${args[@]}" completion methods 2>/dev/null)" -- "$cur"))
+}
+
+complete -F _binrpc binrpc
+`
+
+const zshCompletion = `#compdef binrpc
+
+_binrpc() {
+	local -a args config
+	local i state
+
+	for ((i = 2; i + 1 < CURRENT; i++)); do
+		case "${words[i]}" in
+		-config)
+			config=("${words[i]}" "${words[i+1]}")
+			args+=("${words[i]}" "${words[i+1]}")
+			((i++))
+			;;
+		-s|-instance|-timeout)
+			args+=("${words[i]}" "${words[i+1]}")
+			((i++))
+			;;
+		esac
+	done
+
+	_arguments \
+		'-s[address of the ctl module]:address:' \
+		'-output[output format]:format:(text json yaml table flat)' \
+		'-timeout[timeout of the call]:duration:' \
+		'-config[config file defining named instances]:file:_files' \
+		'-instance[name of the instance to call]:instance:->instances' \
+		'-all[call all the instances defined in the config file]' \
+		'1:method:->methods' \
+		'*::arg:'
+
+	case $state in
+	instances)
+		compadd -- ${(f)"$(binrpc "${config[@]}" completion instances 2>/dev/null)"}
+		;;
+	methods)
+		compadd -- completion inspect ${(f)"$(binrpc "${args[@]}" completion methods 2>/dev/null)"}
+		;;
+	esac
+}
+
+compdef _binrpc binrpc
+`
+
+const fishCompletion = `# fish completion for binrpc
+
+# __binrpc_args prints the flags selecting the instance, found on the command line
+function __binrpc_args
+	set -l tokens (commandline -opc)
+	set -l i 2
+
+	while test $i -lt (count $tokens)
+		switch $tokens[$i]
+			case -s -config -instance -timeout
+				printf '%s\n' $tokens[$i] $tokens[(math $i + 1)]
+				set i (math $i + 1)
+		end
+
+		set i (math $i + 1)
+	end
+end
+
+# __binrpc_config prints the -config flag found on the command line
+function __binrpc_config
+	set -l tokens (commandline -opc)
+	set -l i (contains -i -- -config $tokens)
+
+	and test $i -lt (count $tokens)
+	and printf '%s\n' -config $tokens[(math $i + 1)]
+end
+
+# __binrpc_needs_method succeeds if the method is not typed yet
+function __binrpc_needs_method
+	set -l tokens (commandline -opc)
+	set -l i 2
+
+	while test $i -le (count $tokens)
+		switch $tokens[$i]
+			case -s -config -instance -timeout -output
+				set i (math $i + 1)
+			case '-*'
+			case '*'
+				return 1
+		end
+
+		set i (math $i + 1)
+	end
+end
+
+complete -c binrpc -f
+complete -c binrpc -o s -x -d 'address of the ctl module'
+complete -c binrpc -o output -x -a 'text json yaml table flat' -d 'output format'
+complete -c binrpc -o timeout -x -d 'timeout of the call'
+complete -c binrpc -o config -r -F -d 'config file defining named instances'
+complete -c binrpc -o instance -x -a '(binrpc (__binrpc_config) completion instances 2>/dev/null)' -d 'name of the instance to call'
+complete -c binrpc -o all -d 'call all the instances defined in the config file'
+complete -c binrpc -n __binrpc_needs_method -a completion -d 'print a completion script'
+complete -c binrpc -n __binrpc_needs_method -a inspect -d 'decode captured packets'
+complete -c binrpc -n __binrpc_needs_method -a '(binrpc (__binrpc_args) completion methods 2>/dev/null)'
+`
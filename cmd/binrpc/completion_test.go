@@ -0,0 +1,70 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunCompletion(t *testing.T) {
+	for shell := range completionScripts {
+		var stdout, stderr strings.Builder
+
+		if code := run([]string{"completion", shell}, &stdout, &stderr); code != exitOK {
+			t.Errorf("%s: expected %d, got %d", shell, exitOK, code)
+		}
+
+		if !strings.Contains(stdout.String(), "completion methods") {
+			t.Errorf("%s: expected a script completing methods, got %q", shell, stdout.String())
+		}
+
+		if !strings.Contains(stdout.String(), "inspect") {
+			t.Errorf("%s: expected a script completing the inspect subcommand, got %q", shell, stdout.String())
+		}
+	}
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"completion", "csh"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+}
+
+func TestRunCompletionInstances(t *testing.T) {
+	path := writeConfig(t, `{"instances": {"edge2": {"address": "b"}, "edge1": {"address": "a"}}}`)
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"-config", path, "completion", "instances"}, &stdout, &stderr); code != exitOK {
+		t.Errorf("expected %d, got %d", exitOK, code)
+	}
+
+	if expected := "edge1\nedge2\n"; stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestRunCompletionMethodsUnreachable(t *testing.T) {
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"-s", "tcp:" + closedAddress(t), "completion", "methods"}, &stdout, &stderr); code != exitTransport {
+		t.Errorf("expected %d, got %d", exitTransport, code)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("expected no output, got %q", stdout.String())
+	}
+}
+
+func TestRunCompletionMethodsNoInstance(t *testing.T) {
+	path := writeConfig(t, `{"instances": {}}`)
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"-config", path, "-all", "completion", "methods"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+
+	if !strings.Contains(stderr.String(), "no instance") {
+		t.Errorf("expected an error about the missing instances, got %q", stderr.String())
+	}
+}
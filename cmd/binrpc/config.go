@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// config is the content of the config file, defining named Kamailio instances:
+//
+//	{
+//		"instances": {
+//			"edge1": {"address": "tcp:10.0.0.1:2049", "timeout": "10s"},
+//			"edge2": {"address": "10.0.0.2:2046", "transport": "udp", "dial_timeout": "1s"}
+//		}
+//	}
+type config struct {
+	Instances map[string]instance `json:"instances"`
+}
+
+// instance is a Kamailio instance defined in the config file.
+type instance struct {
+	// Address of the ctl module, in the same format as the -s flag.
+	Address string `json:"address"`
+
+	// Transport is "tcp", "udp", "unix" or "unixgram". If set, it takes precedence over the network in Address.
+	Transport string `json:"transport"`
+
+	// Timeout of the call, and timeout of the connection. Zero means the default.
+	Timeout     duration `json:"timeout"`
+	DialTimeout duration `json:"dial_timeout"`
+}
+
+// duration is a time.Duration written as a string like "5s" in JSON.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	value, err := time.ParseDuration(s)
+
+	if err != nil {
+		return err
+	}
+
+	*d = duration(value)
+
+	return nil
+}
+
+// defaultConfigPath returns the path of the config file used when the -config flag is not set.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "binrpc", "config.json")
+}
+
+// loadConfig reads the config file at path.
+func loadConfig(path string) (*config, error) {
+	if path == "" {
+		return nil, errors.New("no config file")
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c config
+
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	for name, instance := range c.Instances {
+		if instance.Address == "" {
+			return nil, fmt.Errorf("invalid config file %s: instance %s has no address", path, name)
+		}
+	}
+
+	return &c, nil
+}
+
+// names returns the names of the instances, sorted.
+func (c *config) names() []string {
+	names := make([]string, 0, len(c.Instances))
+
+	for name := range c.Instances {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `{
+		"instances": {
+			"edge2": {"address": "10.0.0.2:2046", "transport": "udp", "dial_timeout": "1s"},
+			"edge1": {"address": "tcp:10.0.0.1:2049", "timeout": "10s"}
+		}
+	}`)
+
+	c, err := loadConfig(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := c.names(); strings.Join(names, ",") != "edge1,edge2" {
+		t.Errorf("expected edge1,edge2, got %v", names)
+	}
+
+	if timeout := time.Duration(c.Instances["edge1"].Timeout); timeout != 10*time.Second {
+		t.Errorf("expected 10s, got %s", timeout)
+	}
+
+	if transport := c.Instances["edge2"].Transport; transport != "udp" {
+		t.Errorf("expected udp, got %s", transport)
+	}
+
+	if _, err = loadConfig(writeConfig(t, `{"instances": {"edge1": {"timeout": "1s"}}}`)); err == nil {
+		t.Error("error must be returned for an instance without address")
+	}
+
+	if _, err = loadConfig(writeConfig(t, `{"instances": {"edge1": {"address": "a", "timeout": "soon"}}}`)); err == nil {
+		t.Error("error must be returned for an invalid duration")
+	}
+}
+
+// closedAddress returns a TCP address nothing listens on.
+func closedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+	listener.Close()
+
+	return address
+}
+
+func TestRunAll(t *testing.T) {
+	path := writeConfig(t, `{
+		"instances": {
+			"edge1": {"address": "tcp:`+closedAddress(t)+`"},
+			"edge2": {"address": "`+closedAddress(t)+`", "dial_timeout": "1s"}
+		}
+	}`)
+
+	var stdout, stderr strings.Builder
+
+	code := run([]string{"-config", path, "-all", "-output", "json", "core.version"}, &stdout, &stderr)
+
+	if code != exitTransport {
+		t.Errorf("expected %d, got %d", exitTransport, code)
+	}
+
+	var combined map[string]map[string]string
+
+	if err := json.Unmarshal([]byte(stdout.String()), &combined); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"edge1", "edge2"} {
+		if combined[name]["error"] == "" {
+			t.Errorf("%s: expected an error, got %v", name, combined[name])
+		}
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+
+	run([]string{"-config", path, "-all", "core.version"}, &stdout, &stderr)
+
+	if expected := "==> edge1 <==\n==> edge2 <==\n"; stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+
+	if code := run([]string{"-config", path, "-instance", "edge3", "core.version"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+}
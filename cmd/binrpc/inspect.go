@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/florentchauveau/go-kamailio-binrpc/v3/capture"
+	"github.com/florentchauveau/go-kamailio-binrpc/v3/format"
+)
+
+// packet is a packet decoded by the inspect subcommand.
+type packet struct {
+	header  *binrpc.Header
+	records []binrpc.Record
+
+	// capture is set for the packets found in a pcap capture
+	capture *capture.Packet
+}
+
+// runInspect executes the inspect subcommand with args, and returns the exit code.
+// Each arg is a file, containing packets in binary or in hex or a pcap capture, or packets written in hex. Without
+// args, packets are read from stdin.
+func runInspect(args []string, output string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: binrpc [-output format] inspect [-port port] [file | hex...]")
+		flags.PrintDefaults()
+	}
+
+	port := flags.Int("port", 0, "port of the ctl module in pcap captures, 0 to decode all the TCP and UDP traffic")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	args = flags.Args()
+
+	var inputs [][]byte
+
+	if len(args) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			fmt.Fprintf(stderr, "binrpc: %v\n", err)
+			return exitUsage
+		}
+
+		args = []string{"-"}
+		inputs = append(inputs, data)
+	}
+
+	for _, arg := range args[len(inputs):] {
+		data, err := os.ReadFile(arg)
+
+		if err != nil && isHexText([]byte(arg)) {
+			// not a file, but packets written in hex
+			data, err = []byte(arg), nil
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "binrpc: %v\n", err)
+			return exitUsage
+		}
+
+		inputs = append(inputs, data)
+	}
+
+	var packets []packet
+
+	code := exitOK
+
+	for i, data := range inputs {
+		if capture.IsPcap(data) {
+			captured, err := capture.Read(bytes.NewReader(data), *port)
+
+			if err != nil {
+				fmt.Fprintf(stderr, "binrpc: %s: %v\n", args[i], err)
+				code = exitTransport
+			}
+
+			for j := range captured {
+				packets = append(packets, packet{captured[j].Header, captured[j].Records, &captured[j]})
+			}
+
+			continue
+		}
+
+		if isHexText(data) {
+			decoded, err := hex.DecodeString(stripSpaces(string(data)))
+
+			if err != nil {
+				fmt.Fprintf(stderr, "binrpc: %s: %v\n", args[i], err)
+				code = exitUsage
+				continue
+			}
+
+			data = decoded
+		}
+
+		reader := bufio.NewReader(bytes.NewReader(data))
+
+		for {
+			if _, err := reader.Peek(1); err != nil {
+				break
+			}
+
+			header, records, err := binrpc.DecodePacket(reader, binrpc.ReaderOptions{ErrorSnippets: true})
+
+			if err != nil {
+				fmt.Fprintf(stderr, "binrpc: %s: packet %d: %v\n", args[i], len(packets)+1, err)
+				code = exitTransport
+				break
+			}
+
+			packets = append(packets, packet{header, records, nil})
+		}
+	}
+
+	if encoder, ok := format.LookupEncoder(output); ok {
+		if err := encodePackets(stdout, encoder, packets); err != nil {
+			fmt.Fprintf(stderr, "binrpc: %v\n", err)
+			return exitTransport
+		}
+
+		return code
+	}
+
+	formatter, _ := format.Lookup(output)
+
+	for i, p := range packets {
+		fmt.Fprintf(stdout, "==> packet %d: %s <==\n", i+1, describePacket(p))
+
+		if err := formatter(stdout, p.records); err != nil {
+			fmt.Fprintf(stderr, "binrpc: packet %d: %v\n", i+1, err)
+			code = exitTransport
+		}
+	}
+
+	return code
+}
+
+// encodePackets writes packets as a list of objects with their header fields and records.
+func encodePackets(w io.Writer, encoder format.Encoder, packets []packet) error {
+	list := []any{}
+
+	for _, p := range packets {
+		reply, err := format.Reply(p.records)
+
+		if err != nil {
+			return err
+		}
+
+		o := format.NewObject()
+
+		if p.capture != nil {
+			o.Add("time", p.capture.Time.UTC().Format(time.RFC3339Nano))
+			o.Add("transport", p.capture.Transport)
+			o.Add("src", p.capture.Src)
+			o.Add("dst", p.capture.Dst)
+		}
+
+		o.Add("cookie", fmt.Sprintf("%08x", p.header.Cookie))
+		o.Add("size", p.header.Size()+p.header.PayloadLength)
+		o.Add("fault", p.header.Fault())
+		o.Add("records", reply)
+
+		list = append(list, o)
+	}
+
+	return encoder(w, list)
+}
+
+// describePacket returns the cookie and the size of a packet, whether it is a fault, and where it was captured.
+func describePacket(p packet) string {
+	description := fmt.Sprintf("cookie %08x, %d bytes", p.header.Cookie, p.header.Size()+p.header.PayloadLength)
+
+	if p.header.Fault() {
+		description += ", fault"
+	}
+
+	if p.capture != nil {
+		description = fmt.Sprintf("%s %s %s > %s, %s", p.capture.Time.UTC().Format("15:04:05.000000"),
+			p.capture.Transport, p.capture.Src, p.capture.Dst, description)
+	}
+
+	return description
+}
+
+// isHexText reports whether data contains only hex digits and whitespace.
+func isHexText(data []byte) bool {
+	text := strings.TrimSpace(string(data))
+
+	if text == "" {
+		return false
+	}
+
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stripSpaces(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, s)
+}
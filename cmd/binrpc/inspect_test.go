@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+func TestRunInspect(t *testing.T) {
+	vector, err := binrpc.VectorHex(0x1234, "core.echo", 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"inspect", vector}, &stdout, &stderr); code != exitOK {
+		t.Errorf("expected %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	if expected := "==> packet 1: cookie 00001234, 19 bytes <==\ncore.echo\n42\n"; stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+
+	// a binary file containing two packets
+	data, _ := hex.DecodeString(vector + vector)
+	path := filepath.Join(t.TempDir(), "capture.bin")
+
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout.Reset()
+
+	if code := run([]string{"-output", "json", "inspect", path}, &stdout, &stderr); code != exitOK {
+		t.Errorf("expected %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	if count := strings.Count(stdout.String(), `"cookie": "00001234"`); count != 2 {
+		t.Errorf("expected 2 packets, got %d: %s", count, stdout.String())
+	}
+}
+
+func TestRunInspectInvalid(t *testing.T) {
+	var stdout, stderr strings.Builder
+
+	// truncated packet
+	if code := run([]string{"inspect", "a1010e1234910a636f72"}, &stdout, &stderr); code != exitTransport {
+		t.Errorf("expected %d, got %d", exitTransport, code)
+	}
+
+	if code := run([]string{"inspect", "abc"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+}
+
+func TestRunInspectMissingFile(t *testing.T) {
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"inspect", "missing.bin"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+}
+
+func TestRunInspectPcap(t *testing.T) {
+	vector, _ := binrpc.VectorHex(0x1234, "core.echo", 42)
+	payload, _ := hex.DecodeString(vector)
+
+	// a capture with the raw IP link type, containing a UDP datagram from 127.0.0.1:40000 to 127.0.0.2:2046
+	var frame bytes.Buffer
+
+	frame.Write([]byte{0x45, 0})
+	binary.Write(&frame, binary.BigEndian, uint16(28+len(payload)))
+	frame.Write([]byte{0, 0, 0, 0, 64, 17, 0, 0, 127, 0, 0, 1, 127, 0, 0, 2})
+	binary.Write(&frame, binary.BigEndian, []uint16{40000, 2046, uint16(8 + len(payload)), 0})
+	frame.Write(payload)
+
+	var capture bytes.Buffer
+
+	binary.Write(&capture, binary.LittleEndian, []uint32{0xa1b2c3d4, 0x00040002, 0, 0, 65535, 101})
+	binary.Write(&capture, binary.LittleEndian, []uint32{1700000000, 0, uint32(frame.Len()), uint32(frame.Len())})
+	capture.Write(frame.Bytes())
+
+	path := filepath.Join(t.TempDir(), "ctl.pcap")
+
+	if err := os.WriteFile(path, capture.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"inspect", "-port", "2046", path}, &stdout, &stderr); code != exitOK {
+		t.Errorf("expected %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	expected := "==> packet 1: 22:13:20.000000 udp 127.0.0.1:40000 > 127.0.0.2:2046, cookie 00001234, 19 bytes <==\n" +
+		"core.echo\n42\n"
+
+	if stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+
+	stdout.Reset()
+
+	if code := run([]string{"inspect", "-port", "2049", path}, &stdout, &stderr); code != exitOK || stdout.Len() != 0 {
+		t.Errorf("expected no packets on another port, got %d: %q", code, stdout.String())
+	}
+}
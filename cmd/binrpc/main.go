@@ -0,0 +1,406 @@
+// Command binrpc invokes an RPC function of Kamailio through the ctl module, like kamcmd.
+//
+// Usage:
+//
+//	binrpc [-s address | -instance name | -all] [-output format] [-timeout duration] method [args...]
+//	binrpc [-s address | -instance name] completion bash|zsh|fish
+//	binrpc [-output format] inspect [-port port] [file | hex...]
+//
+// The address is "tcp:host:port", "udp:host:port", "unix:path" or "unixd:path" for unix datagram sockets (default
+// "unix:/run/kamailio/kamailio_ctl"). IPv6 literals are written "tcp:[fe80::1%eth0]:2049", brackets being optional.
+// URLs such as "unix:///run/kamailio/kamailio_ctl" or "binrpc://host:port" are accepted too, see binrpc.ParseAddress.
+//
+// Instances can be named in a JSON config file (default "binrpc/config.json" in the user config directory, such as
+// ~/.config/binrpc/config.json):
+//
+//	{
+//		"instances": {
+//			"edge1": {"address": "tcp:10.0.0.1:2049", "timeout": "10s"},
+//			"edge2": {"address": "10.0.0.2:2046", "transport": "udp", "dial_timeout": "1s"}
+//		}
+//	}
+//
+// With -instance, the command is sent to the named instance. With -all, the command is sent to all the instances
+// concurrently: json and yaml outputs are combined into one object keyed by instance name, other outputs are written
+// one instance after the other. The exit code is the highest of all instances.
+//
+// The timeout of an instance includes the dial, which dial_timeout can bound further.
+//
+// Args that look like integers are sent as int, others as string. The type can be forced with a prefix:
+// "s:" for string, "i:" for int and "d:" for double (e.g. "s:42").
+//
+// The output format is one of "text" (kamcmd style, the default), "json", "yaml", "table" or "flat", see package
+// format.
+//
+// The completion subcommand prints a completion script for bash, zsh or fish. Method names are completed with the
+// methods returned by system.listMethods on the instance selected by the -s, -config and -instance flags already typed:
+//
+//	source <(binrpc completion bash)
+//	binrpc completion fish > ~/.config/fish/completions/binrpc.fish
+//
+// The inspect subcommand decodes packets offline, for captures and bug reports. Each arg is a file containing packets
+// in binary or in hex, or a packet written in hex. Without args, packets are read from stdin. Files can also be pcap
+// captures, whose TCP streams are reassembled (see package capture), in which case -port selects the ctl traffic:
+//
+//	binrpc -output json inspect a1010e1234910a636f72652e6563686f00102a
+//	binrpc inspect -port 2049 ctl.pcap
+//
+// Exit codes:
+//
+//	0  success
+//	1  transport failure (connection, timeout, invalid reply)
+//	2  invalid usage
+//	3  fault reply with a 4xx code (e.g. invalid parameters)
+//	4  fault reply with another code (e.g. 500 command not found)
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/florentchauveau/go-kamailio-binrpc/v3/format"
+)
+
+// exit codes, see the package documentation
+const (
+	exitOK = iota
+	exitTransport
+	exitUsage
+	exitFault4xx
+	exitFault
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// target is a Kamailio instance the command is sent to.
+type target struct {
+	name        string
+	network     string
+	address     string
+	timeout     time.Duration
+	dialTimeout time.Duration
+}
+
+// result is the outcome of the command on a target.
+type result struct {
+	target  target
+	records []binrpc.Record
+	err     error
+}
+
+// run executes the command with args, and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("binrpc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: binrpc [-s address | -instance name | -all] [-output format] [-timeout duration] method [args...]")
+		fmt.Fprintln(stderr, "       binrpc [-s address | -instance name] completion bash|zsh|fish")
+		fmt.Fprintln(stderr, "       binrpc [-output format] inspect [-port port] [file | hex...]")
+		flags.PrintDefaults()
+	}
+
+	address := flags.String("s", "unix:/run/kamailio/kamailio_ctl", "address of the ctl module: tcp:host:port, udp:host:port, unix:path or unixd:path")
+	output := flags.String("output", "text", "output format: text, json, yaml, table or flat")
+	timeout := flags.Duration("timeout", 5*time.Second, "timeout of the call")
+	configPath := flags.String("config", defaultConfigPath(), "config file defining named instances")
+	instanceName := flags.String("instance", "", "name of the instance to call, defined in the config file")
+	all := flags.Bool("all", false, "call all the instances defined in the config file")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	selection := targetFlags{
+		address:    *address,
+		timeout:    *timeout,
+		configPath: *configPath,
+		instance:   *instanceName,
+		all:        *all,
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		selection.timeoutSet = selection.timeoutSet || f.Name == "timeout"
+	})
+
+	if flags.Arg(0) == "completion" {
+		return runCompletion(flags.Args()[1:], selection, stdout, stderr)
+	}
+
+	formatter, ok := format.Lookup(*output)
+
+	if !ok {
+		fmt.Fprintf(stderr, "binrpc: unknown output format %s\n", *output)
+		return exitUsage
+	}
+
+	if flags.Arg(0) == "inspect" {
+		return runInspect(flags.Args()[1:], *output, stdout, stderr)
+	}
+
+	var callArgs []any
+
+	for _, arg := range flags.Args()[1:] {
+		value, err := parseArg(arg)
+
+		if err != nil {
+			fmt.Fprintf(stderr, "binrpc: %v\n", err)
+			return exitUsage
+		}
+
+		callArgs = append(callArgs, value)
+	}
+
+	targets, err := selection.targets()
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpc: %v\n", err)
+		return exitUsage
+	}
+
+	results := make([]result, len(targets))
+
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		wg.Add(1)
+
+		go func(i int, t target) {
+			defer wg.Done()
+
+			records, err := t.call(flags.Arg(0), callArgs)
+			results[i] = result{t, records, err}
+		}(i, t)
+	}
+
+	wg.Wait()
+
+	if !*all {
+		return writeResult(stdout, stderr, formatter, results[0])
+	}
+
+	if encoder, ok := format.LookupEncoder(*output); ok {
+		return writeCombined(stdout, stderr, encoder, results)
+	}
+
+	code := exitOK
+
+	for _, result := range results {
+		fmt.Fprintf(stdout, "==> %s <==\n", result.target.name)
+		code = max(code, writeResult(stdout, stderr, formatter, result))
+	}
+
+	return code
+}
+
+// targetFlags are the flags selecting the instances the command is sent to.
+type targetFlags struct {
+	address    string
+	timeout    time.Duration
+	timeoutSet bool
+	configPath string
+	instance   string
+	all        bool
+}
+
+// targets returns the instances selected by the flags: the instances of the config file with -instance or -all, and
+// the -s address otherwise. An explicit -timeout takes precedence over the timeouts of the config file. It fails if
+// no instance is selected, such as with -all and a config file without instances.
+func (f targetFlags) targets() (targets []target, err error) {
+	if !f.all && f.instance == "" {
+		t := target{timeout: f.timeout}
+
+		if t.network, t.address, err = binrpc.ParseAddress(f.address); err != nil {
+			return nil, err
+		}
+
+		return []target{t}, nil
+	}
+
+	config, err := loadConfig(f.configPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{f.instance}
+
+	if f.all {
+		names = config.names()
+	}
+
+	for _, name := range names {
+		instance, ok := config.Instances[name]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown instance %s", name)
+		}
+
+		t := target{
+			name:        name,
+			timeout:     time.Duration(instance.Timeout),
+			dialTimeout: time.Duration(instance.DialTimeout),
+		}
+
+		if t.network, t.address, err = binrpc.ParseAddress(instance.Address); err != nil {
+			return nil, fmt.Errorf("instance %s: %w", name, err)
+		}
+
+		if instance.Transport != "" {
+			t.network = instance.Transport
+		}
+
+		if t.timeout == 0 || f.timeoutSet {
+			t.timeout = f.timeout
+		}
+
+		targets = append(targets, t)
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no instance in %s", f.configPath)
+	}
+
+	return targets, nil
+}
+
+// call dials the target, calls method with args, and closes the connection. The dial counts in the timeout.
+func (t target) call(method string, args []any) ([]binrpc.Record, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+
+	client, err := binrpc.Dial(t.network, t.address, binrpc.WithTimeouts(binrpc.Timeouts{Dial: t.dialLimit()}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Close()
+
+	return client.CallContext(ctx, method, args...)
+}
+
+// dialLimit returns the dial timeout of the target, bounded by its timeout, which is used if it is not set.
+func (t target) dialLimit() time.Duration {
+	if t.dialTimeout == 0 || t.dialTimeout > t.timeout {
+		return t.timeout
+	}
+
+	return t.dialTimeout
+}
+
+// writeResult writes the records of result with formatter, or its error, and returns the exit code.
+func writeResult(stdout, stderr io.Writer, formatter format.Formatter, result result) int {
+	prefix := "binrpc: "
+
+	if result.target.name != "" {
+		prefix += result.target.name + ": "
+	}
+
+	if result.err != nil {
+		fmt.Fprintf(stderr, "%s%v\n", prefix, result.err)
+		return exitCode(result.err)
+	}
+
+	if err := formatter(stdout, result.records); err != nil {
+		fmt.Fprintf(stderr, "%s%v\n", prefix, err)
+		return exitTransport
+	}
+
+	return exitOK
+}
+
+// writeCombined writes results as one object keyed by instance name, and returns the exit code.
+// Errors are written as an object with an "error" key.
+func writeCombined(stdout, stderr io.Writer, encoder format.Encoder, results []result) int {
+	code := exitOK
+	combined := format.NewObject()
+
+	for _, result := range results {
+		var reply any
+
+		err := result.err
+
+		if err == nil {
+			reply, err = format.Reply(result.records)
+		}
+
+		if err != nil {
+			fmt.Fprintf(stderr, "binrpc: %s: %v\n", result.target.name, err)
+			code = max(code, exitCode(err))
+
+			failure := format.NewObject()
+			failure.Add("error", err.Error())
+			reply = failure
+		}
+
+		combined.Add(result.target.name, reply)
+	}
+
+	if err := encoder(stdout, combined); err != nil {
+		fmt.Fprintf(stderr, "binrpc: %v\n", err)
+		return exitTransport
+	}
+
+	return code
+}
+
+// exitCode returns the exit code matching the error of a call.
+func exitCode(err error) int {
+	var fault *binrpc.RPCError
+
+	if !errors.As(err, &fault) {
+		return exitTransport
+	}
+
+	if fault.Code >= 400 && fault.Code < 500 {
+		return exitFault4xx
+	}
+
+	return exitFault
+}
+
+// parseArg returns the value of an arg, see the package documentation.
+func parseArg(arg string) (any, error) {
+	prefix, value, found := strings.Cut(arg, ":")
+
+	if found {
+		switch prefix {
+		case "s":
+			return value, nil
+		case "i":
+			if n, err := strconv.Atoi(value); err == nil {
+				return n, nil
+			}
+
+			return nil, fmt.Errorf("invalid int arg: %s", arg)
+		case "d":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f, nil
+			}
+
+			return nil, fmt.Errorf("invalid double arg: %s", arg)
+		}
+	}
+
+	if n, err := strconv.Atoi(arg); err == nil {
+		return n, nil
+	}
+
+	return arg, nil
+}
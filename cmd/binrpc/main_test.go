@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+func TestParseArg(t *testing.T) {
+	tests := map[string]any{
+		"42":     42,
+		"s:42":   "42",
+		"i:42":   42,
+		"d:1.5":  1.5,
+		"all":    "all",
+		"sip:me": "sip:me",
+	}
+
+	for arg, expected := range tests {
+		value, err := parseArg(arg)
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		if value != expected {
+			t.Errorf("%s: expected %v (%T), got %v (%T)", arg, expected, expected, value, value)
+		}
+	}
+
+	if _, err := parseArg("i:abc"); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := map[error]int{
+		&binrpc.RPCError{Code: 400, Message: "Invalid parameters"}: exitFault4xx,
+		&binrpc.RPCError{Code: 500, Message: "command not found"}:  exitFault,
+		fmt.Errorf("call: %w", &binrpc.RPCError{Code: 404}):        exitFault4xx,
+		io.EOF: exitTransport,
+	}
+
+	for err, expected := range tests {
+		if code := exitCode(err); code != expected {
+			t.Errorf("%v: expected %d, got %d", err, expected, code)
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr strings.Builder
+
+	if code := run(nil, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+
+	if code := run([]string{"-output", "xml", "tm.stats"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("expected %d, got %d", exitUsage, code)
+	}
+
+	if code := run([]string{"-s", "unix:/nonexistent", "tm.stats"}, &stdout, &stderr); code != exitTransport {
+		t.Errorf("expected %d, got %d", exitTransport, code)
+	}
+}
+
+func TestTargetDialLimit(t *testing.T) {
+	tests := []struct {
+		target   target
+		expected time.Duration
+	}{
+		// -s address, or an instance without dial_timeout
+		{target{timeout: 5 * time.Second}, 5 * time.Second},
+		{target{timeout: 5 * time.Second, dialTimeout: time.Second}, time.Second},
+		{target{timeout: time.Second, dialTimeout: 5 * time.Second}, time.Second},
+	}
+
+	for _, test := range tests {
+		if limit := test.target.dialLimit(); limit != test.expected {
+			t.Errorf("%+v: expected %s, got %s", test.target, test.expected, limit)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := binrpc.NewServer()
+	defer server.Close()
+
+	server.Handle("tm.stats", func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+		return []any{[]binrpc.StructItem{
+			{Key: "current", Value: binrpc.Record{Type: binrpc.TypeInt, Value: 3}},
+			{Key: "total", Value: binrpc.Record{Type: binrpc.TypeInt, Value: 1200}},
+		}}, nil
+	})
+
+	server.Handle("core.echo", func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+		return request.Args, nil
+	})
+
+	go server.ServePacket(conn)
+
+	address := "udp:" + conn.LocalAddr().String()
+
+	var stdout, stderr strings.Builder
+
+	if code := run([]string{"-s", address, "-output", "json", "tm.stats"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	if expected := `"total": 1200`; !strings.Contains(stdout.String(), expected) {
+		t.Errorf("expected %s, got %s", expected, stdout.String())
+	}
+
+	stdout.Reset()
+
+	if code := run([]string{"-s", address, "core.echo", "s:42"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("expected %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	if output := strings.TrimSpace(stdout.String()); output != "42" {
+		t.Errorf(`expected "42", got "%s"`, output)
+	}
+
+	if code := run([]string{"-s", address, "core.foo"}, &stdout, &stderr); code != exitFault {
+		t.Errorf("expected %d, got %d", exitFault, code)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// method is an RPC method to generate.
+type method struct {
+	Name string
+
+	// Signature is nil if the parameters of the method are unknown.
+	Signature *binrpc.Signature
+}
+
+// replyTypes are the Go types of the replies decoded by binrpc.Unmarshal, keyed by method.
+var replyTypes = map[string]string{
+	"tm.stats":           "binrpc.TMStats",
+	"sl.stats":           "binrpc.SLStats",
+	"dlg.list":           "[]binrpc.Dialog",
+	"dlg.stats_active":   "binrpc.DialogStats",
+	"system.listMethods": "[]string",
+	"system.methodHelp":  "string",
+}
+
+// goTypes are the Go types of the parameters, keyed by BINRPC type.
+var goTypes = map[uint8]string{
+	binrpc.TypeInt:    "int",
+	binrpc.TypeString: "string",
+	binrpc.TypeDouble: "float64",
+	binrpc.TypeBytes:  "[]byte",
+	binrpc.TypeStruct: "map[string]any",
+	binrpc.TypeArray:  "[]any",
+}
+
+// generate returns the gofmt-ed source of package packageName, with the bindings of methods.
+func generate(packageName string, methods []method) ([]byte, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "// Code generated by binrpcgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	fmt.Fprintf(&b, "import (\n\"context\"\n\nbinrpc \"github.com/florentchauveau/go-kamailio-binrpc/v3\"\n)\n\n")
+	fmt.Fprintf(&b, "// Client calls the RPC methods of Kamailio with typed args.\n")
+	fmt.Fprintf(&b, "type Client struct {\ncaller binrpc.Caller\n}\n\n")
+	fmt.Fprintf(&b, "// New returns a Client calling the methods with caller, such as a *binrpc.Client.\n")
+	fmt.Fprintf(&b, "func New(caller binrpc.Caller) *Client {\nreturn &Client{caller: caller}\n}\n")
+
+	used := make(map[string]bool)
+
+	for _, m := range methods {
+		name := uniqueName(used, exportedName(m.Name, "Method"))
+
+		if err := writeMethod(&b, name, m); err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Name, err)
+		}
+	}
+
+	source, err := format.Source([]byte(b.String()))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code: %w", err)
+	}
+
+	return source, nil
+}
+
+// writeMethod writes the args struct of m, if it has a signature, and the method name of Client calling m.
+func writeMethod(b *strings.Builder, name string, m method) error {
+	params := ""
+	args := ", args..."
+
+	switch {
+	case m.Signature == nil:
+		params = ", args ...any"
+	case len(m.Signature.Params) == 0:
+		args = ""
+	default:
+		if err := writeArgs(b, name, m); err != nil {
+			return err
+		}
+
+		params = ", args " + name + "Args"
+		args = ", args.args()..."
+	}
+
+	fmt.Fprintf(b, "\n// %s calls %s.\n", name, m.Name)
+
+	if reply, ok := replyTypes[m.Name]; ok {
+		fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context%s) (%s, error) {\n", name, params, reply)
+		fmt.Fprintf(b, "return binrpc.CallContext[%s](ctx, c.caller, %q%s)\n}\n", reply, m.Name, args)
+
+		return nil
+	}
+
+	fmt.Fprintf(b, "func (c *Client) %s(ctx context.Context%s) ([]binrpc.Record, error) {\n", name, params)
+	fmt.Fprintf(b, "return c.caller.CallContext(ctx, %q%s)\n}\n", m.Name, args)
+
+	return nil
+}
+
+// writeArgs writes the args struct of m, and its args method returning the positional args. Optional parameters are
+// pointers: as parameters are positional, the first nil one ends the args.
+func writeArgs(b *strings.Builder, name string, m method) error {
+	fields := make([]string, len(m.Signature.Params))
+	used := make(map[string]bool)
+
+	fmt.Fprintf(b, "\n// %sArgs are the args of %s.\n", name, m.Name)
+	fmt.Fprintf(b, "type %sArgs struct {\n", name)
+
+	for i, param := range m.Signature.Params {
+		goType, ok := goTypes[param.Type]
+
+		if !ok {
+			return fmt.Errorf("param %s: unknown type %d", param.Name, param.Type)
+		}
+
+		fields[i] = uniqueName(used, exportedName(param.Name, fmt.Sprintf("Arg%d", i+1)))
+
+		if param.Optional {
+			goType = "*" + goType
+		}
+
+		fmt.Fprintf(b, "%s %s\n", fields[i], goType)
+	}
+
+	fmt.Fprintf(b, "}\n\n")
+	fmt.Fprintf(b, "func (a %sArgs) args() []any {\n", name)
+	fmt.Fprintf(b, "args := []any{")
+
+	optional := -1
+
+	for i, param := range m.Signature.Params {
+		if param.Optional {
+			optional = i
+			break
+		}
+
+		if i > 0 {
+			b.WriteString(", ")
+		}
+
+		fmt.Fprintf(b, "a.%s", fields[i])
+	}
+
+	fmt.Fprintf(b, "}\n")
+
+	if optional >= 0 {
+		for i, param := range m.Signature.Params[optional:] {
+			field := fields[optional+i]
+
+			if !param.Optional {
+				return fmt.Errorf("param %s: required after an optional param", param.Name)
+			}
+
+			fmt.Fprintf(b, "\nif a.%s == nil {\nreturn args\n}\n\nargs = append(args, *a.%s)\n", field, field)
+		}
+	}
+
+	fmt.Fprintf(b, "\nreturn args\n}\n")
+
+	return nil
+}
+
+// exportedName returns s as an exported Go identifier, its words being capitalized: "dispatcher.set_state" becomes
+// DispatcherSetState, and "htable.listTables" HtableListTables. fallback is returned if s has no letter, and used as a
+// prefix if s starts with a digit.
+func exportedName(s string, fallback string) string {
+	var b strings.Builder
+
+	upper := true
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+
+	if !strings.ContainsFunc(name, unicode.IsLetter) {
+		return fallback
+	}
+
+	if unicode.IsDigit(rune(name[0])) {
+		return fallback + name
+	}
+
+	return name
+}
+
+// uniqueName returns name, followed by a number if it is already used, and marks it as used.
+func uniqueName(used map[string]bool, name string) string {
+	unique := name
+
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	used[unique] = true
+
+	return unique
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"net"
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+func TestExportedName(t *testing.T) {
+	tests := map[string]string{
+		"dispatcher.set_state": "DispatcherSetState",
+		"htable.listTables":    "HtableListTables",
+		"call-id":              "CallId",
+		"#1":                   "Arg",
+		"2xx":                  "Arg2xx",
+	}
+
+	for s, expected := range tests {
+		if name := exportedName(s, "Arg"); name != expected {
+			t.Errorf("%s: expected %s, got %s", s, expected, name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := binrpc.NewServer()
+	defer server.Close()
+
+	for _, method := range []string{"dispatcher.add", "dispatcher.list", "tm.stats", "core.echo", "app.set"} {
+		server.Handle(method, func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+			return nil, nil
+		})
+	}
+
+	server.Handle("system.methodSignature", func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+		if method, _ := request.Args[0].(binrpc.Record).String(); method == "app.set" {
+			return []any{[]any{"int", "string", "double"}}, nil
+		}
+
+		return nil, &binrpc.RPCError{Code: 500, Message: "Not implemented"}
+	})
+
+	go server.Serve(listener)
+
+	var stdout, stderr strings.Builder
+
+	args := []string{"-s", "tcp:" + listener.Addr().String(), "-package", "edge", "dispatcher.", "tm.stats", "core.echo", "app.set"}
+
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected 0, got %d: %s", code, stderr.String())
+	}
+
+	source := stdout.String()
+
+	if _, err = parser.ParseFile(token.NewFileSet(), "edge.go", source, 0); err != nil {
+		t.Fatalf("invalid source: %v\n%s", err, source)
+	}
+
+	for _, expected := range []string{
+		"package edge",
+		"func (c *Client) DispatcherAdd(ctx context.Context, args DispatcherAddArgs) ([]binrpc.Record, error)",
+		"\tFlags    *int\n",
+		"args := []any{a.Group, a.Address}",
+		"func (c *Client) DispatcherList(ctx context.Context) ([]binrpc.Record, error)",
+		"func (c *Client) TmStats(ctx context.Context, args ...any) (binrpc.TMStats, error)",
+		"func (c *Client) CoreEcho(ctx context.Context, args ...any) ([]binrpc.Record, error)",
+		"type AppSetArgs struct {\n\tArg1 string\n\tArg2 float64\n}",
+	} {
+		if !strings.Contains(source, expected) {
+			t.Errorf("expected %q in:\n%s", expected, source)
+		}
+	}
+
+	if strings.Contains(source, "SystemListMethods") {
+		t.Error("unexpected method not matching the patterns")
+	}
+}
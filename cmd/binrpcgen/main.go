@@ -0,0 +1,148 @@
+// Command binrpcgen generates typed Go bindings for the RPC methods exported by a Kamailio instance.
+//
+// Usage:
+//
+//	binrpcgen [-s address] [-package name] [-o file] [-timeout duration] [pattern...]
+//
+// The methods are listed with system.listMethods, and only the methods matching one of the patterns are generated
+// (all by default). A pattern ending with "." matches all the methods of a module, such as "dispatcher.", other
+// patterns match a method exactly. The address is parsed by binrpc.ParseAddress (default
+// "unix:/run/kamailio/kamailio_ctl").
+//
+// For each method, the generated Client has a method named after it, "dispatcher.set_state" becoming
+// DispatcherSetState. The parameters are the ones of the signature registered in package binrpc (see
+// binrpc.RegisterSignature), or else the one returned by system.methodSignature. Methods with a signature take an args
+// struct, such as DispatcherSetStateArgs, whose optional parameters are pointers. Methods without signature take
+// variadic args. Methods whose reply has a decoder in package binrpc, such as tm.stats, return it, the others return
+// the records of the reply:
+//
+//	//go:generate binrpcgen -s tcp:127.0.0.1:2049 -package kamailio -o kamailio.go dispatcher. tm.stats
+//
+//	kam := kamailio.New(client)
+//	records, err := kam.DispatcherSetState(ctx, kamailio.DispatcherSetStateArgs{State: "ip", Group: 1, Address: uri})
+//	stats, err := kam.TmStats(ctx)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command with args, and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("binrpcgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: binrpcgen [-s address] [-package name] [-o file] [-timeout duration] [pattern...]")
+		flags.PrintDefaults()
+	}
+
+	address := flags.String("s", "unix:/run/kamailio/kamailio_ctl", "address of the ctl module, see binrpc.ParseAddress")
+	packageName := flags.String("package", "kamailio", "package name of the generated code")
+	output := flags.String("o", "", "file to write, instead of stdout")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of the introspection")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	methods, err := introspect(ctx, *address, flags.Args())
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpcgen: %v\n", err)
+		return 1
+	}
+
+	source, err := generate(*packageName, methods)
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpcgen: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(source)
+	} else {
+		err = os.WriteFile(*output, source, 0o644)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "binrpcgen: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// introspect returns the methods exported by Kamailio at address that match patterns, sorted by name, with their
+// signature.
+func introspect(ctx context.Context, address string, patterns []string) ([]method, error) {
+	client, err := binrpc.DialAddress(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer client.Close()
+
+	names, err := client.ListMethods(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	var methods []method
+
+	for _, name := range names {
+		if !matchAny(patterns, name) {
+			continue
+		}
+
+		m := method{Name: name}
+
+		if signature, ok := binrpc.LookupSignature(name); ok {
+			m.Signature = &signature
+		} else if signatures, err := client.MethodSignature(ctx, name); err == nil && len(signatures) > 0 {
+			// several signatures cannot be expressed by a single args struct: the first one is used
+			m.Signature = &signatures[0]
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		methods = append(methods, m)
+	}
+
+	return methods, nil
+}
+
+// matchAny reports whether name matches one of patterns, or patterns is empty.
+func matchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if pattern == name || (strings.HasSuffix(pattern, ".") && strings.HasPrefix(name, pattern)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,146 @@
+// Package collector provides Prometheus collectors for the statistics exposed by Kamailio over BINRPC.
+//
+// Each collector issues its RPC commands when it is collected:
+//
+//	client, err := binrpc.Dial("tcp", "localhost:2049")
+//
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	prometheus.MustRegister(collector.NewTMStats(client))
+//
+// If a command fails, the collector reports an invalid metric, which fails the scrape. Core reports the outcome of
+// its commands with a gauge instead, as core.tcp_info fails when TCP is disabled.
+//
+// This package is a separate module, so that the binrpc package stays free of dependencies.
+package collector
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var errEmptyReply = errors.New("empty reply")
+
+// Caller issues RPC commands. It is implemented by *binrpc.Client, *binrpc.MuxClient, *binrpc.Cache and *binrpc.Pool.
+type Caller interface {
+	CallContext(ctx context.Context, method string, args ...any) ([]binrpc.Record, error)
+}
+
+// Option configures a collector.
+type Option func(*config)
+
+type config struct {
+	namespace   string
+	constLabels prometheus.Labels
+	timeout     time.Duration
+
+	dialogProfiles []string
+}
+
+// WithNamespace sets the namespace of the metrics. The default is "kamailio".
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		c.namespace = namespace
+	}
+}
+
+// WithConstLabels sets labels added to all the metrics, such as the instance name.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(c *config) {
+		c.constLabels = labels
+	}
+}
+
+// WithTimeout sets the maximum duration of each RPC command. The default is 5 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.timeout = timeout
+	}
+}
+
+// WithDialogProfiles sets the dialog profiles whose size is collected by the Dialog collector.
+func WithDialogProfiles(profiles ...string) Option {
+	return func(c *config) {
+		c.dialogProfiles = profiles
+	}
+}
+
+func newConfig(options []Option) config {
+	c := config{
+		namespace: "kamailio",
+		timeout:   5 * time.Second,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+// desc returns the description of a metric of subsystem.
+func (c config) desc(subsystem, name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(c.namespace, subsystem, name), help, labels, c.constLabels)
+}
+
+// call issues an RPC command with the configured timeout.
+func (c config) call(caller Caller, method string, args ...any) ([]binrpc.Record, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	return caller.CallContext(ctx, method, args...)
+}
+
+// structMetric maps an item of a struct reply to a metric.
+type structMetric struct {
+	desc        *prometheus.Desc
+	valueType   prometheus.ValueType
+	labelValues []string
+}
+
+// structItems returns the items of the first record of a reply, which must be a struct.
+func structItems(records []binrpc.Record) ([]binrpc.StructItem, error) {
+	if len(records) == 0 {
+		return nil, errEmptyReply
+	}
+
+	return records[0].StructItems()
+}
+
+// collectStruct sends a metric for each item of items found in metrics. Other items are ignored.
+func collectStruct(ch chan<- prometheus.Metric, items []binrpc.StructItem, metrics map[string]structMetric) {
+	for _, item := range items {
+		metric, ok := metrics[item.Key]
+
+		if !ok {
+			continue
+		}
+
+		var value float64
+
+		if err := item.Value.Scan(&value); err != nil {
+			ch <- prometheus.NewInvalidMetric(metric.desc, err)
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(metric.desc, metric.valueType, value, metric.labelValues...)
+	}
+}
+
+// describeStruct sends the descriptions of metrics.
+func describeStruct(ch chan<- *prometheus.Desc, metrics map[string]structMetric) {
+	seen := make(map[*prometheus.Desc]bool)
+
+	for _, metric := range metrics {
+		if !seen[metric.desc] {
+			seen[metric.desc] = true
+			ch <- metric.desc
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+// fakeCaller replies to each method with the records in replies.
+type fakeCaller map[string][]binrpc.Record
+
+func (f fakeCaller) CallContext(ctx context.Context, method string, args ...any) ([]binrpc.Record, error) {
+	records, ok := f[method]
+
+	if !ok {
+		return nil, fmt.Errorf("command %s not found", method)
+	}
+
+	return records, nil
+}
+
+// structReply returns a reply made of one struct, built from key and value pairs.
+func structReply(pairs ...any) []binrpc.Record {
+	return []binrpc.Record{structRecord(pairs...)}
+}
+
+func structRecord(pairs ...any) binrpc.Record {
+	var items []binrpc.StructItem
+
+	for i := 0; i < len(pairs); i += 2 {
+		items = append(items, binrpc.StructItem{
+			Key:   pairs[i].(string),
+			Value: record(pairs[i+1]),
+		})
+	}
+
+	return binrpc.Record{Type: binrpc.TypeStruct, Value: items}
+}
+
+func record(value any) binrpc.Record {
+	switch v := value.(type) {
+	case binrpc.Record:
+		return v
+	case int:
+		return binrpc.Record{Type: binrpc.TypeInt, Value: v}
+	case float64:
+		return binrpc.Record{Type: binrpc.TypeDouble, Value: v}
+	default:
+		return binrpc.Record{Type: binrpc.TypeString, Value: fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Core collects the resources of the Kamailio instance: shared memory (core.shmmem), private memory of each process
+// (pkg.stats), TCP connections (core.tcp_info) and uptime (core.uptime).
+//
+// Unlike the other collectors, a failed command does not fail the scrape: core.tcp_info fails when TCP is disabled.
+// The outcome of each command is reported by kamailio_core_command_up, labelled with the command.
+type Core struct {
+	caller Caller
+	config config
+
+	shm    map[string]structMetric
+	tcp    map[string]structMetric
+	uptime map[string]structMetric
+	pkg    map[string]*prometheus.Desc
+	up     *prometheus.Desc
+}
+
+// NewCore returns a collector of the core resources.
+func NewCore(caller Caller, options ...Option) *Core {
+	c := newConfig(options)
+	labels := []string{"pid", "rank", "desc"}
+
+	return &Core{
+		caller: caller,
+		config: c,
+		shm: map[string]structMetric{
+			"total":     {c.desc("core", "shm_total_bytes", "Size of the shared memory."), prometheus.GaugeValue, nil},
+			"free":      {c.desc("core", "shm_free_bytes", "Free shared memory."), prometheus.GaugeValue, nil},
+			"used":      {c.desc("core", "shm_used_bytes", "Shared memory used, without the memory manager overhead."), prometheus.GaugeValue, nil},
+			"real_used": {c.desc("core", "shm_real_used_bytes", "Shared memory used, including the memory manager overhead."), prometheus.GaugeValue, nil},
+			"max_used":  {c.desc("core", "shm_max_used_bytes", "Maximum shared memory used since startup."), prometheus.GaugeValue, nil},
+			"fragments": {c.desc("core", "shm_fragments", "Number of fragments of the shared memory."), prometheus.GaugeValue, nil},
+		},
+		tcp: map[string]structMetric{
+			"readers":                {c.desc("core", "tcp_readers", "Number of TCP reader processes."), prometheus.GaugeValue, nil},
+			"max_connections":        {c.desc("core", "tcp_max_connections", "Maximum number of TCP connections."), prometheus.GaugeValue, nil},
+			"max_tls_connections":    {c.desc("core", "tls_max_connections", "Maximum number of TLS connections."), prometheus.GaugeValue, nil},
+			"opened_connections":     {c.desc("core", "tcp_connections", "Number of opened TCP connections."), prometheus.GaugeValue, nil},
+			"opened_tls_connections": {c.desc("core", "tls_connections", "Number of opened TLS connections."), prometheus.GaugeValue, nil},
+			"write_queued_bytes":     {c.desc("core", "tcp_write_queued_bytes", "Number of bytes queued for writing on TCP connections."), prometheus.GaugeValue, nil},
+		},
+		uptime: map[string]structMetric{
+			"uptime": {c.desc("core", "uptime_seconds", "Number of seconds since startup."), prometheus.GaugeValue, nil},
+		},
+		pkg: map[string]*prometheus.Desc{
+			"used":        c.desc("pkg", "used_bytes", "Private memory used by the process, without the memory manager overhead.", labels...),
+			"free":        c.desc("pkg", "free_bytes", "Free private memory of the process.", labels...),
+			"real_used":   c.desc("pkg", "real_used_bytes", "Private memory used by the process, including the memory manager overhead.", labels...),
+			"total_size":  c.desc("pkg", "total_bytes", "Size of the private memory of the process.", labels...),
+			"total_frags": c.desc("pkg", "fragments", "Number of fragments of the private memory of the process.", labels...),
+		},
+		up: c.desc("core", "command_up", "Whether the last call of the core command succeeded.", "command"),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Core) Describe(ch chan<- *prometheus.Desc) {
+	describeStruct(ch, c.shm)
+	describeStruct(ch, c.tcp)
+	describeStruct(ch, c.uptime)
+
+	for _, desc := range c.pkg {
+		ch <- desc
+	}
+
+	ch <- c.up
+}
+
+// Collect implements prometheus.Collector. Each command is collected independently: if one fails, the metrics of the
+// others are still collected, and its kamailio_core_command_up is 0.
+func (c *Core) Collect(ch chan<- prometheus.Metric) {
+	for method, metrics := range map[string]map[string]structMetric{
+		"core.shmmem":   c.shm,
+		"core.tcp_info": c.tcp,
+		"core.uptime":   c.uptime,
+	} {
+		c.collectUp(ch, method, c.collectStruct(ch, method, metrics))
+	}
+
+	c.collectUp(ch, "pkg.stats", c.collectPkg(ch))
+}
+
+// collectUp sends kamailio_core_command_up for method, 1 if err is nil.
+func (c *Core) collectUp(ch chan<- prometheus.Metric, method string, err error) {
+	up := 1.0
+
+	if err != nil {
+		up = 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, method)
+}
+
+func (c *Core) collectStruct(ch chan<- prometheus.Metric, method string, metrics map[string]structMetric) error {
+	records, err := c.config.call(c.caller, method)
+
+	if err != nil {
+		return err
+	}
+
+	items, err := structItems(records)
+
+	if err != nil {
+		return err
+	}
+
+	collectStruct(ch, items, metrics)
+
+	return nil
+}
+
+// collectPkg collects pkg.stats, which returns a struct for each process.
+func (c *Core) collectPkg(ch chan<- prometheus.Metric) error {
+	records, err := c.config.call(c.caller, "pkg.stats")
+
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		items, err := record.StructItems()
+
+		if err != nil {
+			return err
+		}
+
+		var pid, rank, desc string
+
+		for key, dest := range map[string]*string{"pid": &pid, "rank": &rank, "desc": &desc} {
+			if value, ok := record.Get(key); ok {
+				if err := value.Scan(dest); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, item := range items {
+			metric, ok := c.pkg[item.Key]
+
+			if !ok {
+				continue
+			}
+
+			var value float64
+
+			if err := item.Value.Scan(&value); err != nil {
+				return err
+			}
+
+			ch <- prometheus.MustNewConstMetric(metric, prometheus.GaugeValue, value, pid, rank, desc)
+		}
+	}
+
+	return nil
+}
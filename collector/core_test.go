@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCore(t *testing.T) {
+	caller := fakeCaller{
+		"core.shmmem":   structReply("total", 1024, "free", 512, "used", 400, "real_used", 512, "max_used", 600, "fragments", 3),
+		"core.tcp_info": structReply("readers", 8, "max_connections", 4096, "opened_connections", 2),
+		"core.uptime":   structReply("now", "Mon Jan  1 00:00:00 2024", "up_since", "Sun Dec 31 00:00:00 2023", "uptime", 86400),
+		"pkg.stats": []binrpc.Record{
+			structRecord("entry", 0, "pid", 100, "rank", 0, "used", 10, "free", 90, "real_used", 20, "total_size", 110, "total_frags", 4, "desc", "main process"),
+			structRecord("entry", 1, "pid", 101, "rank", 1, "used", 5, "free", 95, "real_used", 10, "total_size", 105, "total_frags", 2, "desc", "udp receiver"),
+		},
+	}
+
+	expected := `
+# HELP kamailio_core_shm_free_bytes Free shared memory.
+# TYPE kamailio_core_shm_free_bytes gauge
+kamailio_core_shm_free_bytes 512
+# HELP kamailio_core_tcp_connections Number of opened TCP connections.
+# TYPE kamailio_core_tcp_connections gauge
+kamailio_core_tcp_connections 2
+# HELP kamailio_core_uptime_seconds Number of seconds since startup.
+# TYPE kamailio_core_uptime_seconds gauge
+kamailio_core_uptime_seconds 86400
+# HELP kamailio_pkg_used_bytes Private memory used by the process, without the memory manager overhead.
+# TYPE kamailio_pkg_used_bytes gauge
+kamailio_pkg_used_bytes{desc="main process",pid="100",rank="0"} 10
+kamailio_pkg_used_bytes{desc="udp receiver",pid="101",rank="1"} 5
+`
+
+	err := testutil.CollectAndCompare(NewCore(caller), strings.NewReader(expected),
+		"kamailio_core_shm_free_bytes", "kamailio_core_tcp_connections", "kamailio_core_uptime_seconds", "kamailio_pkg_used_bytes")
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCoreTCPDisabled(t *testing.T) {
+	caller := fakeCaller{
+		"core.shmmem": structReply("total", 1024, "free", 512),
+		"core.uptime": structReply("uptime", 86400),
+		"pkg.stats":   []binrpc.Record{structRecord("pid", 100, "rank", 0, "used", 10, "desc", "main process")},
+	}
+
+	expected := `
+# HELP kamailio_core_command_up Whether the last call of the core command succeeded.
+# TYPE kamailio_core_command_up gauge
+kamailio_core_command_up{command="core.shmmem"} 1
+kamailio_core_command_up{command="core.tcp_info"} 0
+kamailio_core_command_up{command="core.uptime"} 1
+kamailio_core_command_up{command="pkg.stats"} 1
+# HELP kamailio_core_shm_free_bytes Free shared memory.
+# TYPE kamailio_core_shm_free_bytes gauge
+kamailio_core_shm_free_bytes 512
+`
+
+	err := testutil.CollectAndCompare(NewCore(caller), strings.NewReader(expected),
+		"kamailio_core_command_up", "kamailio_core_shm_free_bytes")
+
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,119 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Dialog collects the number of active dialogs returned by dlg.stats_active, and optionally the size of dialog
+// profiles returned by dlg.profile_get_size (see WithDialogProfiles).
+type Dialog struct {
+	caller Caller
+	config config
+
+	metrics map[string]structMetric
+	early   *prometheus.Desc
+	profile *prometheus.Desc
+	failure *prometheus.Desc
+}
+
+// NewDialog returns a collector of dlg.stats_active. The dialog module must be loaded.
+func NewDialog(caller Caller, options ...Option) *Dialog {
+	c := newConfig(options)
+
+	dialogs := c.desc("dialog", "dialogs", "Number of active dialogs, by state.", "state")
+
+	return &Dialog{
+		caller: caller,
+		config: c,
+		metrics: map[string]structMetric{
+			"starting":   {dialogs, prometheus.GaugeValue, []string{"starting"}},
+			"connecting": {dialogs, prometheus.GaugeValue, []string{"connecting"}},
+			"answering":  {dialogs, prometheus.GaugeValue, []string{"answering"}},
+			"ongoing":    {dialogs, prometheus.GaugeValue, []string{"ongoing"}},
+			"all":        {c.desc("dialog", "active_dialogs", "Number of active dialogs."), prometheus.GaugeValue, nil},
+		},
+		early:   c.desc("dialog", "early_dialogs", "Number of dialogs not answered yet."),
+		profile: c.desc("dialog", "profile_size", "Number of dialogs in the profile.", "profile"),
+		failure: c.desc("dialog", "stats", "Failure of dlg.stats_active or dlg.profile_get_size."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (d *Dialog) Describe(ch chan<- *prometheus.Desc) {
+	describeStruct(ch, d.metrics)
+
+	ch <- d.early
+	ch <- d.profile
+}
+
+// Collect implements prometheus.Collector.
+func (d *Dialog) Collect(ch chan<- prometheus.Metric) {
+	if err := d.collectActive(ch); err != nil {
+		ch <- prometheus.NewInvalidMetric(d.failure, err)
+	}
+
+	for _, profile := range d.config.dialogProfiles {
+		if err := d.collectProfile(ch, profile); err != nil {
+			ch <- prometheus.NewInvalidMetric(d.failure, err)
+		}
+	}
+}
+
+func (d *Dialog) collectActive(ch chan<- prometheus.Metric) error {
+	records, err := d.config.call(d.caller, "dlg.stats_active")
+
+	if err != nil {
+		return err
+	}
+
+	items, err := structItems(records)
+
+	if err != nil {
+		return err
+	}
+
+	collectStruct(ch, items, d.metrics)
+
+	var early float64
+
+	for _, item := range items {
+		switch item.Key {
+		case "starting", "connecting", "answering":
+			var value float64
+
+			if err := item.Value.Scan(&value); err != nil {
+				return err
+			}
+
+			early += value
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(d.early, prometheus.GaugeValue, early)
+
+	return nil
+}
+
+func (d *Dialog) collectProfile(ch chan<- prometheus.Metric, profile string) error {
+	records, err := d.config.call(d.caller, "dlg.profile_get_size", profile)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = structItems(records); err != nil {
+		return err
+	}
+
+	var size float64
+
+	if record, ok := records[0].Get("count"); ok {
+		if err := record.Scan(&size); err != nil {
+			return err
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(d.profile, prometheus.GaugeValue, size, profile)
+
+	return nil
+}
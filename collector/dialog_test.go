@@ -0,0 +1,58 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// profileCaller replies to dlg.profile_get_size with the size of the profile given as argument.
+type profileCaller struct {
+	fakeCaller
+	sizes map[string]int
+}
+
+func (p profileCaller) CallContext(ctx context.Context, method string, args ...any) ([]binrpc.Record, error) {
+	if method == "dlg.profile_get_size" {
+		profile := args[0].(string)
+		return structReply("profile", profile, "value", "", "count", p.sizes[profile]), nil
+	}
+
+	return p.fakeCaller.CallContext(ctx, method, args...)
+}
+
+func TestDialog(t *testing.T) {
+	caller := profileCaller{
+		fakeCaller: fakeCaller{
+			"dlg.stats_active": structReply("starting", 1, "connecting", 2, "answering", 3, "ongoing", 10, "all", 16),
+		},
+		sizes: map[string]int{
+			"inbound": 7,
+		},
+	}
+
+	expected := `
+# HELP kamailio_dialog_active_dialogs Number of active dialogs.
+# TYPE kamailio_dialog_active_dialogs gauge
+kamailio_dialog_active_dialogs 16
+# HELP kamailio_dialog_dialogs Number of active dialogs, by state.
+# TYPE kamailio_dialog_dialogs gauge
+kamailio_dialog_dialogs{state="answering"} 3
+kamailio_dialog_dialogs{state="connecting"} 2
+kamailio_dialog_dialogs{state="ongoing"} 10
+kamailio_dialog_dialogs{state="starting"} 1
+# HELP kamailio_dialog_early_dialogs Number of dialogs not answered yet.
+# TYPE kamailio_dialog_early_dialogs gauge
+kamailio_dialog_early_dialogs 6
+# HELP kamailio_dialog_profile_size Number of dialogs in the profile.
+# TYPE kamailio_dialog_profile_size gauge
+kamailio_dialog_profile_size{profile="inbound"} 7
+`
+
+	if err := testutil.CollectAndCompare(NewDialog(caller, WithDialogProfiles("inbound")), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"strconv"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Dispatcher collects the state of the destinations returned by dispatcher.list.
+type Dispatcher struct {
+	caller Caller
+	config config
+
+	sets     *prometheus.Desc
+	active   *prometheus.Desc
+	probing  *prometheus.Desc
+	flags    *prometheus.Desc
+	priority *prometheus.Desc
+	latency  map[string]*prometheus.Desc
+	timeouts *prometheus.Desc
+	failure  *prometheus.Desc
+}
+
+// NewDispatcher returns a collector of dispatcher.list. The dispatcher module must be loaded.
+// Destination metrics are labeled with the set ID and the URI of the destination.
+func NewDispatcher(caller Caller, options ...Option) *Dispatcher {
+	c := newConfig(options)
+	labels := []string{"set", "destination"}
+
+	return &Dispatcher{
+		caller:   caller,
+		config:   c,
+		sets:     c.desc("dispatcher", "sets", "Number of destination sets."),
+		active:   c.desc("dispatcher", "destination_active", "Whether the destination is active (1) or not (0).", labels...),
+		probing:  c.desc("dispatcher", "destination_probing", "Whether the destination is probed (1) or not (0).", labels...),
+		flags:    c.desc("dispatcher", "destination_flags", "Flags of the destination, in the flags label.", "set", "destination", "flags"),
+		priority: c.desc("dispatcher", "destination_priority", "Priority of the destination.", labels...),
+		latency: map[string]*prometheus.Desc{
+			"AVG": c.desc("dispatcher", "destination_latency_average_seconds", "Average latency of the destination.", labels...),
+			"STD": c.desc("dispatcher", "destination_latency_stddev_seconds", "Standard deviation of the latency of the destination.", labels...),
+			"EST": c.desc("dispatcher", "destination_latency_estimated_seconds", "Estimated latency of the destination.", labels...),
+			"MAX": c.desc("dispatcher", "destination_latency_max_seconds", "Maximum latency of the destination.", labels...),
+		},
+		timeouts: c.desc("dispatcher", "destination_latency_timeouts_total", "Total number of timeouts while measuring the latency of the destination.", labels...),
+		failure:  c.desc("dispatcher", "list", "Failure of dispatcher.list."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (d *Dispatcher) Describe(ch chan<- *prometheus.Desc) {
+	ch <- d.sets
+	ch <- d.active
+	ch <- d.probing
+	ch <- d.flags
+	ch <- d.priority
+
+	for _, desc := range d.latency {
+		ch <- desc
+	}
+
+	ch <- d.timeouts
+}
+
+// Collect implements prometheus.Collector.
+func (d *Dispatcher) Collect(ch chan<- prometheus.Metric) {
+	records, err := d.config.call(d.caller, "dispatcher.list")
+
+	if err == nil {
+		err = d.collect(ch, records)
+	}
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(d.failure, err)
+	}
+}
+
+func (d *Dispatcher) collect(ch chan<- prometheus.Metric, records []binrpc.Record) error {
+	sets, err := binrpc.ParseDispatcherList(records)
+
+	if err != nil {
+		return err
+	}
+
+	ch <- prometheus.MustNewConstMetric(d.sets, prometheus.GaugeValue, float64(len(sets)))
+
+	for _, set := range sets {
+		id := strconv.Itoa(set.ID)
+
+		for _, destination := range set.Destinations {
+			d.collectDestination(ch, id, destination)
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) collectDestination(ch chan<- prometheus.Metric, set string, dest binrpc.DispatcherDestination) {
+	uri := dest.URI
+
+	ch <- prometheus.MustNewConstMetric(d.active, prometheus.GaugeValue, boolToFloat(dest.Active()), set, uri)
+	ch <- prometheus.MustNewConstMetric(d.probing, prometheus.GaugeValue, boolToFloat(dest.Probing()), set, uri)
+	ch <- prometheus.MustNewConstMetric(d.flags, prometheus.GaugeValue, 1, set, uri, dest.Flags)
+	ch <- prometheus.MustNewConstMetric(d.priority, prometheus.GaugeValue, float64(dest.Priority), set, uri)
+
+	latency := dest.Latency
+
+	if latency == nil {
+		return
+	}
+
+	// latencies are in milliseconds
+	for key, value := range map[string]float64{
+		"AVG": latency.Average,
+		"STD": latency.StdDev,
+		"EST": latency.Estimated,
+		"MAX": latency.Max,
+	} {
+		ch <- prometheus.MustNewConstMetric(d.latency[key], prometheus.GaugeValue, value/1000, set, uri)
+	}
+
+	ch <- prometheus.MustNewConstMetric(d.timeouts, prometheus.CounterValue, float64(latency.Timeouts), set, uri)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// arrayRecord returns an array of single-item structs named key, as decoded from Kamailio replies.
+func arrayRecord(key string, values ...binrpc.Record) binrpc.Record {
+	var elements []binrpc.Record
+
+	for _, value := range values {
+		elements = append(elements, structRecord(key, value))
+	}
+
+	return binrpc.Record{Type: binrpc.TypeArray, Value: elements}
+}
+
+func TestDispatcher(t *testing.T) {
+	destination := structRecord(
+		"URI", "sip:10.0.0.1:5060",
+		"FLAGS", "AP",
+		"PRIORITY", 5,
+		"LATENCY", structRecord("AVG", 20.5, "STD", 1.0, "EST", 21.0, "MAX", 40, "TIMEOUT", 2),
+	)
+	inactive := structRecord("URI", "sip:10.0.0.2:5060", "FLAGS", "IP", "PRIORITY", 0)
+
+	caller := fakeCaller{
+		"dispatcher.list": structReply(
+			"NRSETS", 1,
+			"RECORDS", arrayRecord("SET", structRecord("ID", 1, "TARGETS", arrayRecord("DEST", destination, inactive))),
+		),
+	}
+
+	expected := `
+# HELP kamailio_dispatcher_destination_active Whether the destination is active (1) or not (0).
+# TYPE kamailio_dispatcher_destination_active gauge
+kamailio_dispatcher_destination_active{destination="sip:10.0.0.1:5060",set="1"} 1
+kamailio_dispatcher_destination_active{destination="sip:10.0.0.2:5060",set="1"} 0
+# HELP kamailio_dispatcher_destination_latency_average_seconds Average latency of the destination.
+# TYPE kamailio_dispatcher_destination_latency_average_seconds gauge
+kamailio_dispatcher_destination_latency_average_seconds{destination="sip:10.0.0.1:5060",set="1"} 0.0205
+# HELP kamailio_dispatcher_destination_latency_timeouts_total Total number of timeouts while measuring the latency of the destination.
+# TYPE kamailio_dispatcher_destination_latency_timeouts_total counter
+kamailio_dispatcher_destination_latency_timeouts_total{destination="sip:10.0.0.1:5060",set="1"} 2
+# HELP kamailio_dispatcher_destination_priority Priority of the destination.
+# TYPE kamailio_dispatcher_destination_priority gauge
+kamailio_dispatcher_destination_priority{destination="sip:10.0.0.1:5060",set="1"} 5
+kamailio_dispatcher_destination_priority{destination="sip:10.0.0.2:5060",set="1"} 0
+# HELP kamailio_dispatcher_sets Number of destination sets.
+# TYPE kamailio_dispatcher_sets gauge
+kamailio_dispatcher_sets 1
+`
+
+	err := testutil.CollectAndCompare(NewDispatcher(caller), strings.NewReader(expected),
+		"kamailio_dispatcher_destination_active",
+		"kamailio_dispatcher_destination_latency_average_seconds",
+		"kamailio_dispatcher_destination_latency_timeouts_total",
+		"kamailio_dispatcher_destination_priority",
+		"kamailio_dispatcher_sets",
+	)
+
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// slCodes are the keys of the reply of sl.stats. Codes without their own key are counted by class ("2xx" counts the
+// 2xx replies other than 200 and 202), and "xxx" counts the replies with a code of 700 or more.
+var slCodes = []string{
+	"200", "202", "2xx",
+	"300", "301", "302", "3xx",
+	"400", "401", "403", "404", "407", "408", "483", "4xx",
+	"500", "5xx",
+	"6xx",
+	"xxx",
+}
+
+// SLStats collects the statistics of the replies sent statelessly, returned by sl.stats.
+type SLStats struct {
+	caller  Caller
+	config  config
+	metrics map[string]structMetric
+	failure *prometheus.Desc
+}
+
+// NewSLStats returns a collector of sl.stats. The sl module must be loaded.
+func NewSLStats(caller Caller, options ...Option) *SLStats {
+	c := newConfig(options)
+
+	replies := c.desc("sl", "replies_total", "Total number of replies sent statelessly, by code. Codes such as 2xx count the codes of the class not counted separately.", "code")
+	metrics := make(map[string]structMetric, len(slCodes))
+
+	for _, code := range slCodes {
+		metrics[code] = structMetric{replies, prometheus.CounterValue, []string{code}}
+	}
+
+	return &SLStats{
+		caller:  caller,
+		config:  c,
+		metrics: metrics,
+		failure: c.desc("sl", "stats", "Failure of sl.stats."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *SLStats) Describe(ch chan<- *prometheus.Desc) {
+	describeStruct(ch, s.metrics)
+}
+
+// Collect implements prometheus.Collector.
+func (s *SLStats) Collect(ch chan<- prometheus.Metric) {
+	records, err := s.config.call(s.caller, "sl.stats")
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(s.failure, err)
+		return
+	}
+
+	items, err := structItems(records)
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(s.failure, err)
+		return
+	}
+
+	collectStruct(ch, items, s.metrics)
+}
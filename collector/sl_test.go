@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSLStats(t *testing.T) {
+	caller := fakeCaller{
+		"sl.stats": structReply("200", 10, "2xx", 1, "404", 3, "4xx", 2, "xxx", 0, "unknown", 5),
+	}
+
+	expected := `
+# HELP kamailio_sl_replies_total Total number of replies sent statelessly, by code. Codes such as 2xx count the codes of the class not counted separately.
+# TYPE kamailio_sl_replies_total counter
+kamailio_sl_replies_total{code="200"} 10
+kamailio_sl_replies_total{code="2xx"} 1
+kamailio_sl_replies_total{code="404"} 3
+kamailio_sl_replies_total{code="4xx"} 2
+kamailio_sl_replies_total{code="xxx"} 0
+`
+
+	err := testutil.CollectAndCompare(NewSLStats(caller), strings.NewReader(expected))
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSLStatsFailure(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewSLStats(fakeCaller{}))
+
+	if _, err := registry.Gather(); err == nil {
+		t.Error("error must be returned")
+	}
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TMStats collects the transaction statistics returned by tm.stats.
+type TMStats struct {
+	caller  Caller
+	config  config
+	metrics map[string]structMetric
+	failure *prometheus.Desc
+}
+
+// NewTMStats returns a collector of tm.stats. The tm module must be loaded.
+func NewTMStats(caller Caller, options ...Option) *TMStats {
+	c := newConfig(options)
+
+	replies := c.desc("tm", "replies_total", "Total number of transactions completed, by class of final reply.", "class")
+
+	return &TMStats{
+		caller: caller,
+		config: c,
+		metrics: map[string]structMetric{
+			"current":       {c.desc("tm", "transactions_current", "Number of transactions in memory."), prometheus.GaugeValue, nil},
+			"waiting":       {c.desc("tm", "transactions_waiting", "Number of transactions waiting to be deleted."), prometheus.GaugeValue, nil},
+			"total":         {c.desc("tm", "transactions_total", "Total number of transactions."), prometheus.CounterValue, nil},
+			"total_local":   {c.desc("tm", "local_transactions_total", "Total number of transactions created locally."), prometheus.CounterValue, nil},
+			"rpl_received":  {c.desc("tm", "replies_received_total", "Total number of replies received."), prometheus.CounterValue, nil},
+			"rpl_generated": {c.desc("tm", "replies_generated_total", "Total number of replies generated locally."), prometheus.CounterValue, nil},
+			"rpl_sent":      {c.desc("tm", "replies_sent_total", "Total number of replies sent."), prometheus.CounterValue, nil},
+			"6xx":           {replies, prometheus.CounterValue, []string{"6xx"}},
+			"5xx":           {replies, prometheus.CounterValue, []string{"5xx"}},
+			"4xx":           {replies, prometheus.CounterValue, []string{"4xx"}},
+			"3xx":           {replies, prometheus.CounterValue, []string{"3xx"}},
+			"2xx":           {replies, prometheus.CounterValue, []string{"2xx"}},
+			"created":       {c.desc("tm", "transactions_created_total", "Total number of transactions created."), prometheus.CounterValue, nil},
+			"freed":         {c.desc("tm", "transactions_freed_total", "Total number of transactions freed."), prometheus.CounterValue, nil},
+			"delayed_free":  {c.desc("tm", "transactions_delayed_free_total", "Total number of transactions whose deletion was delayed."), prometheus.CounterValue, nil},
+		},
+		failure: c.desc("tm", "stats", "Failure of tm.stats."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (t *TMStats) Describe(ch chan<- *prometheus.Desc) {
+	describeStruct(ch, t.metrics)
+}
+
+// Collect implements prometheus.Collector.
+func (t *TMStats) Collect(ch chan<- prometheus.Metric) {
+	records, err := t.config.call(t.caller, "tm.stats")
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(t.failure, err)
+		return
+	}
+
+	items, err := structItems(records)
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(t.failure, err)
+		return
+	}
+
+	collectStruct(ch, items, t.metrics)
+}
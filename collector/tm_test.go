@@ -0,0 +1,44 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTMStats(t *testing.T) {
+	caller := fakeCaller{
+		"tm.stats": structReply("current", 1, "waiting", 2, "total", 100, "total_local", 3, "2xx", 80, "4xx", 20, "unknown", 5),
+	}
+
+	expected := `
+# HELP kamailio_tm_replies_total Total number of transactions completed, by class of final reply.
+# TYPE kamailio_tm_replies_total counter
+kamailio_tm_replies_total{class="2xx"} 80
+kamailio_tm_replies_total{class="4xx"} 20
+# HELP kamailio_tm_transactions_current Number of transactions in memory.
+# TYPE kamailio_tm_transactions_current gauge
+kamailio_tm_transactions_current 1
+# HELP kamailio_tm_transactions_total Total number of transactions.
+# TYPE kamailio_tm_transactions_total counter
+kamailio_tm_transactions_total 100
+`
+
+	err := testutil.CollectAndCompare(NewTMStats(caller), strings.NewReader(expected),
+		"kamailio_tm_replies_total", "kamailio_tm_transactions_current", "kamailio_tm_transactions_total")
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTMStatsFailure(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewTMStats(fakeCaller{}))
+
+	if _, err := registry.Gather(); err == nil {
+		t.Error("error must be returned")
+	}
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"strings"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Usrloc collects the registration statistics of the usrloc module, using stats.get_statistics.
+// Per domain metrics are labeled with the name of the usrloc domain (the table, such as "location").
+type Usrloc struct {
+	caller Caller
+	config config
+
+	users              *prometheus.Desc
+	contacts           *prometheus.Desc
+	registeredUsers    *prometheus.Desc
+	expired            *prometheus.Desc
+	registeredContacts *prometheus.Desc
+	failure            *prometheus.Desc
+}
+
+// NewUsrloc returns a collector of the usrloc statistics. The usrloc module must be loaded.
+func NewUsrloc(caller Caller, options ...Option) *Usrloc {
+	c := newConfig(options)
+
+	return &Usrloc{
+		caller:             caller,
+		config:             c,
+		registeredUsers:    c.desc("usrloc", "registered_users", "Number of registered AoRs."),
+		contacts:           c.desc("usrloc", "contacts", "Number of registered contacts, by domain.", "domain"),
+		users:              c.desc("usrloc", "users", "Number of registered AoRs, by domain.", "domain"),
+		expired:            c.desc("usrloc", "expired_contacts_total", "Total number of expired contacts, by domain.", "domain"),
+		registeredContacts: c.desc("usrloc", "registered_contacts", "Number of registered contacts."),
+		failure:            c.desc("usrloc", "stats", "Failure of stats.get_statistics usrloc:."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (u *Usrloc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- u.registeredUsers
+	ch <- u.registeredContacts
+	ch <- u.users
+	ch <- u.contacts
+	ch <- u.expired
+}
+
+// Collect implements prometheus.Collector.
+func (u *Usrloc) Collect(ch chan<- prometheus.Metric) {
+	records, err := u.config.call(u.caller, "stats.get_statistics", "usrloc:")
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(u.failure, err)
+		return
+	}
+
+	statistics, err := binrpc.ParseStatistics(records)
+
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(u.failure, err)
+		return
+	}
+
+	var contacts float64
+
+	for key, n := range statistics {
+		name, found := strings.CutPrefix(key, "usrloc.")
+
+		if !found {
+			continue
+		}
+
+		value := float64(n)
+
+		if name == "registered_users" {
+			ch <- prometheus.MustNewConstMetric(u.registeredUsers, prometheus.GaugeValue, value)
+			continue
+		}
+
+		// per domain statistics are named "<domain>-users", "<domain>-contacts" and "<domain>-expires"
+		index := strings.LastIndex(name, "-")
+
+		if index < 0 {
+			continue
+		}
+
+		domain := name[:index]
+
+		switch name[index+1:] {
+		case "users":
+			ch <- prometheus.MustNewConstMetric(u.users, prometheus.GaugeValue, value, domain)
+		case "contacts":
+			contacts += value
+			ch <- prometheus.MustNewConstMetric(u.contacts, prometheus.GaugeValue, value, domain)
+		case "expires":
+			ch <- prometheus.MustNewConstMetric(u.expired, prometheus.CounterValue, value, domain)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(u.registeredContacts, prometheus.GaugeValue, contacts)
+}
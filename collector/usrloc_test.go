@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUsrloc(t *testing.T) {
+	var reply []binrpc.Record
+
+	for _, line := range []string{
+		"usrloc:registered_users = 3",
+		"usrloc:location-users = 2",
+		"usrloc:location-contacts = 4",
+		"usrloc:location-expires = 10",
+		"usrloc:location_ws-users = 1",
+		"usrloc:location_ws-contacts = 1",
+		"tm:ignored = 1",
+	} {
+		reply = append(reply, record(line))
+	}
+
+	caller := fakeCaller{
+		"stats.get_statistics": reply,
+	}
+
+	expected := `
+# HELP kamailio_usrloc_contacts Number of registered contacts, by domain.
+# TYPE kamailio_usrloc_contacts gauge
+kamailio_usrloc_contacts{domain="location"} 4
+kamailio_usrloc_contacts{domain="location_ws"} 1
+# HELP kamailio_usrloc_expired_contacts_total Total number of expired contacts, by domain.
+# TYPE kamailio_usrloc_expired_contacts_total counter
+kamailio_usrloc_expired_contacts_total{domain="location"} 10
+# HELP kamailio_usrloc_registered_contacts Number of registered contacts.
+# TYPE kamailio_usrloc_registered_contacts gauge
+kamailio_usrloc_registered_contacts 5
+# HELP kamailio_usrloc_registered_users Number of registered AoRs.
+# TYPE kamailio_usrloc_registered_users gauge
+kamailio_usrloc_registered_users 3
+# HELP kamailio_usrloc_users Number of registered AoRs, by domain.
+# TYPE kamailio_usrloc_users gauge
+kamailio_usrloc_users{domain="location"} 2
+kamailio_usrloc_users{domain="location_ws"} 1
+`
+
+	if err := testutil.CollectAndCompare(NewUsrloc(caller), strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,207 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultCheckInterval is the idle duration after which a connection of a Pool is checked before being used.
+const defaultCheckInterval = 30 * time.Second
+
+// Pool maintains persistent connections to the ctl module, and hands them out for calls. Unlike a single Client, which
+// serializes its calls, a Pool of size N runs up to N calls concurrently, and callers such as exporters scraping
+// periodically reuse the connections instead of dialing a new one each time.
+//
+// A connection idle for longer than the check interval (30 seconds by default, see SetCheckInterval) is checked with
+// core.version before being used. A connection failing the check, or a call with a connection error, is closed and
+// replaced by a new one when it is needed.
+//
+// A Pool is safe for concurrent use.
+type Pool struct {
+	dial func(ctx context.Context) (*Client, error)
+
+	// clients holds the idle clients, and nil for the slots whose client was evicted, to be dialed again
+	clients       chan *pooledClient
+	checkInterval time.Duration
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// pooledClient is a Client of a Pool.
+type pooledClient struct {
+	*Client
+
+	// used is the time of the last call that proved the connection to be working
+	used time.Time
+}
+
+// NewPool dials size connections to the ctl module listening on address, and returns a Pool using them. See Dial for
+// network, address and options, which apply to every connection.
+func NewPool(network, address string, size int, options ...Option) (*Pool, error) {
+	if size < 1 {
+		return nil, errors.New("invalid pool size")
+	}
+
+	p := &Pool{
+		dial: func(ctx context.Context) (*Client, error) {
+			return dialWith(ctx, &net.Dialer{}, network, address, options)
+		},
+		clients:       make(chan *pooledClient, size),
+		checkInterval: defaultCheckInterval,
+	}
+
+	for i := 0; i < size; i++ {
+		client, err := p.dial(context.Background())
+
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+
+		p.clients <- &pooledClient{Client: client, used: time.Now()}
+	}
+
+	return p, nil
+}
+
+// SetCheckInterval sets the idle duration after which a connection is checked before being used. Zero disables the
+// checks. It must be called before the first call.
+func (p *Pool) SetCheckInterval(interval time.Duration) {
+	p.checkInterval = interval
+}
+
+// Call is like Client.Call, using a connection of the pool.
+func (p *Pool) Call(method string, args ...any) ([]Record, error) {
+	return p.CallContext(context.Background(), method, args...)
+}
+
+// CallContext is like Client.CallContext, using a connection of the pool. It waits for a connection to be available
+// until ctx is done.
+func (p *Pool) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	client, err := p.get(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := client.CallContext(ctx, method, args...)
+
+	p.put(client, err)
+
+	return records, err
+}
+
+// Close closes the idle connections of the pool, and the connections in use once their call is done. Calls made after
+// Close return net.ErrClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	var slots int
+
+drain:
+	for {
+		select {
+		case client := <-p.clients:
+			if client != nil {
+				client.Close()
+			}
+
+			slots++
+		default:
+			break drain
+		}
+	}
+
+	// keep the slots, so that calls waiting for a connection see that the pool is closed
+	for i := 0; i < slots; i++ {
+		p.clients <- nil
+	}
+
+	return nil
+}
+
+// get returns a working client, waiting for one to be idle, checking it or dialing a new one until ctx is done.
+func (p *Pool) get(ctx context.Context) (*pooledClient, error) {
+	var client *pooledClient
+
+	select {
+	case client = <-p.clients:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if p.isClosed() {
+		p.evict(client)
+		return nil, net.ErrClosed
+	}
+
+	if client != nil && p.checkInterval > 0 && time.Since(client.used) > p.checkInterval {
+		if _, err := client.CallContext(ctx, "core.version"); err != nil && !isFault(err) {
+			// the check failed because ctx is done: the connection may be working
+			if ctx.Err() != nil {
+				p.clients <- client
+				return nil, ctx.Err()
+			}
+
+			client.Close()
+			client = nil
+		}
+	}
+
+	if client == nil {
+		c, err := p.dial(ctx)
+
+		if err != nil {
+			p.clients <- nil
+			return nil, err
+		}
+
+		client = &pooledClient{Client: c}
+	}
+
+	return client, nil
+}
+
+// put gives client back to the pool after a call that returned err. The client is evicted if err is caused by the
+// connection, or if the pool is closed.
+func (p *Pool) put(client *pooledClient, err error) {
+	if isConnError(err) || p.isClosed() {
+		p.evict(client)
+		return
+	}
+
+	if err == nil || isFault(err) {
+		client.used = time.Now()
+	}
+
+	p.clients <- client
+}
+
+// evict closes client, and frees its slot.
+func (p *Pool) evict(client *pooledClient) {
+	if client != nil {
+		client.Close()
+	}
+
+	p.clients <- nil
+}
+
+func (p *Pool) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.closed
+}
+
+// isFault reports whether err is a fault replied by Kamailio, which proves the connection to be working.
+func isFault(err error) bool {
+	var fault *RPCError
+
+	return errors.As(err, &fault)
+}
@@ -0,0 +1,142 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	address := listen(t, func(records []Record) []any {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			if current := maxInFlight.Load(); n <= current || maxInFlight.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		return echo(records)
+	})
+
+	pool, err := NewPool("tcp", address, 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if records, err := pool.Call("core.echo"); err != nil {
+				t.Error(err)
+			} else if value, _ := records[0].String(); value != "core.echo" {
+				t.Errorf(`expected "core.echo", got "%s"`, value)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := maxInFlight.Load(); n != 2 {
+		t.Errorf("expected 2 concurrent calls, got %d", n)
+	}
+}
+
+func TestPoolEvict(t *testing.T) {
+	// the server closes the connection on the first request of each method
+	address, received := flakyServer(t)
+
+	pool, err := NewPool("tcp", address, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer pool.Close()
+
+	pool.SetCheckInterval(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	// the check fails and the connection is replaced, then the call fails and the connection is evicted
+	if _, err = pool.Call("tm.stats"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	if count := received("core.version"); count != 1 {
+		t.Errorf("expected 1 check, got %d", count)
+	}
+
+	if _, err = pool.Call("tm.stats"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPoolCanceled(t *testing.T) {
+	pool, err := NewPool("tcp", listen(t, echo), 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer pool.Close()
+
+	pool.SetCheckInterval(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	client := <-pool.clients
+	pool.clients <- client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the check fails because ctx is done: the connection is kept
+	if _, err = pool.CallContext(ctx, "core.echo"); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	kept := <-pool.clients
+	pool.clients <- kept
+
+	if kept != client {
+		t.Error("expected the connection to be kept")
+	}
+
+	if _, err = pool.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	pool, err := NewPool("tcp", listen(t, echo), 2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Close()
+
+	if _, err = pool.Call("core.echo"); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected net.ErrClosed, got %v", err)
+	}
+
+	if _, err = NewPool("tcp", listen(t, echo), 0); err == nil {
+		t.Error("error must be returned")
+	}
+}
@@ -0,0 +1,56 @@
+package binrpc
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math/rand"
+	"sync/atomic"
+)
+
+// CookieSource generates the cookies matching requests and replies. A CookieSource used by a Client must be safe for
+// concurrent use.
+type CookieSource interface {
+	Cookie() uint32
+}
+
+// CookieFunc adapts a function to a CookieSource.
+type CookieFunc func() uint32
+
+// Cookie implements CookieSource.
+func (f CookieFunc) Cookie() uint32 {
+	return f()
+}
+
+// RandomCookies is the default CookieSource, using math/rand.
+var RandomCookies CookieSource = CookieFunc(rand.Uint32)
+
+// CryptoCookies generates cookies with crypto/rand, so that they cannot be predicted by other users of the ctl
+// socket.
+var CryptoCookies CookieSource = CookieFunc(func() uint32 {
+	var b [4]byte
+
+	// crypto/rand.Read never fails on supported platforms
+	crand.Read(b[:])
+
+	return binary.BigEndian.Uint32(b[:])
+})
+
+// SequentialCookies returns a CookieSource generating start, start+1, start+2... and wrapping around. It is safe for
+// concurrent use, and makes packets reproducible, for instance in tests.
+func SequentialCookies(start uint32) CookieSource {
+	var next atomic.Uint32
+
+	next.Store(start)
+
+	return CookieFunc(func() uint32 {
+		return next.Add(1) - 1
+	})
+}
+
+// WithCookieSource sets the source of the cookies of the calls, RandomCookies by default. The cookies set by
+// WithCookie take precedence.
+func WithCookieSource(source CookieSource) Option {
+	return func(c *Client) {
+		c.cookies = source
+	}
+}
@@ -0,0 +1,64 @@
+package binrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+)
+
+func TestWritePacketWithCookie(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := WritePacketWithCookie(&buffer, 0x1234, "core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// header with a 1 byte length and a 2 bytes cookie, and "core.echo" with a 1 byte size
+	expected := "a1010c1234" + "910a636f72652e6563686f00"
+
+	if packet := hex.EncodeToString(buffer.Bytes()); packet != expected {
+		t.Errorf("expected %s, got %s", expected, packet)
+	}
+
+	if err := WritePacketWithCookie[int](&buffer, 1); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestSequentialCookies(t *testing.T) {
+	source := SequentialCookies(0xfffffffe)
+
+	for _, expected := range []uint32{0xfffffffe, 0xffffffff, 0, 1} {
+		if cookie := source.Cookie(); cookie != expected {
+			t.Errorf("expected %x, got %x", expected, cookie)
+		}
+	}
+}
+
+func TestWithCookieSource(t *testing.T) {
+	var cookies []uint32
+
+	hooks := Hooks{
+		OnCallStart: func(ctx context.Context, info CallInfo) context.Context {
+			cookies = append(cookies, info.Cookie)
+			return ctx
+		},
+	}
+
+	client := serve(t, echo, WithCookieSource(SequentialCookies(100)), WithHooks(hooks))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call("core.echo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.CallContext(WithCookie(context.Background(), 7), "core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cookies) != 3 || cookies[0] != 100 || cookies[1] != 101 || cookies[2] != 7 {
+		t.Errorf("expected cookies [100 101 7], got %v", cookies)
+	}
+}
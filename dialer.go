@@ -0,0 +1,223 @@
+package binrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+)
+
+// Dialer opens connections.
+//
+// It is implemented by *net.Dialer, and by *ssh.Client of golang.org/x/crypto/ssh, which opens connections from the
+// remote host. This is useful as the ctl module often listens on a unix socket or on the loopback interface only:
+//
+//	sshClient, err := ssh.Dial("tcp", "sip1.example.com:22", sshConfig)
+//
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	client, err := binrpc.DialWith(sshClient, "unix", "/run/kamailio/kamailio_ctl")
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// DialerFunc adapts a function to a Dialer. As a Client reconnects with the same Dialer, a function opening a new SSH
+// connection when the previous one is lost lets the Client recover from the loss of the SSH connection itself:
+//
+//	var (
+//		mu        sync.Mutex
+//		sshClient *ssh.Client
+//	)
+//
+//	dial := binrpc.DialerFunc(func(network, address string) (net.Conn, error) {
+//		mu.Lock()
+//		defer mu.Unlock()
+//
+//		if sshClient != nil {
+//			if conn, err := sshClient.Dial(network, address); err == nil {
+//				return conn, nil
+//			}
+//
+//			sshClient.Close()
+//		}
+//
+//		var err error
+//
+//		if sshClient, err = ssh.Dial("tcp", "sip1.example.com:22", sshConfig); err != nil {
+//			return nil, err
+//		}
+//
+//		return sshClient.Dial(network, address)
+//	})
+//
+//	client, err := binrpc.DialWith(dial, "unix", "/run/kamailio/kamailio_ctl")
+//
+// As a dial abandoned when the dial timeout expires may still be running, the function must be safe for concurrent
+// use.
+type DialerFunc func(network, address string) (net.Conn, error)
+
+// Dial calls f(network, address).
+func (f DialerFunc) Dial(network, address string) (net.Conn, error) {
+	return f(network, address)
+}
+
+// contextDialer is implemented by dialers supporting cancellation, such as *net.Dialer.
+type contextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DialWith is like Dial, but opens the connection with dialer.
+//
+// Connections without deadline support, such as SSH channels, are relayed through a net.Pipe so that timeouts and
+// context cancellation still interrupt calls. Closing the Client closes the connection, but not the dialer.
+//
+// On the unixgram network, a temporary reply socket is bound unless dialer is a *net.Dialer with a LocalAddr, see
+// Dial.
+//
+// The dial timeout (see WithTimeouts) is enforced with DialContext if dialer implements it. Otherwise, the Client
+// stops waiting for Dial when the timeout expires, and closes the connection if it is opened later.
+func DialWith(dialer Dialer, network, address string, options ...Option) (*Client, error) {
+	return dialWith(context.Background(), dialer, network, address, options)
+}
+
+// dialWith is the implementation of DialWith, the first dial being aborted when ctx is done.
+func dialWith(ctx context.Context, dialer Dialer, network, address string, options []Option) (*Client, error) {
+	target := endpoint{network, address}
+
+	dial := func(ctx context.Context, timeout time.Duration) (net.Conn, endpoint, error) {
+		conn, err := dialEndpoint(ctx, dialer, target, timeout)
+
+		return conn, target, err
+	}
+
+	return dialClient(ctx, dial, target, options)
+}
+
+// dialClient returns a Client connected with dial, target being its endpoint until connected.
+func dialClient(ctx context.Context, dial dialFunc, target endpoint, options []Option) (*Client, error) {
+	c := newClient(target.network, target.address, dial, options)
+	conn, e, err := c.open(ctx, c.timeouts.Dial)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.network, c.address = e.network, e.address
+	c.connect(conn)
+
+	return c, nil
+}
+
+// endpoint is the network and the address of a connection.
+type endpoint struct {
+	network string
+	address string
+}
+
+// dialFunc opens a connection within timeout, if not zero, and returns it with its endpoint.
+type dialFunc func(ctx context.Context, timeout time.Duration) (net.Conn, endpoint, error)
+
+// dialEndpoint opens a connection to e with dialer within timeout, if not zero.
+func dialEndpoint(ctx context.Context, dialer Dialer, e endpoint, timeout time.Duration) (net.Conn, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := dialContext(ctx, dialer, e.network, e.address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if conn.SetDeadline(time.Time{}) != nil {
+		conn = newPipeConn(conn)
+	}
+
+	return conn, nil
+}
+
+// dialContext opens a connection with dialer, and returns the error of ctx if it is done first.
+func dialContext(ctx context.Context, dialer Dialer, network, address string) (net.Conn, error) {
+	if dialer, ok := dialer.(*net.Dialer); ok && network == "unixgram" && dialer.LocalAddr == nil {
+		return dialUnixgram(ctx, dialer, address)
+	}
+
+	if dialer, ok := dialer.(contextDialer); ok {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	if ctx.Done() == nil {
+		return dialer.Dial(network, address)
+	}
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		conn, err := dialer.Dial(network, address)
+		done <- result{conn, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.conn, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.conn != nil {
+				r.conn.Close()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// pipeConn relays a connection without deadline support through a net.Pipe, which supports them.
+// Data read by the relay but not by the Client stays in the pipe, so a timeout does not lose a reply.
+type pipeConn struct {
+	net.Conn
+
+	remote net.Conn
+}
+
+func newPipeConn(remote net.Conn) *pipeConn {
+	local, relay := net.Pipe()
+
+	go func() {
+		io.Copy(relay, remote)
+		relay.Close()
+	}()
+
+	go func() {
+		io.Copy(remote, relay)
+	}()
+
+	return &pipeConn{
+		Conn:   local,
+		remote: remote,
+	}
+}
+
+// Close closes both ends of the relay.
+func (c *pipeConn) Close() error {
+	c.Conn.Close()
+
+	return c.remote.Close()
+}
+
+func (c *pipeConn) LocalAddr() net.Addr {
+	return c.remote.LocalAddr()
+}
+
+func (c *pipeConn) RemoteAddr() net.Addr {
+	return c.remote.RemoteAddr()
+}
@@ -0,0 +1,112 @@
+package binrpc
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// noDeadlineConn is a connection without deadline support, like an SSH channel.
+type noDeadlineConn struct {
+	net.Conn
+}
+
+func (noDeadlineConn) SetDeadline(time.Time) error {
+	return errors.New("deadline not supported")
+}
+
+func (noDeadlineConn) SetReadDeadline(time.Time) error {
+	return errors.New("deadline not supported")
+}
+
+func (noDeadlineConn) SetWriteDeadline(time.Time) error {
+	return errors.New("deadline not supported")
+}
+
+// noDeadlineDialer opens connections without deadline support.
+type noDeadlineDialer struct{}
+
+func (noDeadlineDialer) Dial(network, address string) (net.Conn, error) {
+	conn, err := net.Dial(network, address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return noDeadlineConn{conn}, nil
+}
+
+func TestDialWith(t *testing.T) {
+	client, err := DialWith(&net.Dialer{}, "tcp", listen(t, echo))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, ok := client.conn.(*pipeConn); ok {
+		t.Error("connections supporting deadlines must not be relayed")
+	}
+
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDialWithNoDeadline(t *testing.T) {
+	client, err := DialWith(noDeadlineDialer{}, "tcp", listen(t, echo), WithTimeout(100*time.Millisecond))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Call("slow", 150); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	records, err := client.Call("fast")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "fast" {
+		t.Errorf(`expected "fast", got "%s"`, value)
+	}
+}
+
+func TestDialerFunc(t *testing.T) {
+	address := listen(t, echo)
+	dials := 0
+
+	dialer := DialerFunc(func(network, addr string) (net.Conn, error) {
+		dials++
+
+		// the address dialed is the one given to DialWith, like the socket path on the remote host with SSH
+		if addr != "ctl" {
+			t.Errorf(`expected "ctl", got "%s"`, addr)
+		}
+
+		return net.Dial("tcp", address)
+	})
+
+	client, err := DialWith(dialer, "unix", "ctl")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+
+	if dials != 1 {
+		t.Errorf("expected 1 dial, got %d", dials)
+	}
+}
@@ -0,0 +1,96 @@
+package binrpc
+
+import (
+	"context"
+)
+
+// States of a Dialog.
+const (
+	DialogUnconfirmed = 1
+	DialogEarly       = 2
+	DialogConfirmedNA = 3
+	DialogConfirmed   = 4
+	DialogDeleted     = 5
+)
+
+// Dialog is a dialog of the dialog module, as returned by dlg.list.
+type Dialog struct {
+	HashEntry int    `binrpc:"h_entry"`
+	HashID    int    `binrpc:"h_id"`
+	CallID    string `binrpc:"call-id"`
+	FromURI   string `binrpc:"from_uri"`
+	ToURI     string `binrpc:"to_uri"`
+
+	// State is one of DialogUnconfirmed, DialogEarly, DialogConfirmedNA (answered but not acknowledged yet),
+	// DialogConfirmed and DialogDeleted.
+	State int `binrpc:"state"`
+
+	// StartTime, InitTime and EndTime are Unix times. StartTime and EndTime are zero until the dialog is confirmed
+	// and ended.
+	StartTime int `binrpc:"start_ts"`
+	InitTime  int `binrpc:"init_ts"`
+	EndTime   int `binrpc:"end_ts"`
+
+	// Timeout is the Unix time at which the dialog expires, and Lifetime its duration in seconds.
+	Timeout  int `binrpc:"timeout"`
+	Lifetime int `binrpc:"lifetime"`
+
+	DFlags int `binrpc:"dflags"`
+	SFlags int `binrpc:"sflags"`
+	IFlags int `binrpc:"iflags"`
+
+	Caller DialogPeer `binrpc:"caller"`
+	Callee DialogPeer `binrpc:"callee"`
+}
+
+// DialogPeer is the caller or the callee side of a Dialog.
+type DialogPeer struct {
+	Tag      string `binrpc:"tag"`
+	Contact  string `binrpc:"contact"`
+	CSeq     string `binrpc:"cseq"`
+	RouteSet string `binrpc:"route_set"`
+	Socket   string `binrpc:"socket"`
+}
+
+// DialogStats is the number of active dialogs by state, as returned by dlg.stats_active.
+type DialogStats struct {
+	Starting   int `binrpc:"starting"`
+	Connecting int `binrpc:"connecting"`
+	Answering  int `binrpc:"answering"`
+	Ongoing    int `binrpc:"ongoing"`
+	All        int `binrpc:"all"`
+}
+
+// DlgList calls dlg.list with caller and returns the dialogs.
+func DlgList(ctx context.Context, caller Caller) ([]Dialog, error) {
+	records, err := caller.CallContext(ctx, "dlg.list")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dialogs []Dialog
+
+	if err = Unmarshal(records, &dialogs); err != nil {
+		return nil, err
+	}
+
+	return dialogs, nil
+}
+
+// DlgStatsActive calls dlg.stats_active with caller and returns the number of active dialogs by state.
+func DlgStatsActive(ctx context.Context, caller Caller) (*DialogStats, error) {
+	records, err := caller.CallContext(ctx, "dlg.stats_active")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stats DialogStats
+
+	if err = Unmarshal(records, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,105 @@
+package binrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestDlgList(t *testing.T) {
+	dialog := []StructItem{
+		{Key: "h_entry", Value: mustRecord(t, 12)},
+		{Key: "h_id", Value: mustRecord(t, 345)},
+		{Key: "ref", Value: mustRecord(t, 2)},
+		{Key: "call-id", Value: mustRecord(t, "a84b4c76e66710")},
+		{Key: "from_uri", Value: mustRecord(t, "sip:alice@example.com")},
+		{Key: "to_uri", Value: mustRecord(t, "sip:bob@example.com")},
+		{Key: "state", Value: mustRecord(t, DialogConfirmed)},
+		{Key: "start_ts", Value: mustRecord(t, 1700000010)},
+		{Key: "init_ts", Value: mustRecord(t, 1700000000)},
+		{Key: "timeout", Value: mustRecord(t, 1700043210)},
+		{Key: "lifetime", Value: mustRecord(t, 43200)},
+		{Key: "caller", Value: mustRecord(t, []StructItem{
+			{Key: "tag", Value: mustRecord(t, "1928301774")},
+			{Key: "contact", Value: mustRecord(t, "sip:alice@10.0.0.1:5060")},
+			{Key: "cseq", Value: mustRecord(t, "314159")},
+			{Key: "route_set", Value: mustRecord(t, "")},
+			{Key: "socket", Value: mustRecord(t, "udp:10.0.0.2:5060")},
+		})},
+		{Key: "callee", Value: mustRecord(t, []StructItem{
+			{Key: "tag", Value: mustRecord(t, "a6c85cf")},
+		})},
+		{Key: "profiles", Value: mustRecord(t, []any{})},
+	}
+
+	server := NewServer()
+	defer server.Close()
+
+	server.Handle("dlg.list", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{dialog, dialog}, nil
+	})
+
+	server.Handle("dlg.stats_active", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{map[string]any{"starting": 1, "connecting": 2, "answering": 0, "ongoing": 5, "all": 8}}, nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	dialogs, err := DlgList(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dialogs) != 2 {
+		t.Fatalf("expected 2 dialogs, got %d", len(dialogs))
+	}
+
+	expected := Dialog{
+		HashEntry: 12,
+		HashID:    345,
+		CallID:    "a84b4c76e66710",
+		FromURI:   "sip:alice@example.com",
+		ToURI:     "sip:bob@example.com",
+		State:     DialogConfirmed,
+		StartTime: 1700000010,
+		InitTime:  1700000000,
+		Timeout:   1700043210,
+		Lifetime:  43200,
+		Caller: DialogPeer{
+			Tag:     "1928301774",
+			Contact: "sip:alice@10.0.0.1:5060",
+			CSeq:    "314159",
+			Socket:  "udp:10.0.0.2:5060",
+		},
+		Callee: DialogPeer{Tag: "a6c85cf"},
+	}
+
+	if dialogs[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, dialogs[0])
+	}
+
+	stats, err := DlgStatsActive(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (DialogStats{Starting: 1, Connecting: 2, Ongoing: 5, All: 8}); *stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, *stats)
+	}
+}
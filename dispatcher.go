@@ -0,0 +1,270 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// States of a dispatcher destination, for Dispatcher.SetState. DispatcherProbing can be combined with the other
+// states, such as DispatcherActive + DispatcherProbing.
+const (
+	DispatcherActive   = "a"
+	DispatcherInactive = "i"
+	DispatcherDisabled = "d"
+	DispatcherTrying   = "t"
+	DispatcherProbing  = "p"
+)
+
+// DispatcherSet is a set of destinations of the dispatcher module, as returned by dispatcher.list.
+type DispatcherSet struct {
+	ID           int
+	Destinations []DispatcherDestination
+}
+
+// DispatcherDestination is a destination of a DispatcherSet.
+type DispatcherDestination struct {
+	URI string
+
+	// Flags is the state of the destination, such as "AP" for active and probing: A (active), I (inactive),
+	// D (disabled) or T (trying), followed by P if the destination is probed, or X if it is not.
+	Flags string
+
+	Priority int
+
+	// Attrs is the attributes of the destination, such as "weight=50;socket=udp:10.0.0.1:5060".
+	Attrs string
+
+	// Latency is nil unless the latency of the destination is measured, with the ds_ping_latency_stats parameter.
+	Latency *DispatcherLatency
+}
+
+// DispatcherLatency is the latency of the replies of a destination to the probing requests.
+type DispatcherLatency struct {
+	// Average, StdDev, Estimated and Max are in milliseconds.
+	Average   float64 `binrpc:"AVG"`
+	StdDev    float64 `binrpc:"STD"`
+	Estimated float64 `binrpc:"EST"`
+	Max       float64 `binrpc:"MAX"`
+
+	// Timeouts is the number of probing requests without reply.
+	Timeouts int `binrpc:"TIMEOUT"`
+}
+
+// Active reports whether the destination is active.
+func (destination DispatcherDestination) Active() bool {
+	return strings.HasPrefix(destination.Flags, "A")
+}
+
+// Probing reports whether the destination is probed.
+func (destination DispatcherDestination) Probing() bool {
+	return strings.Contains(destination.Flags, "P")
+}
+
+// Dispatcher manages the destinations of the dispatcher module:
+//
+//	ds := binrpc.NewDispatcher(client)
+//
+//	err := ds.SetState(ctx, binrpc.DispatcherInactive, 1, "sip:10.0.0.1:5060")
+//
+// The changes made by Add and Remove are lost on the next reload of the dispatcher lists, from their file or
+// database.
+type Dispatcher struct {
+	caller Caller
+}
+
+// NewDispatcher returns a Dispatcher calling the dispatcher commands with caller.
+func NewDispatcher(caller Caller) *Dispatcher {
+	return &Dispatcher{caller: caller}
+}
+
+// List calls dispatcher.list and returns the destination sets.
+func (ds *Dispatcher) List(ctx context.Context) ([]DispatcherSet, error) {
+	records, err := ds.caller.CallContext(ctx, "dispatcher.list")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDispatcherList(records)
+}
+
+// Add calls dispatcher.add to add uri to set, with flags, such as 8 to probe the destination, or 1 to add it inactive.
+func (ds *Dispatcher) Add(ctx context.Context, set int, uri string, flags int) error {
+	return ds.call(ctx, "dispatcher.add", set, uri, flags)
+}
+
+// Remove calls dispatcher.remove to remove uri from set.
+func (ds *Dispatcher) Remove(ctx context.Context, set int, uri string) error {
+	return ds.call(ctx, "dispatcher.remove", set, uri)
+}
+
+// SetState calls dispatcher.set_state to set the state of uri in set, such as DispatcherInactive to stop routing
+// to a destination under maintenance.
+func (ds *Dispatcher) SetState(ctx context.Context, state string, set int, uri string) error {
+	return ds.call(ctx, "dispatcher.set_state", state, set, uri)
+}
+
+// Reload calls dispatcher.reload to reload the destinations from their file or database.
+func (ds *Dispatcher) Reload(ctx context.Context) error {
+	return ds.call(ctx, "dispatcher.reload")
+}
+
+// call calls method with args, and checks that the reply is empty or "ok". Faults are returned as *RPCError.
+func (ds *Dispatcher) call(ctx context.Context, method string, args ...any) error {
+	records, err := ds.caller.CallContext(ctx, method, args...)
+
+	if err != nil {
+		return err
+	}
+
+	return checkOK(method, records)
+}
+
+// checkOK checks that the reply of method is empty, or strings of okReplies, such as "ok" or "Reload OK", as replied
+// by the commands changing the state of Kamailio on success. The case is ignored.
+func checkOK(method string, records []Record) error {
+	for _, record := range records {
+		s, err := record.String()
+
+		if err != nil || !okReplies[strings.ToLower(s)] {
+			return fmt.Errorf("%s: unexpected reply: %v", method, record.Value)
+		}
+	}
+
+	return nil
+}
+
+// okReplies are the replies of Kamailio on success, in lower case.
+var okReplies = map[string]bool{
+	"ok":                              true,
+	"reload ok":                       true,
+	"ok. tls configuration reloaded.": true,
+}
+
+// ParseDispatcherList decodes the reply of dispatcher.list, a struct like {NRSETS: 1, RECORDS: [{SET: {ID: 1,
+// TARGETS: [{DEST: {URI: ..., FLAGS: ...}}]}}]}.
+func ParseDispatcherList(records []Record) ([]DispatcherSet, error) {
+	if len(records) == 0 {
+		return nil, errors.New("empty reply")
+	}
+
+	var sets []DispatcherSet
+
+	list, ok := records[0].Get("RECORDS")
+
+	// without destinations, RECORDS is missing
+	if !ok {
+		return sets, nil
+	}
+
+	elements, err := list.Array()
+
+	if err != nil {
+		return nil, fmt.Errorf("RECORDS: %w", err)
+	}
+
+	for i := range elements {
+		for _, record := range elements[i].GetAll("SET") {
+			set, err := parseDispatcherSet(record)
+
+			if err != nil {
+				return nil, err
+			}
+
+			sets = append(sets, set)
+		}
+	}
+
+	return sets, nil
+}
+
+// parseDispatcherSet returns the set of record, a struct.
+func parseDispatcherSet(record Record) (DispatcherSet, error) {
+	var set DispatcherSet
+
+	id, ok := record.Get("ID")
+
+	if !ok {
+		return set, errors.New("set without ID")
+	}
+
+	if err := id.Scan(&set.ID); err != nil {
+		return set, fmt.Errorf("ID: %w", err)
+	}
+
+	targets, ok := record.Get("TARGETS")
+
+	if !ok {
+		return set, nil
+	}
+
+	elements, err := targets.Array()
+
+	if err != nil {
+		return set, fmt.Errorf("set %d: TARGETS: %w", set.ID, err)
+	}
+
+	for i := range elements {
+		for _, dest := range elements[i].GetAll("DEST") {
+			destination, err := parseDispatcherDestination(dest)
+
+			if err != nil {
+				return set, fmt.Errorf("set %d: %w", set.ID, err)
+			}
+
+			set.Destinations = append(set.Destinations, destination)
+		}
+	}
+
+	return set, nil
+}
+
+// parseDispatcherDestination returns the destination of record, a struct.
+func parseDispatcherDestination(record Record) (DispatcherDestination, error) {
+	var destination DispatcherDestination
+
+	uri, ok := record.Get("URI")
+
+	if !ok {
+		return destination, errors.New("destination without URI")
+	}
+
+	fields := map[string]any{
+		"URI":      &destination.URI,
+		"FLAGS":    &destination.Flags,
+		"PRIORITY": &destination.Priority,
+	}
+
+	items, _ := record.StructItems()
+
+	for _, item := range items {
+		if dest, ok := fields[item.Key]; ok {
+			if err := item.Value.Scan(dest); err != nil {
+				return destination, fmt.Errorf("%s: %s: %w", uri.Value, item.Key, err)
+			}
+		}
+	}
+
+	// ATTRS is the raw attributes, or a struct with the raw attributes in BODY
+	if attrs, ok := record.Get("ATTRS"); ok {
+		if body, ok := attrs.Get("BODY"); ok {
+			attrs = body
+		}
+
+		if err := attrs.Scan(&destination.Attrs); err != nil {
+			return destination, fmt.Errorf("%s: ATTRS: %w", uri.Value, err)
+		}
+	}
+
+	if latency, ok := record.Get("LATENCY"); ok {
+		destination.Latency = &DispatcherLatency{}
+
+		if err := latency.Scan(destination.Latency); err != nil {
+			return destination, fmt.Errorf("%s: LATENCY: %w", uri.Value, err)
+		}
+	}
+
+	return destination, nil
+}
@@ -0,0 +1,248 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDispatcher simulates the destination sets of the dispatcher module, and records the calls changing them.
+type fakeDispatcher struct {
+	mu    sync.Mutex
+	sets  []DispatcherSet
+	calls []string
+}
+
+// serveDispatcher starts a Server replying to the dispatcher commands with ds, and returns a Dispatcher calling it.
+func serveDispatcher(t *testing.T, ds *fakeDispatcher) *Dispatcher {
+	server := NewServer()
+	t.Cleanup(func() { server.Close() })
+
+	server.Handle("dispatcher.list", ds.list)
+
+	for _, method := range []string{"dispatcher.add", "dispatcher.remove", "dispatcher.set_state", "dispatcher.reload"} {
+		server.Handle(method, ds.change)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { client.Close() })
+
+	return NewDispatcher(client)
+}
+
+func (ds *fakeDispatcher) list(ctx context.Context, request *Request) ([]any, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	var sets []any
+
+	for _, set := range ds.sets {
+		var targets []any
+
+		for _, destination := range set.Destinations {
+			dest := map[string]any{
+				"URI":      destination.URI,
+				"FLAGS":    destination.Flags,
+				"PRIORITY": destination.Priority,
+				"ATTRS":    map[string]any{"BODY": destination.Attrs},
+			}
+
+			if latency := destination.Latency; latency != nil {
+				dest["LATENCY"] = map[string]any{
+					"AVG":     latency.Average,
+					"STD":     latency.StdDev,
+					"EST":     latency.Estimated,
+					"MAX":     latency.Max,
+					"TIMEOUT": latency.Timeouts,
+				}
+			}
+
+			targets = append(targets, map[string]any{"DEST": dest})
+		}
+
+		sets = append(sets, map[string]any{"SET": map[string]any{"ID": set.ID, "TARGETS": targets}})
+	}
+
+	return []any{map[string]any{"NRSETS": len(sets), "RECORDS": sets}}, nil
+}
+
+// change applies the dispatcher command of request to the sets.
+func (ds *fakeDispatcher) change(ctx context.Context, request *Request) ([]any, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	args := make([]string, len(request.Args))
+
+	for i, arg := range request.Args {
+		record := arg.(Record)
+		record.Scan(&args[i])
+	}
+
+	ds.calls = append(ds.calls, strings.Join(append([]string{request.Method}, args...), " "))
+
+	switch request.Method {
+	case "dispatcher.add":
+		destination := DispatcherDestination{URI: args[1], Flags: "AX"}
+
+		if len(args) > 2 {
+			flags, _ := strconv.Atoi(args[2])
+			destination.Flags = map[int]string{0: "A", 1: "I", 2: "T", 4: "D"}[flags&7] + "X"
+
+			if flags&8 != 0 {
+				destination.Flags = destination.Flags[:1] + "P"
+			}
+		}
+
+		if len(args) > 4 {
+			destination.Priority, _ = strconv.Atoi(args[3])
+			destination.Attrs = args[4]
+		}
+
+		set := ds.set(args[0], true)
+		set.Destinations = append(set.Destinations, destination)
+	case "dispatcher.remove":
+		set := ds.set(args[0], false)
+
+		for i := 0; set != nil && i < len(set.Destinations); i++ {
+			if set.Destinations[i].URI == args[1] {
+				set.Destinations = append(set.Destinations[:i], set.Destinations[i+1:]...)
+				return nil, nil
+			}
+		}
+
+		return nil, &RPCError{Code: 500, Message: "Removing dispatcher dst failed"}
+	case "dispatcher.set_state":
+		set := ds.set(args[1], false)
+
+		for i := 0; set != nil && i < len(set.Destinations); i++ {
+			if set.Destinations[i].URI == args[2] {
+				set.Destinations[i].Flags = strings.ToUpper(args[0])
+				return nil, nil
+			}
+		}
+
+		return nil, &RPCError{Code: 500, Message: "Destination address not found"}
+	}
+
+	return nil, nil
+}
+
+// set returns the set whose ID is id, adding it if create is true, or nil.
+func (ds *fakeDispatcher) set(id string, create bool) *DispatcherSet {
+	n, _ := strconv.Atoi(id)
+
+	for i := range ds.sets {
+		if ds.sets[i].ID == n {
+			return &ds.sets[i]
+		}
+	}
+
+	if !create {
+		return nil
+	}
+
+	ds.sets = append(ds.sets, DispatcherSet{ID: n})
+
+	return &ds.sets[len(ds.sets)-1]
+}
+
+func TestDispatcher(t *testing.T) {
+	fake := &fakeDispatcher{sets: []DispatcherSet{
+		{ID: 1, Destinations: []DispatcherDestination{
+			{URI: "sip:10.0.0.1:5060", Flags: "AP", Priority: 5, Attrs: "weight=50", Latency: &DispatcherLatency{
+				Average: 20.5, StdDev: 1.25, Estimated: 21, Max: 40, Timeouts: 2,
+			}},
+			{URI: "sip:10.0.0.2:5060", Flags: "IP"},
+		}},
+	}}
+
+	ds := serveDispatcher(t, fake)
+	ctx := context.Background()
+
+	sets, err := ds.List(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(sets, fake.sets) {
+		t.Errorf("expected %+v, got %+v", fake.sets, sets)
+	}
+
+	if !sets[0].Destinations[0].Active() || !sets[0].Destinations[0].Probing() || sets[0].Destinations[1].Active() {
+		t.Error("expected the first destination only to be active")
+	}
+
+	if err = ds.Add(ctx, 2, "sip:10.0.0.3:5060", 0); err != nil {
+		t.Error(err)
+	}
+
+	if err = ds.SetState(ctx, DispatcherActive+DispatcherProbing, 1, "sip:10.0.0.2:5060"); err != nil {
+		t.Error(err)
+	}
+
+	if err = ds.Remove(ctx, 1, "sip:10.0.0.1:5060"); err != nil {
+		t.Error(err)
+	}
+
+	if err = ds.Reload(ctx); err != nil {
+		t.Error(err)
+	}
+
+	var rpcErr *RPCError
+
+	if err = ds.Remove(ctx, 1, "sip:10.0.0.9:5060"); !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+
+	expected := []string{
+		"dispatcher.add 2 sip:10.0.0.3:5060 0",
+		"dispatcher.set_state ap 1 sip:10.0.0.2:5060",
+		"dispatcher.remove 1 sip:10.0.0.1:5060",
+		"dispatcher.reload",
+		"dispatcher.remove 1 sip:10.0.0.9:5060",
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if !reflect.DeepEqual(fake.calls, expected) {
+		t.Errorf("expected %q, got %q", expected, fake.calls)
+	}
+}
+
+func TestDispatcherUnexpectedReply(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{"error: no such set"}
+	})
+
+	if err := NewDispatcher(client).Reload(context.Background()); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestParseDispatcherListEmpty(t *testing.T) {
+	sets, err := ParseDispatcherList([]Record{mustRecord(t, map[string]any{"NRSETS": 0})})
+
+	if err != nil || len(sets) != 0 {
+		t.Errorf("expected no sets, got %v (%v)", sets, err)
+	}
+}
@@ -0,0 +1,53 @@
+package binrpc
+
+import (
+	"context"
+)
+
+// Statuses of a DMQNode.
+const (
+	DMQNodeActive    = "active"
+	DMQNodeNotActive = "not_active"
+	DMQNodeDisabled  = "disabled"
+	DMQNodeTimeout   = "timeout"
+	DMQNodePending   = "pending"
+)
+
+// DMQNode is a node of the DMQ cluster, as returned by dmq.list_nodes.
+type DMQNode struct {
+	Host       string `binrpc:"host"`
+	Port       int    `binrpc:"port"`
+	ResolvedIP string `binrpc:"resolved_ip"`
+
+	// Status is DMQNodeActive, DMQNodeNotActive, DMQNodeDisabled, DMQNodeTimeout or DMQNodePending.
+	Status string `binrpc:"status"`
+
+	// LastNotification is the Unix time of the last notification received from the node, zero if none.
+	LastNotification int `binrpc:"last_notification"`
+
+	// Local is set for the node of the Kamailio called.
+	Local bool `binrpc:"local"`
+}
+
+// Active reports whether the node is active.
+func (node DMQNode) Active() bool {
+	return node.Status == DMQNodeActive
+}
+
+// DMQListNodes calls dmq.list_nodes with caller and returns the nodes of the DMQ cluster, such as to monitor the
+// replication peers.
+func DMQListNodes(ctx context.Context, caller Caller) ([]DMQNode, error) {
+	records, err := caller.CallContext(ctx, "dmq.list_nodes")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []DMQNode
+
+	if err = Unmarshal(records, &nodes); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
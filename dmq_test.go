@@ -0,0 +1,55 @@
+package binrpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDMQListNodes(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{
+			map[string]any{
+				"host":              "10.0.0.1",
+				"port":              "5060",
+				"resolved_ip":       "10.0.0.1",
+				"status":            "active",
+				"last_notification": 0,
+				"local":             1,
+			},
+			map[string]any{
+				"host":              "kamailio2.example.com",
+				"port":              "5060",
+				"resolved_ip":       "10.0.0.2",
+				"status":            "timeout",
+				"last_notification": 1700000000,
+				"local":             0,
+			},
+		}
+	})
+
+	nodes, err := DMQListNodes(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []DMQNode{
+		{Host: "10.0.0.1", Port: 5060, ResolvedIP: "10.0.0.1", Status: DMQNodeActive, Local: true},
+		{
+			Host:             "kamailio2.example.com",
+			Port:             5060,
+			ResolvedIP:       "10.0.0.2",
+			Status:           DMQNodeTimeout,
+			LastNotification: 1700000000,
+		},
+	}
+
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, nodes)
+	}
+
+	if !nodes[0].Active() || nodes[1].Active() {
+		t.Error("expected the first node only to be active")
+	}
+}
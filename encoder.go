@@ -0,0 +1,86 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Encoder writes packets to a stream. It keeps its buffer from one packet to the next, so that writing many packets,
+// such as the requests of a poller, does not allocate it each time. An Encoder is not safe for concurrent use.
+type Encoder struct {
+	w      io.Writer
+	buffer []byte
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes p to the stream, in a single Write, so that each packet is a single datagram on a packet connection.
+// The packet has the cookie and the flags of p.Header, as with Packet.MarshalBinary.
+func (e *Encoder) Encode(p *Packet) error {
+	buffer, err := appendPacket(e.buffer[:0], p.Header.flags, p.Header.Cookie, p.Records)
+
+	if err != nil {
+		return err
+	}
+
+	e.buffer = buffer
+
+	if _, err = e.w.Write(buffer); err != nil {
+		return fmt.Errorf("cannot write packet: %w", err)
+	}
+
+	return nil
+}
+
+// Decoder reads packets from a stream. It keeps its buffers from one packet to the next, so that reading many packets
+// only allocates their records. A Decoder is not safe for concurrent use.
+type Decoder struct {
+	r       *bufio.Reader
+	payload bytes.Buffer
+	state   decodeState
+}
+
+// NewDecoder returns a Decoder reading from r. As the Decoder buffers r, it may read beyond the packets decoded: use
+// a *bufio.Reader to read from r afterwards.
+func NewDecoder(r io.Reader) *Decoder {
+	reader, ok := r.(*bufio.Reader)
+
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+
+	return &Decoder{r: reader}
+}
+
+// SetOptions sets the options of the packets decoded next.
+func (d *Decoder) SetOptions(options ReaderOptions) {
+	d.state.options = options
+}
+
+// Decode reads the next packet from the stream, whatever its cookie. At the end of the stream, the error wraps io.EOF.
+// Faults are not returned as errors: see Header.Fault.
+func (d *Decoder) Decode() (*Packet, error) {
+	d.payload.Reset()
+
+	header, err := readPayloadTo(d.r, &d.payload, d.state.options.MaxPayload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := d.state.decodePayload(header, d.payload.Bytes())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Packet{
+		Header:  *header,
+		Records: records,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package binrpc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncoderDecoder(t *testing.T) {
+	var stream bytes.Buffer
+
+	encoder := NewEncoder(&stream)
+
+	packets := []Packet{
+		{Header: Header{Cookie: 1}, Records: []Record{mustRecord(t, "core.echo"), mustRecord(t, []byte{1, 2, 3})}},
+		{Header: Header{Cookie: 2}, Records: []Record{mustRecord(t, []byte{4, 5, 6}), mustRecord(t, 1.5)}},
+	}
+
+	for i := range packets {
+		if err := encoder.Encode(&packets[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the encoder writes the same bytes as MarshalBinary
+	expected, _ := packets[0].MarshalBinary()
+
+	if !bytes.HasPrefix(stream.Bytes(), expected) {
+		t.Errorf("expected %x, got %x", expected, stream.Bytes()[:len(expected)])
+	}
+
+	decoder := NewDecoder(&stream)
+
+	first, err := decoder.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := decoder.Decode()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Header.Cookie != 1 || second.Header.Cookie != 2 {
+		t.Errorf("expected cookies 1 and 2, got %d and %d", first.Header.Cookie, second.Header.Cookie)
+	}
+
+	// the bytes of the first packet must not be overwritten by the second one
+	if value, _ := first.Records[1].Bytes(); !bytes.Equal(value, []byte{1, 2, 3}) {
+		t.Errorf("expected 010203, got %x", value)
+	}
+
+	if value, _ := second.Records[1].Double(); value != 1.5 {
+		t.Errorf("expected 1.5, got %f", value)
+	}
+
+	if _, err = decoder.Decode(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoderOptions(t *testing.T) {
+	var stream bytes.Buffer
+
+	packet := Packet{Records: []Record{mustRecord(t, 1), mustRecord(t, 2)}}
+
+	if err := NewEncoder(&stream).Encode(&packet); err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := NewDecoder(&stream)
+	decoder.SetOptions(ReaderOptions{MaxRecords: 1})
+
+	if _, err := decoder.Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("expected ErrLimitExceeded, got %v", err)
+	}
+}
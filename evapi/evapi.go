@@ -0,0 +1,184 @@
+// Package evapi implements a client of the evapi module of Kamailio, the event channel complementing the RPC commands
+// sent with package binrpc.
+//
+// Kamailio listens on the address of the "bind_addr" parameter of evapi, and relays events to the connected clients
+// with evapi_relay() and evapi_async_relay(). Messages sent by a client trigger the event_route[evapi:message-received]
+// of Kamailio, with the message in $evapi(msg).
+//
+// Messages are framed as netstrings ("5:hello,"), the default of the "netstring_format" parameter of evapi:
+//
+//	conn, err := evapi.Dial("tcp", "10.0.0.1:8448")
+//
+//	for {
+//		event, err := conn.Receive()
+//
+//		if err != nil {
+//			break
+//		}
+//
+//		conn.Send([]byte(`{"ack": true}`))
+//	}
+package evapi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// DefaultMaxSize is the maximum size of the messages received, unless changed by SetMaxSize.
+const DefaultMaxSize = 1 << 20
+
+// ErrMessageTooLarge is returned by Receive when a message exceeds the maximum size. The connection cannot be used
+// anymore.
+var ErrMessageTooLarge = errors.New("message too large")
+
+// Conn is a connection to the evapi module. Receive and Send can be called concurrently with each other, but
+// concurrent calls to Receive, or to Send, are serialized.
+type Conn struct {
+	conn net.Conn
+
+	readMu  sync.Mutex
+	reader  *bufio.Reader
+	maxSize int
+
+	writeMu sync.Mutex
+	buffer  []byte
+}
+
+// Dial connects to the evapi module listening on address. See net.Dial for network and address.
+func Dial(network, address string) (*Conn, error) {
+	return DialContext(context.Background(), network, address)
+}
+
+// DialContext is like Dial, the dial being aborted when ctx is done.
+func DialContext(ctx context.Context, network, address string) (*Conn, error) {
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, network, address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConn(conn), nil
+}
+
+// NewConn returns a Conn using conn. The Conn takes ownership of conn and closes it on Close.
+func NewConn(conn net.Conn) *Conn {
+	return &Conn{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		maxSize: DefaultMaxSize,
+	}
+}
+
+// SetMaxSize sets the maximum size of the messages received, DefaultMaxSize by default. Zero means no limit.
+func (c *Conn) SetMaxSize(size int) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	c.maxSize = size
+}
+
+// Receive waits for the next event relayed by Kamailio, and returns its message. It returns io.EOF when Kamailio
+// closes the connection. Deadlines can be set on the connection with NetConn.
+func (c *Conn) Receive() ([]byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	return readNetstring(c.reader, c.maxSize)
+}
+
+// Send sends msg to Kamailio, which runs the event_route[evapi:message-received] with it.
+func (c *Conn) Send(msg []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	// a single Write, so that the netstring is not split in several segments needlessly
+	c.buffer = appendNetstring(c.buffer[:0], msg)
+
+	if _, err := c.conn.Write(c.buffer); err != nil {
+		return fmt.Errorf("cannot send message: %w", err)
+	}
+
+	return nil
+}
+
+// NetConn returns the underlying connection, for instance to set deadlines.
+func (c *Conn) NetConn() net.Conn {
+	return c.conn
+}
+
+// Close closes the connection. A pending Receive returns an error.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// appendNetstring appends msg as a netstring to dst.
+func appendNetstring(dst []byte, msg []byte) []byte {
+	dst = strconv.AppendInt(dst, int64(len(msg)), 10)
+	dst = append(dst, ':')
+	dst = append(dst, msg...)
+
+	return append(dst, ',')
+}
+
+// readNetstring reads a netstring from r, and returns its data, unless its length exceeds maxSize (if not zero).
+func readNetstring(r *bufio.Reader, maxSize int) ([]byte, error) {
+	length := 0
+	digits := 0
+
+	for {
+		b, err := r.ReadByte()
+
+		if err != nil {
+			if err == io.EOF && digits > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return nil, err
+		}
+
+		if b == ':' && digits > 0 {
+			break
+		}
+
+		if b < '0' || b > '9' {
+			return nil, fmt.Errorf("invalid netstring: unexpected byte %q in length", b)
+		}
+
+		length = length*10 + int(b-'0')
+		digits++
+
+		// the length is checked as it is read, so that it cannot overflow
+		if maxSize > 0 && length > maxSize {
+			return nil, fmt.Errorf("%w: more than %d bytes", ErrMessageTooLarge, maxSize)
+		}
+
+		if length > 1<<30 {
+			return nil, errors.New("invalid netstring: length too big")
+		}
+	}
+
+	msg := make([]byte, length+1)
+
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	if msg[length] != ',' {
+		return nil, fmt.Errorf("invalid netstring: expected ',' after %d bytes, got %q", length, msg[length])
+	}
+
+	return msg[:length], nil
+}
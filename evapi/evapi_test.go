@@ -0,0 +1,114 @@
+package evapi
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+// kamailio accepts a connection, relays events, and returns the messages received.
+func kamailio(t *testing.T, events ...string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		for _, event := range events {
+			// split the events, as TCP may
+			for i := 0; i < len(event); i += 3 {
+				conn.Write([]byte(event[i:min(i+3, len(event))]))
+			}
+		}
+
+		msg, err := readNetstring(bufio.NewReader(conn), 0)
+
+		if err == nil {
+			received <- msg
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestConn(t *testing.T) {
+	address, received := kamailio(t, `17:{"event":"start"},`, "0:,")
+
+	conn, err := Dial("tcp", address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	for _, expected := range []string{`{"event":"start"}`, ""} {
+		event, err := conn.Receive()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(event) != expected {
+			t.Errorf(`expected "%s", got "%s"`, expected, event)
+		}
+	}
+
+	if err = conn.Send([]byte("hello, world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg := <-received; string(msg) != "hello, world" {
+		t.Errorf(`expected "hello, world", got "%s"`, msg)
+	}
+
+	if _, err = conn.Receive(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadNetstring(t *testing.T) {
+	tests := []struct {
+		data    string
+		maxSize int
+		err     error
+	}{
+		{"5:hello", 0, io.ErrUnexpectedEOF},
+		{"5:hello;", 0, nil},
+		{":hello,", 0, nil},
+		{"x:hello,", 0, nil},
+		{"12", 0, io.ErrUnexpectedEOF},
+		{"123456:", 1000, ErrMessageTooLarge},
+		{"99999999999999999999999:", 0, nil},
+	}
+
+	for _, test := range tests {
+		_, err := readNetstring(bufio.NewReader(strings.NewReader(test.data)), test.maxSize)
+
+		if err == nil {
+			t.Errorf("%s: error must be returned", test.data)
+		} else if test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: expected %v, got %v", test.data, test.err, err)
+		}
+	}
+
+	if data := appendNetstring(nil, []byte("hello")); string(data) != "5:hello," {
+		t.Errorf(`expected "5:hello,", got "%s"`, data)
+	}
+}
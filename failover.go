@@ -0,0 +1,123 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+)
+
+// FailoverPolicy configures a Client created by DialFailover.
+type FailoverPolicy struct {
+	// FailbackInterval is the minimum interval between two attempts to return to a preferred address, made by the
+	// calls while the Client is connected to another one. Zero disables failback: the Client stays on the address it
+	// is connected to until the connection is lost.
+	FailbackInterval time.Duration
+}
+
+// failover dials the first reachable address of a list.
+type failover struct {
+	endpoints []endpoint
+	policy    FailoverPolicy
+
+	// current is the index of the endpoint connected, and checked the time of the last attempt to fail back
+	current int
+	checked time.Time
+}
+
+// DialFailover connects to the first reachable address of addresses, in the syntax of ParseAddress, and returns a
+// Client. The addresses are in order of preference, and may mix networks, such as the ctl sockets of an HA pair of
+// Kamailio:
+//
+//	client, err := binrpc.DialFailover([]string{"tcp:10.0.0.1:2049", "tcp:10.0.0.2:2049"},
+//		binrpc.FailoverPolicy{FailbackInterval: time.Minute})
+//
+// When the connection is lost, the next call dials the addresses in order again. While connected to another address
+// than the first one, calls check that a preferred address is reachable again, every policy.FailbackInterval, and
+// move to it: the calls then go to the preferred node as soon as it is back. ConnInfo has the address connected.
+//
+// Each address is dialed within the dial timeout, see WithTimeouts.
+func DialFailover(addresses []string, policy FailoverPolicy, options ...Option) (*Client, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("no address")
+	}
+
+	f := &failover{
+		endpoints: make([]endpoint, len(addresses)),
+		policy:    policy,
+	}
+
+	for i, address := range addresses {
+		network, addr, err := ParseAddress(address)
+
+		if err != nil {
+			return nil, err
+		}
+
+		f.endpoints[i] = endpoint{network, addr}
+	}
+
+	dial := func(ctx context.Context, timeout time.Duration) (net.Conn, endpoint, error) {
+		return f.dial(ctx, timeout, len(f.endpoints))
+	}
+
+	options = append(options, func(c *Client) {
+		c.failover = f
+	})
+
+	return dialClient(context.Background(), dial, f.endpoints[0], options)
+}
+
+// dial connects to the first reachable endpoint among the first n ones, each one within timeout.
+func (f *failover) dial(ctx context.Context, timeout time.Duration, n int) (net.Conn, endpoint, error) {
+	var errs []error
+
+	for i, e := range f.endpoints[:n] {
+		conn, err := dialEndpoint(ctx, &net.Dialer{}, e, timeout)
+
+		if err == nil {
+			f.current = i
+			f.checked = time.Now()
+
+			return conn, e, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s %s: %w", e.network, e.address, err))
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, f.endpoints[0], errors.Join(errs...)
+}
+
+// failback moves the connection to a preferred endpoint if one is reachable again, and it is time to check. It must
+// be called with c.mu held, while the connection is usable.
+func (c *Client) failback(ctx context.Context, timeout time.Duration) {
+	f := c.failover
+
+	if f == nil || f.current == 0 || f.policy.FailbackInterval <= 0 {
+		return
+	}
+
+	if time.Since(f.checked) < f.policy.FailbackInterval {
+		return
+	}
+
+	f.checked = time.Now()
+
+	conn, e, err := f.dial(ctx, timeout, f.current)
+
+	if err != nil {
+		// stay on the current endpoint
+		return
+	}
+
+	c.log(ctx, slog.LevelDebug, "binrpc failback", slog.String("network", e.network), slog.String("address", e.address))
+
+	c.disconnect(nil)
+	c.replace(conn, e, nil)
+}
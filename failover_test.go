@@ -0,0 +1,114 @@
+package binrpc
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// listenAt is like listen, on address.
+func listenAt(t *testing.T, address string, handler func(records []Record) []any) net.Listener {
+	listener, err := net.Listen("tcp", address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go handle(conn, handler)
+		}
+	}()
+
+	return listener
+}
+
+// reply returns a handler replying with value.
+func reply(value string) func(records []Record) []any {
+	return func(records []Record) []any {
+		return []any{value}
+	}
+}
+
+func TestDialFailover(t *testing.T) {
+	// nothing listens on the preferred address yet
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	preferred := listener.Addr().String()
+	listener.Close()
+
+	backup := listen(t, reply("backup"))
+
+	client, err := DialFailover([]string{"tcp:" + preferred, "tcp:" + backup}, FailoverPolicy{
+		FailbackInterval: 20 * time.Millisecond,
+	}, WithTimeout(time.Second))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	call := func(expected string) {
+		t.Helper()
+
+		records, err := client.Call("core.echo")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value, _ := records[0].String(); value != expected {
+			t.Errorf(`expected "%s", got "%s"`, expected, value)
+		}
+	}
+
+	call("backup")
+
+	if info := client.connInfo(nil); info.Address != backup {
+		t.Errorf("expected address %s, got %s", backup, info.Address)
+	}
+
+	// the preferred node is back: calls move to it once the failback interval has elapsed
+	listener = listenAt(t, preferred, reply("preferred"))
+
+	call("backup")
+	time.Sleep(30 * time.Millisecond)
+	call("preferred")
+
+	// when the preferred node is lost, the next call fails and the following one fails over
+	listener.Close()
+	client.conn.Close()
+
+	if _, err = client.Call("core.echo"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	call("backup")
+}
+
+func TestDialFailoverUnreachable(t *testing.T) {
+	if _, err := DialFailover(nil, FailoverPolicy{}); err == nil {
+		t.Error("error must be returned without address")
+	}
+
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := listener.Addr().String()
+	listener.Close()
+
+	if _, err := DialFailover([]string{"tcp:" + address, "unix:/nonexistent"}, FailoverPolicy{}); err == nil {
+		t.Error("error must be returned when no address is reachable")
+	}
+}
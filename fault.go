@@ -0,0 +1,43 @@
+package binrpc
+
+import (
+	"fmt"
+)
+
+// flagFault is set in the header of replies reporting a fault.
+const flagFault uint8 = 0x3
+
+// RPCError is a fault reported by Kamailio, such as "500 command core.foo not found".
+// Codes follow the SIP conventions: 4xx for invalid requests (e.g. 400 for invalid parameters), 5xx for failures.
+type RPCError struct {
+	Code    int
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
+
+// Fault reports whether header is the header of a fault reply.
+func (header *Header) Fault() bool {
+	return header.flags&flagFault == flagFault
+}
+
+// faultError returns the fault contained in the records of a fault reply: a code and a message.
+func faultError(records []Record) *RPCError {
+	fault := &RPCError{
+		Code: 500,
+	}
+
+	if len(records) > 0 {
+		if code, err := records[0].Int(); err == nil {
+			fault.Code = code
+		}
+	}
+
+	if len(records) > 1 {
+		records[1].Scan(&fault.Message)
+	}
+
+	return fault
+}
@@ -0,0 +1,52 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestClientFault(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "core.foo" {
+			return []any{&RPCError{Code: 500, Message: "command core.foo not found"}}
+		}
+
+		return echo(records)
+	})
+
+	records, err := client.Call("core.foo")
+
+	if records != nil {
+		t.Error("records must be nil")
+	}
+
+	var fault *RPCError
+
+	if !errors.As(err, &fault) {
+		t.Fatalf("expected an *RPCError, got %v", err)
+	}
+
+	if fault.Code != 500 || fault.Message != "command core.foo not found" {
+		t.Errorf("unexpected fault %v", fault)
+	}
+
+	// a fault does not break the connection
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHeaderFault(t *testing.T) {
+	data, _ := hex.DecodeString("a1322a9883af")
+	header, err := ReadHeader(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !header.Fault() {
+		t.Error("header must be a fault")
+	}
+}
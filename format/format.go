@@ -0,0 +1,454 @@
+// Package format writes the records of a BINRPC reply for humans and other tools, as used by the binrpc command:
+//
+//	records, err := client.Call("tm.stats")
+//	err = format.JSON(os.Stdout, records)
+//
+// Structs keep the order of their items, and the values of a key found several times in a struct are grouped in a
+// list. Bytes are written as strings.
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+var errUnsupportedType = errors.New("unsupported record type")
+
+// Formatter writes the records of a reply to w.
+type Formatter func(w io.Writer, records []binrpc.Record) error
+
+// Encoder writes a value returned by Reply, possibly wrapped in an Object, to w.
+type Encoder func(w io.Writer, value any) error
+
+// formatters are the Formatters keyed by name.
+var formatters = map[string]Formatter{
+	"text":  Text,
+	"json":  JSON,
+	"yaml":  YAML,
+	"table": Table,
+	"flat":  Flat,
+}
+
+// encoders are the Encoders keyed by name. Only the structured formats have one.
+var encoders = map[string]Encoder{
+	"json": EncodeJSON,
+	"yaml": EncodeYAML,
+}
+
+// Lookup returns the Formatter named name: "text", "json", "yaml", "table" or "flat".
+func Lookup(name string) (Formatter, bool) {
+	formatter, ok := formatters[name]
+
+	return formatter, ok
+}
+
+// LookupEncoder returns the Encoder named name: "json" or "yaml". Values of several replies can be combined into
+// one document with an encoder, unlike with the other formats.
+func LookupEncoder(name string) (Encoder, bool) {
+	encoder, ok := encoders[name]
+
+	return encoder, ok
+}
+
+// NewObject returns an empty Object.
+func NewObject() *Object {
+	return &Object{values: make(map[string]any)}
+}
+
+// Object is a struct converted for output. It preserves the order of the keys, and groups the values of duplicate
+// keys in a list.
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+// duplicates holds the values of a key found several times in a struct.
+type duplicates []any
+
+// Add adds value for key. If key was already added, the values are grouped in a list.
+func (o *Object) Add(key string, value any) {
+	existing, ok := o.values[key]
+
+	if !ok {
+		o.keys = append(o.keys, key)
+		o.values[key] = value
+		return
+	}
+
+	if values, ok := existing.(duplicates); ok {
+		o.values[key] = append(values, value)
+	} else {
+		o.values[key] = duplicates{existing, value}
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	buffer.WriteByte('{')
+
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[key])
+
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(k)
+		buffer.WriteByte(':')
+		buffer.Write(v)
+	}
+
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// toValue converts record to an int, a string, a float64, an *Object or a []any. Bytes are converted to a string.
+func toValue(record binrpc.Record) (any, error) {
+	switch record.Type {
+	case binrpc.TypeInt, binrpc.TypeString, binrpc.TypeDouble:
+		return record.Value, nil
+	case binrpc.TypeBytes:
+		return string(record.Value.([]byte)), nil
+	case binrpc.TypeStruct:
+		items, _ := record.StructItems()
+		o := NewObject()
+
+		for _, item := range items {
+			value, err := toValue(item.Value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			o.Add(item.Key, value)
+		}
+
+		return o, nil
+	case binrpc.TypeArray:
+		elements, _ := record.Array()
+
+		return toList(elements)
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnsupportedType, record.Type)
+	}
+}
+
+func toList(records []binrpc.Record) ([]any, error) {
+	list := []any{}
+
+	for _, record := range records {
+		value, err := toValue(record)
+
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, value)
+	}
+
+	return list, nil
+}
+
+// Reply converts the records of a reply for an Encoder: a single record is converted to its value, several records to
+// a list. Values are ints, strings, float64s, *Objects for structs and []any for arrays.
+func Reply(records []binrpc.Record) (any, error) {
+	if len(records) == 1 {
+		return toValue(records[0])
+	}
+
+	return toList(records)
+}
+
+// JSON writes records as indented JSON, see Reply.
+func JSON(w io.Writer, records []binrpc.Record) error {
+	reply, err := Reply(records)
+
+	if err != nil {
+		return err
+	}
+
+	return EncodeJSON(w, reply)
+}
+
+// EncodeJSON writes value, such as a value returned by Reply, as indented JSON.
+func EncodeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// YAML writes records as YAML, see Reply.
+func YAML(w io.Writer, records []binrpc.Record) error {
+	reply, err := Reply(records)
+
+	if err != nil {
+		return err
+	}
+
+	return EncodeYAML(w, reply)
+}
+
+// EncodeYAML writes value, such as a value returned by Reply, as YAML.
+func EncodeYAML(w io.Writer, value any) error {
+	var buffer strings.Builder
+
+	if isScalar(value) || isEmpty(value) {
+		buffer.WriteString(yamlScalar(value) + "\n")
+	} else {
+		yamlNode(&buffer, value, "")
+	}
+
+	_, err := io.WriteString(w, buffer.String())
+
+	return err
+}
+
+// yamlNode writes an object or a list at indent.
+func yamlNode(buffer *strings.Builder, value any, indent string) {
+	switch v := value.(type) {
+	case *Object:
+		for _, key := range v.keys {
+			buffer.WriteString(indent + yamlString(key) + ":")
+			yamlChild(buffer, v.values[key], indent)
+		}
+	case []any:
+		for _, element := range v {
+			yamlElement(buffer, element, indent)
+		}
+	case duplicates:
+		for _, element := range v {
+			yamlElement(buffer, element, indent)
+		}
+	}
+}
+
+// yamlChild writes the value of a key, on the same line if possible.
+func yamlChild(buffer *strings.Builder, value any, indent string) {
+	if isScalar(value) || isEmpty(value) {
+		buffer.WriteString(" " + yamlScalar(value) + "\n")
+		return
+	}
+
+	buffer.WriteString("\n")
+	yamlNode(buffer, value, indent+"  ")
+}
+
+// yamlElement writes an element of a list.
+func yamlElement(buffer *strings.Builder, value any, indent string) {
+	if isScalar(value) || isEmpty(value) {
+		buffer.WriteString(indent + "- " + yamlScalar(value) + "\n")
+		return
+	}
+
+	var child strings.Builder
+
+	yamlNode(&child, value, indent+"  ")
+	buffer.WriteString(indent + "- " + strings.TrimPrefix(child.String(), indent+"  "))
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-/]*$`)
+
+// yamlString returns s as a plain YAML scalar if that is unambiguous, and quoted otherwise.
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "y", "n":
+		return strconv.Quote(s)
+	}
+
+	if yamlPlain.MatchString(s) {
+		return s
+	}
+
+	return strconv.Quote(s)
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case string:
+		return yamlString(v)
+	case *Object:
+		return "{}"
+	case []any, duplicates:
+		return "[]"
+	default:
+		return scalarString(value)
+	}
+}
+
+// Table writes records as a table. A list of structs of scalars has a column per key, other replies are written with
+// a row per scalar, with the path of the scalar as in Flat.
+func Table(w io.Writer, records []binrpc.Record) error {
+	reply, err := Reply(records)
+
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	if rows, columns, ok := tableRows(reply); ok {
+		fmt.Fprintln(writer, strings.Join(columns, "\t"))
+
+		for _, row := range rows {
+			var cells []string
+
+			for _, column := range columns {
+				if value, ok := row.values[column]; ok {
+					cells = append(cells, scalarString(value))
+				} else {
+					cells = append(cells, "")
+				}
+			}
+
+			fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		}
+	} else {
+		fmt.Fprintln(writer, "KEY\tVALUE")
+
+		flatten(reply, "", func(path string, value any) {
+			fmt.Fprintf(writer, "%s\t%s\n", path, scalarString(value))
+		})
+	}
+
+	return writer.Flush()
+}
+
+// tableRows returns the rows and columns of reply if it is a list of structs containing only scalars.
+func tableRows(reply any) ([]*Object, []string, bool) {
+	list, ok := reply.([]any)
+
+	if !ok || len(list) == 0 {
+		return nil, nil, false
+	}
+
+	var rows []*Object
+	var columns []string
+
+	seen := make(map[string]bool)
+
+	for _, element := range list {
+		row, ok := element.(*Object)
+
+		if !ok {
+			return nil, nil, false
+		}
+
+		for _, key := range row.keys {
+			if !isScalar(row.values[key]) {
+				return nil, nil, false
+			}
+
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, columns, true
+}
+
+// Flat writes a line per scalar of records, "path = value". Keys are joined with "." and list indexes written as
+// "[i]".
+func Flat(w io.Writer, records []binrpc.Record) error {
+	reply, err := Reply(records)
+
+	if err != nil {
+		return err
+	}
+
+	var buffer strings.Builder
+
+	flatten(reply, "", func(path string, value any) {
+		if path == "" {
+			buffer.WriteString(scalarString(value) + "\n")
+		} else {
+			buffer.WriteString(path + " = " + scalarString(value) + "\n")
+		}
+	})
+
+	_, err = io.WriteString(w, buffer.String())
+
+	return err
+}
+
+// flatten calls fn for each scalar in value, with its path: keys are joined with "." and list indexes are
+// written as "[i]". Duplicate keys are indexed like lists.
+func flatten(value any, path string, fn func(path string, value any)) {
+	switch v := value.(type) {
+	case *Object:
+		for _, key := range v.keys {
+			child := key
+
+			if path != "" {
+				child = path + "." + key
+			}
+
+			flatten(v.values[key], child, fn)
+		}
+	case []any:
+		for i, element := range v {
+			flatten(element, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case duplicates:
+		for i, element := range v {
+			flatten(element, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	default:
+		fn(path, value)
+	}
+}
+
+// Text writes records like kamcmd does, one record after the other, see binrpc.Fprint.
+func Text(w io.Writer, records []binrpc.Record) error {
+	return binrpc.Fprint(w, records)
+}
+
+func isScalar(value any) bool {
+	switch value.(type) {
+	case *Object, []any, duplicates:
+		return false
+	default:
+		return true
+	}
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case *Object:
+		return len(v.keys) == 0
+	case []any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func scalarString(value any) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
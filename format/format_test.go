@@ -0,0 +1,132 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	binrpc "github.com/florentchauveau/go-kamailio-binrpc/v3"
+)
+
+func item(key string, value binrpc.Record) binrpc.StructItem {
+	return binrpc.StructItem{Key: key, Value: value}
+}
+
+func integer(n int) binrpc.Record {
+	return binrpc.Record{Type: binrpc.TypeInt, Value: n}
+}
+
+func str(s string) binrpc.Record {
+	return binrpc.Record{Type: binrpc.TypeString, Value: s}
+}
+
+func structure(items ...binrpc.StructItem) binrpc.Record {
+	return binrpc.Record{Type: binrpc.TypeStruct, Value: items}
+}
+
+func array(elements ...binrpc.Record) binrpc.Record {
+	return binrpc.Record{Type: binrpc.TypeArray, Value: elements}
+}
+
+// reply is a struct with a nested struct, an array and a duplicate key.
+var reply = []binrpc.Record{
+	structure(
+		item("name", str("main")),
+		item("stats", structure(item("total", integer(3)))),
+		item("uris", array(str("sip:a"), str("sip:b"))),
+		item("alias", str("a")),
+		item("alias", str("b")),
+	),
+}
+
+func formatRecords(t *testing.T, output string, records []binrpc.Record) string {
+	var buffer strings.Builder
+
+	formatter, ok := Lookup(output)
+
+	if !ok {
+		t.Fatalf("unknown format %s", output)
+	}
+
+	if err := formatter(&buffer, records); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.String()
+}
+
+func TestWriteJSON(t *testing.T) {
+	expected := `{
+  "name": "main",
+  "stats": {
+    "total": 3
+  },
+  "uris": [
+    "sip:a",
+    "sip:b"
+  ],
+  "alias": [
+    "a",
+    "b"
+  ]
+}
+`
+
+	if output := formatRecords(t, "json", reply); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	expected := `name: main
+stats:
+  total: 3
+uris:
+  - "sip:a"
+  - "sip:b"
+alias:
+  - a
+  - b
+`
+
+	if output := formatRecords(t, "yaml", reply); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestWriteFlat(t *testing.T) {
+	expected := `name = main
+stats.total = 3
+uris[0] = sip:a
+uris[1] = sip:b
+alias[0] = a
+alias[1] = b
+`
+
+	if output := formatRecords(t, "flat", reply); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	processes := []binrpc.Record{
+		structure(item("pid", integer(100)), item("desc", str("main"))),
+		structure(item("pid", integer(101)), item("desc", str("udp receiver"))),
+	}
+
+	expected := `pid  desc
+100  main
+101  udp receiver
+`
+
+	if output := formatRecords(t, "table", processes); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	expected := "{\n\tname:  main\n\tstats: {\n\t\ttotal: 3\n\t}\n\turis: [\n\t\tsip:a\n\t\tsip:b\n\t]\n\talias: a\n\talias: b\n}\n"
+
+	if output := formatRecords(t, "text", reply); output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,153 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+// fuzzSeeds returns packets covering the record types, to seed the fuzz targets.
+func fuzzSeeds(f *testing.F) [][]byte {
+	seeds := [][]any{
+		{"core.echo", 42},
+		{"stats.fetch", "all"},
+		{1.5, map[string]any{"a": 1, "b": "c"}},
+		{[]any{1, "two", []string{"three"}}},
+		{[]byte{0, 1, 2}},
+	}
+
+	var packets [][]byte
+
+	for _, values := range seeds {
+		vector, err := VectorHex(0xcafe, values...)
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		packet, _ := hex.DecodeString(vector)
+		packets = append(packets, packet)
+	}
+
+	// a fault reply
+	fault, _ := hex.DecodeString("a1322a9883af2001f49125636f6d6d616e6420636f72652e6563686f20626f6e6a6f757273206e6f7420666f756e6400")
+
+	return append(packets, fault, deepPacket(f, 1000))
+}
+
+// deepPacket returns a packet whose payload is n struct headers, each one nested in the previous one.
+func deepPacket(tb testing.TB, n int) []byte {
+	packet, err := appendHeader(nil, 0, 0xcafe, n)
+
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return append(packet, bytes.Repeat([]byte{TypeStruct}, n)...)
+}
+
+func TestReadDeepNesting(t *testing.T) {
+	packet := deepPacket(t, 1<<20)
+	header, _ := ReadHeader(bytes.NewReader(packet))
+	payload := packet[header.Size():]
+
+	if _, err := ReadPacket(bytes.NewReader(packet), 0); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadPacket: expected ErrLimitExceeded, got %v", err)
+	}
+
+	if _, err := ReadRequest(bytes.NewReader(packet)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadRequest: expected ErrLimitExceeded, got %v", err)
+	}
+
+	if _, err := ReadRecord(bytes.NewReader(payload)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadRecord: expected ErrLimitExceeded, got %v", err)
+	}
+
+	it, err := ReadPacketStream(bytes.NewReader(packet), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = it.Next(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("ReadPacketStream: expected ErrLimitExceeded, got %v", err)
+	}
+}
+
+func FuzzReadHeader(f *testing.F) {
+	for _, packet := range fuzzSeeds(f) {
+		f.Add(packet)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := ReadHeader(bytes.NewReader(data))
+
+		if err != nil {
+			return
+		}
+
+		if header.Size() > len(data) || header.PayloadLength < 0 {
+			t.Errorf("invalid header %+v decoded from %d bytes", header, len(data))
+		}
+	})
+}
+
+func FuzzReadRecord(f *testing.F) {
+	for _, packet := range fuzzSeeds(f) {
+		header, _ := ReadHeader(bytes.NewReader(packet))
+		f.Add(packet[header.Size():])
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		record, err := ReadRecord(bytes.NewReader(data))
+
+		if err != nil {
+			return
+		}
+
+		if record.size > len(data) {
+			t.Errorf("record of size %d decoded from %d bytes", record.size, len(data))
+		}
+	})
+}
+
+func FuzzReadPacket(f *testing.F) {
+	for _, packet := range fuzzSeeds(f) {
+		f.Add(packet)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		options := ReaderOptions{RecordOffsets: true, ErrorSnippets: true}
+
+		records, err := ReadPacketWithOptions(bytes.NewReader(data), 0, options)
+
+		if err != nil {
+			return
+		}
+
+		RecordsToJSON(records)
+
+		// the stream decoder must agree with ReadPacket
+		it, err := ReadPacketStream(bytes.NewReader(data), 0)
+
+		if err != nil {
+			t.Fatalf("stream: %v", err)
+		}
+
+		count := 0
+
+		for {
+			if _, err = it.Next(); err != nil {
+				break
+			}
+
+			count++
+		}
+
+		if err != io.EOF || count != len(records) {
+			t.Errorf("stream: expected %d records, got %d (%v)", len(records), count, err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Caller calls RPC functions. It is implemented by *Client, *MuxClient, *Cache, *Pool, *JSONRPCClient and
+// FallbackCaller.
+type Caller interface {
+	CallContext(ctx context.Context, method string, args ...any) ([]Record, error)
+}
+
+// CallResult is the result of a call of a CallGroup.
+type CallResult struct {
+	Records []Record
+	Err     error
+}
+
+// CallGroup runs named calls concurrently, against one or more Callers, and collects their results. It is the common
+// shape of gathering everything for a scrape or a report:
+//
+//	group := binrpc.NewCallGroup(ctx)
+//
+//	var uptime struct {
+//		Uptime int
+//	}
+//
+//	group.GoScan("uptime", &uptime, client, "core.uptime")
+//	group.Go("tm", client, "tm.stats")
+//	group.Go("edge2", edge2, "tm.stats")
+//
+//	results, err := group.Wait()
+//
+// The first fatal error cancels the context of the calls still running. By default, faults replied by Kamailio (an
+// *RPCError, such as a method of a module that is not loaded) are not fatal, other errors are. See SetFatal.
+//
+// A CallGroup must not be reused after Wait.
+type CallGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	fatal  func(err error) bool
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	results map[string]CallResult
+	err     error
+}
+
+// NewCallGroup returns a CallGroup whose calls are made with a context derived from ctx.
+func NewCallGroup(ctx context.Context) *CallGroup {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &CallGroup{
+		ctx:     ctx,
+		cancel:  cancel,
+		fatal:   isFatal,
+		results: make(map[string]CallResult),
+	}
+}
+
+// SetFatal sets the function reporting whether the error of a call is fatal, and cancels the other calls. It must be
+// called before Go.
+func (g *CallGroup) SetFatal(fatal func(err error) bool) {
+	g.fatal = fatal
+}
+
+// Go calls method with args on caller in a new goroutine, and stores its result under name. A name must be used once.
+func (g *CallGroup) Go(name string, caller Caller, method string, args ...any) {
+	g.GoScan(name, nil, caller, method, args...)
+}
+
+// GoScan is like Go, but also scans the first record of the reply into v on success (see Record.Scan). A scan error
+// is the error of the call. v must not be accessed before Wait returns.
+func (g *CallGroup) GoScan(name string, v any, caller Caller, method string, args ...any) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		records, err := caller.CallContext(g.ctx, method, args...)
+
+		if err == nil && v != nil {
+			if len(records) == 0 {
+				err = errors.New("empty reply")
+			} else {
+				err = records[0].Scan(v)
+			}
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		g.results[name] = CallResult{Records: records, Err: err}
+
+		if err != nil && g.err == nil && g.fatal(err) {
+			g.err = fmt.Errorf("%s: %w", name, err)
+			g.cancel()
+		}
+	}()
+}
+
+// Wait waits for all the calls, and returns their results keyed by name, with the first fatal error if any.
+// The results of the calls canceled by a fatal error have the error of the context.
+func (g *CallGroup) Wait() (map[string]CallResult, error) {
+	g.wg.Wait()
+	g.cancel()
+
+	return g.results, g.err
+}
+
+// isFatal reports whether err is not a fault replied by Kamailio.
+func isFatal(err error) bool {
+	var fault *RPCError
+
+	return !errors.As(err, &fault)
+}
@@ -0,0 +1,88 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCallGroup(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "core.uptime":
+			return []any{map[string]any{"uptime": 42}}
+		case "tm.stats":
+			return []any{"ok"}
+		default:
+			return []any{&RPCError{Code: 500, Message: "command " + method + " not found"}}
+		}
+	})
+
+	group := NewCallGroup(context.Background())
+
+	var uptime struct {
+		Uptime int
+	}
+
+	group.GoScan("uptime", &uptime, client, "core.uptime")
+	group.Go("tm", client, "tm.stats")
+	group.Go("dialog", client, "dlg.stats_active")
+
+	results, err := group.Wait()
+
+	if err != nil {
+		t.Errorf("faults must not be fatal, got %v", err)
+	}
+
+	if uptime.Uptime != 42 {
+		t.Errorf("expected 42, got %d", uptime.Uptime)
+	}
+
+	if len(results["tm"].Records) != 1 || results["tm"].Err != nil {
+		t.Errorf("unexpected result %v", results["tm"])
+	}
+
+	var fault *RPCError
+
+	if !errors.As(results["dialog"].Err, &fault) {
+		t.Errorf("expected a fault, got %v", results["dialog"].Err)
+	}
+}
+
+// callerFunc is a Caller calling a function.
+type callerFunc func(ctx context.Context, method string, args ...any) ([]Record, error)
+
+func (f callerFunc) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	return f(ctx, method, args...)
+}
+
+func TestCallGroupCancel(t *testing.T) {
+	failing := callerFunc(func(ctx context.Context, method string, args ...any) ([]Record, error) {
+		return nil, io.ErrUnexpectedEOF
+	})
+
+	blocking := callerFunc(func(ctx context.Context, method string, args ...any) ([]Record, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, nil
+		}
+	})
+
+	group := NewCallGroup(context.Background())
+	group.Go("slow", blocking, "ul.dump")
+	group.Go("edge1", failing, "tm.stats")
+
+	results, err := group.Wait()
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	if !errors.Is(results["slow"].Err, context.Canceled) {
+		t.Errorf("expected the other calls to be canceled, got %v", results["slow"].Err)
+	}
+}
@@ -0,0 +1,201 @@
+package binrpc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultHealthInterval is the interval between two checks of a HealthChecker, unless configured.
+const defaultHealthInterval = 10 * time.Second
+
+// HealthConfig configures a HealthChecker. The zero value calls core.version every 10 seconds.
+type HealthConfig struct {
+	// Method is the RPC function called to check Kamailio, with Args. Defaults to core.version.
+	Method string
+	Args   []any
+
+	// Interval is the interval between two checks. Defaults to 10 seconds.
+	Interval time.Duration
+
+	// Timeout is the timeout of a check. Defaults to Interval.
+	Timeout time.Duration
+}
+
+// HealthState is the state of Kamailio, as seen by a HealthChecker.
+type HealthState struct {
+	// Up is true if the last check succeeded. A fault replied by Kamailio, such as an unknown method, proves it to be
+	// reachable: the state is up.
+	Up bool
+
+	// Err is the error of the last check, if the state is down.
+	Err error
+
+	// Checked is the time of the last check, zero before the first one.
+	Checked time.Time
+
+	// Since is the time of the last change of Up.
+	Since time.Time
+}
+
+// HealthChecker calls an RPC function periodically to tell whether Kamailio is reachable, such as for an exporter or
+// a readiness probe:
+//
+//	health := binrpc.NewHealthChecker(client, binrpc.HealthConfig{Interval: 5 * time.Second})
+//	defer health.Close()
+//
+//	health.Subscribe(func(state binrpc.HealthState) {
+//		log.Printf("kamailio up: %t (%v)", state.Up, state.Err)
+//	})
+//
+// The checks go through caller, which can be a *Client, a *Pool or any other Caller. A HealthChecker is safe for
+// concurrent use.
+type HealthChecker struct {
+	caller Caller
+	config HealthConfig
+
+	mu          sync.Mutex
+	state       HealthState
+	subscribers []func(HealthState)
+
+	// checkMu serializes the checks and the subscriptions, so that subscribers see the state changes in order
+	checkMu sync.Mutex
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHealthChecker returns a HealthChecker checking Kamailio with caller, once immediately and then every
+// config.Interval, until Close.
+func NewHealthChecker(caller Caller, config HealthConfig) *HealthChecker {
+	if config.Method == "" {
+		config.Method = "core.version"
+	}
+
+	if config.Interval <= 0 {
+		config.Interval = defaultHealthInterval
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = config.Interval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	h := &HealthChecker{
+		caller: caller,
+		config: config,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go h.run(ctx)
+
+	return h
+}
+
+// run checks Kamailio every interval until ctx is done.
+func (h *HealthChecker) run(ctx context.Context) {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check checks Kamailio now, without waiting for the next periodic check, and returns the new state. The check is
+// aborted when ctx is done, which then does not change the state.
+func (h *HealthChecker) Check(ctx context.Context) HealthState {
+	h.checkMu.Lock()
+	defer h.checkMu.Unlock()
+
+	checkCtx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+
+	_, err := h.caller.CallContext(checkCtx, h.config.Method, h.config.Args...)
+
+	if ctx.Err() != nil {
+		return h.State()
+	}
+
+	up := err == nil || isFault(err)
+
+	if up {
+		err = nil
+	}
+
+	h.mu.Lock()
+
+	now := time.Now()
+	changed := h.state.Checked.IsZero() || h.state.Up != up
+
+	if changed {
+		h.state.Since = now
+	}
+
+	h.state.Up = up
+	h.state.Err = err
+	h.state.Checked = now
+
+	state := h.state
+	subscribers := h.subscribers
+
+	h.mu.Unlock()
+
+	if changed {
+		for _, fn := range subscribers {
+			fn(state)
+		}
+	}
+
+	return state
+}
+
+// State returns the state of the last check.
+func (h *HealthChecker) State() HealthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.state
+}
+
+// Up reports whether the last check succeeded. It is false before the first check.
+func (h *HealthChecker) Up() bool {
+	return h.State().Up
+}
+
+// Subscribe adds fn to the functions called when the state changes between up and down, and after the first check.
+// The functions are called one after the other, by the goroutine of the check, and must not block.
+//
+// If Kamailio was already checked, fn is first called with the current state before Subscribe returns, so that no
+// change is missed. Subscribe waits for a check in progress.
+func (h *HealthChecker) Subscribe(fn func(state HealthState)) {
+	h.checkMu.Lock()
+	defer h.checkMu.Unlock()
+
+	h.mu.Lock()
+	h.subscribers = append(h.subscribers, fn)
+	state := h.state
+	h.mu.Unlock()
+
+	if !state.Checked.IsZero() {
+		fn(state)
+	}
+}
+
+// Close stops the periodic checks, and waits for the current one to return. It does not close the caller.
+func (h *HealthChecker) Close() error {
+	h.cancel()
+	<-h.done
+
+	return nil
+}
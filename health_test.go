@@ -0,0 +1,85 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthChecker(t *testing.T) {
+	var down atomic.Bool
+
+	caller := callerFunc(func(ctx context.Context, method string, args ...any) ([]Record, error) {
+		if method != "core.version" {
+			t.Errorf(`expected "core.version", got "%s"`, method)
+		}
+
+		if down.Load() {
+			return nil, net.ErrClosed
+		}
+
+		return []Record{{Type: TypeString, Value: "kamailio 5.8.0"}}, nil
+	})
+
+	health := NewHealthChecker(caller, HealthConfig{Interval: time.Hour})
+	defer health.Close()
+
+	states := make(chan HealthState, 10)
+
+	health.Subscribe(func(state HealthState) {
+		states <- state
+	})
+
+	// the first check, notified by the checker or by Subscribe
+	if state := <-states; !state.Up || state.Err != nil || state.Checked.IsZero() {
+		t.Errorf("expected up, got %+v", state)
+	}
+
+	// no notification without a change
+	health.Check(context.Background())
+
+	down.Store(true)
+
+	if state := health.Check(context.Background()); state.Up || !errors.Is(state.Err, net.ErrClosed) {
+		t.Errorf("expected down with net.ErrClosed, got %+v", state)
+	}
+
+	if state := <-states; state.Up {
+		t.Error("expected down to be notified")
+	}
+
+	if health.Up() {
+		t.Error("expected down")
+	}
+
+	down.Store(false)
+
+	if state := health.Check(context.Background()); !state.Up || state.Since.Before(state.Checked) {
+		t.Errorf("expected up since the last check, got %+v", state)
+	}
+
+	if state := <-states; !state.Up {
+		t.Error("expected up to be notified")
+	}
+
+	if len(states) != 0 {
+		t.Errorf("expected 3 notifications, got %d more", len(states))
+	}
+}
+
+func TestHealthCheckerFault(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{&RPCError{Code: 500, Message: "command not found"}}
+	})
+
+	health := NewHealthChecker(client, HealthConfig{Method: "missing.method", Interval: time.Hour})
+	defer health.Close()
+
+	// a fault proves Kamailio to be reachable
+	if state := health.Check(context.Background()); !state.Up {
+		t.Errorf("expected up, got %+v", state)
+	}
+}
@@ -0,0 +1,144 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+)
+
+type correlationIDKey struct{}
+
+type metadataKey struct{}
+
+type cookieKey struct{}
+
+// CallInfo describes a call. It is passed to hooks.
+type CallInfo struct {
+	// CorrelationID identifies the call across components. See WithCorrelationID.
+	CorrelationID string
+
+	Method string
+	Args   []any
+	Cookie uint32
+
+	// Metadata is the metadata carried by the context of the call, see WithMetadata. It is nil if there is none.
+	Metadata map[string]any
+}
+
+// Hooks are functions called around each call of a Client. Nil hooks are ignored.
+//
+// Hooks are the integration point for tracing: OnCallStart can start a span tagged with info.CorrelationID and
+// store it in the returned context, and OnCallDone can end it.
+type Hooks struct {
+	// OnCallStart is called before the request is written. The context it returns is passed to OnCallDone.
+	OnCallStart func(ctx context.Context, info CallInfo) context.Context
+
+	// OnCallDone is called when the call returns, with the error returned if any.
+	OnCallDone func(ctx context.Context, info CallInfo, err error)
+}
+
+// WithHooks sets the hooks called around each call.
+func WithHooks(hooks Hooks) Option {
+	return func(c *Client) {
+		c.hooks = hooks
+	}
+}
+
+// ConnInfo describes the connection of a Client. It is passed to connection hooks.
+type ConnInfo struct {
+	// Network and Address are the ones given to Dial, or the remote address of the connection given to NewClient.
+	Network string
+	Address string
+
+	// Err is the cause of a disconnection, or the error of a failed reconnection.
+	Err error
+}
+
+// ConnHooks are functions called on the lifecycle events of the connection of a Client. Nil hooks are ignored.
+//
+// Hooks are called synchronously: a slow hook delays the call that triggered it.
+type ConnHooks struct {
+	// OnConnect is called once the Client is created.
+	OnConnect func(info ConnInfo)
+
+	// OnDisconnect is called when the connection is lost, with the error that made it unusable, or closed by Close,
+	// with a nil error.
+	OnDisconnect func(info ConnInfo)
+
+	// OnReconnect is called after each attempt to replace a lost connection, with the error if the attempt failed.
+	// Clients created by Dial or DialWith attempt to reconnect on the call following a disconnection, see
+	// WithReconnect.
+	OnReconnect func(info ConnInfo)
+}
+
+// WithConnHooks sets the hooks called on the lifecycle events of the connection.
+func WithConnHooks(hooks ConnHooks) Option {
+	return func(c *Client) {
+		c.connHooks = hooks
+	}
+}
+
+// WithCorrelationID returns a copy of ctx carrying id. A call made with this context uses id as its correlation ID,
+// instead of one derived from its cookie.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or an empty string.
+// Inside hooks, the context always carries the correlation ID of the call.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+
+	return id
+}
+
+// WithMetadata returns a copy of ctx carrying key and value, in addition to the metadata already carried by ctx.
+// Metadata describes why a call is made, such as the tenant or the job that triggered it. It is passed to hooks in
+// CallInfo, and logged with the call (see WithLogger).
+func WithMetadata(ctx context.Context, key string, value any) context.Context {
+	metadata := make(map[string]any)
+
+	for k, v := range Metadata(ctx) {
+		metadata[k] = v
+	}
+
+	metadata[key] = value
+
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// Metadata returns a copy of the metadata carried by ctx, or nil.
+func Metadata(ctx context.Context) map[string]any {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]any)
+
+	if metadata == nil {
+		return nil
+	}
+
+	copied := make(map[string]any, len(metadata))
+
+	for k, v := range metadata {
+		copied[k] = v
+	}
+
+	return copied
+}
+
+// WithCookie returns a copy of ctx carrying cookie. A call made with this context uses cookie instead of a random one,
+// so that its packets can be matched with an application request, for instance in a capture.
+//
+// The call fails with ErrCookieInUse if a reply with the same cookie may still arrive, from a call that timed out.
+func WithCookie(ctx context.Context, cookie uint32) context.Context {
+	return context.WithValue(ctx, cookieKey{}, cookie)
+}
+
+// cookieFromContext returns the cookie carried by ctx, if any.
+func cookieFromContext(ctx context.Context) (uint32, bool) {
+	cookie, ok := ctx.Value(cookieKey{}).(uint32)
+
+	return cookie, ok
+}
+
+// correlationIDFromCookie is the correlation ID of a call when the caller did not provide one.
+func correlationIDFromCookie(cookie uint32) string {
+	return fmt.Sprintf("%08x", cookie)
+}
@@ -0,0 +1,229 @@
+package binrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHooksCorrelationID(t *testing.T) {
+	var started, done CallInfo
+	var doneID string
+
+	hooks := Hooks{
+		OnCallStart: func(ctx context.Context, info CallInfo) context.Context {
+			started = info
+			return ctx
+		},
+		OnCallDone: func(ctx context.Context, info CallInfo, err error) {
+			done = info
+			doneID = CorrelationID(ctx)
+		},
+	}
+
+	client := serve(t, echo, WithHooks(hooks))
+
+	ctx := WithCorrelationID(context.Background(), "job-42")
+
+	if _, err := client.CallContext(ctx, "core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if started.CorrelationID != "job-42" || done.CorrelationID != "job-42" || doneID != "job-42" {
+		t.Errorf(`expected correlation ID "job-42", got "%s", "%s" and "%s"`, started.CorrelationID, done.CorrelationID, doneID)
+	}
+
+	if started.Method != "core.echo" {
+		t.Errorf(`expected method "core.echo", got "%s"`, started.Method)
+	}
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := correlationIDFromCookie(done.Cookie); doneID != expected {
+		t.Errorf(`expected correlation ID "%s", got "%s"`, expected, doneID)
+	}
+}
+
+func TestLoggerCorrelationID(t *testing.T) {
+	var output bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := serve(t, echo, WithLogger(logger))
+
+	ctx := WithCorrelationID(context.Background(), "job-42")
+
+	if _, err := client.CallContext(ctx, "core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), "correlation_id=job-42") {
+		t.Errorf("correlation ID not found in log output: %s", output.String())
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	var output bytes.Buffer
+	var metadata map[string]any
+
+	hooks := Hooks{
+		OnCallDone: func(ctx context.Context, info CallInfo, err error) {
+			metadata = info.Metadata
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := serve(t, echo, WithHooks(hooks), WithLogger(logger))
+
+	ctx := WithMetadata(context.Background(), "tenant", "acme")
+	derived := WithMetadata(ctx, "job", 42)
+
+	if _, err := client.CallContext(derived, "dispatcher.reload"); err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata["tenant"] != "acme" || metadata["job"] != 42 {
+		t.Errorf("expected tenant and job metadata, got %v", metadata)
+	}
+
+	if parent := Metadata(ctx); len(parent) != 1 {
+		t.Errorf("expected the parent context to be unchanged, got %v", parent)
+	}
+
+	if !strings.Contains(output.String(), "metadata.job=42 metadata.tenant=acme") {
+		t.Errorf("metadata not found in log output: %s", output.String())
+	}
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if metadata != nil {
+		t.Errorf("expected no metadata, got %v", metadata)
+	}
+}
+
+func TestConnHooksReconnect(t *testing.T) {
+	var events []string
+
+	hooks := ConnHooks{
+		OnConnect: func(info ConnInfo) {
+			events = append(events, "connect")
+		},
+		OnDisconnect: func(info ConnInfo) {
+			if info.Err == nil {
+				events = append(events, "close")
+			} else {
+				events = append(events, "disconnect")
+			}
+		},
+		OnReconnect: func(info ConnInfo) {
+			events = append(events, "reconnect")
+
+			if info.Err != nil {
+				t.Error(info.Err)
+			}
+		},
+	}
+
+	address := listen(t, echo)
+	client, err := Dial("tcp", address, WithConnHooks(hooks))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a lost connection
+	client.conn.Close()
+
+	if _, err = client.Call("core.echo"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	client.Close()
+
+	if expected := "connect,disconnect,reconnect,close"; strings.Join(events, ",") != expected {
+		t.Errorf("expected %s, got %s", expected, strings.Join(events, ","))
+	}
+
+	if info := client.connInfo(nil); info.Network != "tcp" || info.Address != address {
+		t.Errorf("expected tcp %s, got %s %s", address, info.Network, info.Address)
+	}
+}
+
+func TestConnHooksClose(t *testing.T) {
+	var disconnects int
+
+	hooks := ConnHooks{
+		OnDisconnect: func(info ConnInfo) {
+			disconnects++
+
+			if info.Err != nil {
+				t.Error(info.Err)
+			}
+		},
+		OnReconnect: func(info ConnInfo) {
+			t.Error("a closed client must not reconnect")
+		},
+	}
+
+	client, err := Dial("tcp", listen(t, echo), WithConnHooks(hooks))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err = client.Call("core.echo"); err == nil {
+			t.Error("error must be returned")
+		}
+	}
+
+	if disconnects != 1 {
+		t.Errorf("expected 1 disconnection, got %d", disconnects)
+	}
+}
+
+func TestWithCookie(t *testing.T) {
+	var cookie uint32
+	var correlationID string
+
+	hooks := Hooks{
+		OnCallDone: func(ctx context.Context, info CallInfo, err error) {
+			cookie = info.Cookie
+			correlationID = info.CorrelationID
+		},
+	}
+
+	client := serve(t, echo, WithHooks(hooks), WithTimeout(100*time.Millisecond))
+
+	ctx := WithCookie(context.Background(), 0xcafe)
+
+	if _, err := client.CallContext(ctx, "core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cookie != 0xcafe || correlationID != "0000cafe" {
+		t.Errorf("expected cookie cafe and correlation ID 0000cafe, got %x and %s", cookie, correlationID)
+	}
+
+	// the late reply of this call is still expected
+	if _, err := client.CallContext(ctx, "slow", 150); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	if _, err := client.CallContext(ctx, "core.echo"); !errors.Is(err, ErrCookieInUse) {
+		t.Errorf("expected ErrCookieInUse, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// HTableEntry is an item of an htable.
+type HTableEntry struct {
+	Name string
+
+	// Value is a TypeString or a TypeInt record, as the item was set by htable.sets or htable.seti.
+	Value Record
+}
+
+// IsInt reports whether the value of the entry is an int.
+func (entry HTableEntry) IsInt() bool {
+	return entry.Value.Type == TypeInt
+}
+
+// HTable is an htable, as returned by htable.listTables.
+type HTable struct {
+	Name string `binrpc:"name"`
+
+	// DBTable is the database table the htable is loaded from, and DBMode is 1 if the htable is written back to it
+	// at shutdown.
+	DBTable string `binrpc:"dbtable"`
+	DBMode  int    `binrpc:"dbmode"`
+
+	// Expire is the number of seconds after which the items expire, 0 if they do not. If UpdateExpire is 1, updating
+	// an item resets its expiration.
+	Expire       int `binrpc:"expire"`
+	UpdateExpire int `binrpc:"updateexpire"`
+
+	// Size is the number of slots of the htable.
+	Size int `binrpc:"size"`
+
+	DMQReplicate int `binrpc:"dmqreplicate"`
+}
+
+// HTableGet calls htable.get with caller and returns the item key of table. If the item does not exist, Kamailio
+// replies with a fault, returned as an *RPCError.
+func HTableGet(ctx context.Context, caller Caller, table, key string) (*HTableEntry, error) {
+	records, err := caller.CallContext(ctx, "htable.get", table, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := ParseHTableEntries(records)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("item not found in reply")
+	}
+
+	return &entries[0], nil
+}
+
+// HTableSets calls htable.sets with caller to set the item key of table to the string value.
+func HTableSets(ctx context.Context, caller Caller, table, key, value string) error {
+	_, err := caller.CallContext(ctx, "htable.sets", table, key, value)
+
+	return err
+}
+
+// HTableSeti calls htable.seti with caller to set the item key of table to the int value.
+func HTableSeti(ctx context.Context, caller Caller, table, key string, value int) error {
+	_, err := caller.CallContext(ctx, "htable.seti", table, key, value)
+
+	return err
+}
+
+// HTableDelete calls htable.delete with caller to delete the item key of table.
+func HTableDelete(ctx context.Context, caller Caller, table, key string) error {
+	_, err := caller.CallContext(ctx, "htable.delete", table, key)
+
+	return err
+}
+
+// HTableDump calls htable.dump with caller and returns the items of table.
+func HTableDump(ctx context.Context, caller Caller, table string) ([]HTableEntry, error) {
+	records, err := caller.CallContext(ctx, "htable.dump", table)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseHTableEntries(records)
+}
+
+// HTableListTables calls htable.listTables with caller and returns the htables.
+func HTableListTables(ctx context.Context, caller Caller) ([]HTable, error) {
+	records, err := caller.CallContext(ctx, "htable.listTables")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []HTable
+
+	if err = Unmarshal(records, &tables); err != nil {
+		return nil, err
+	}
+
+	return tables, nil
+}
+
+// ParseHTableEntries decodes the reply of htable.get and htable.dump. The items are the structs with a "name" and a
+// "value" item, found at any depth, as htable.dump wraps them in structs and arrays of slots.
+func ParseHTableEntries(records []Record) ([]HTableEntry, error) {
+	var entries []HTableEntry
+
+	err := Walk(records, func(path string, record Record) error {
+		name, ok := record.Get("name")
+
+		if !ok {
+			return nil
+		}
+
+		value, ok := record.Get("value")
+
+		if !ok {
+			return nil
+		}
+
+		entry := HTableEntry{Value: value}
+
+		if err := name.Scan(&entry.Name); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		switch value.Type {
+		case TypeInt:
+			// ints are read unsigned: negative values must be restored
+			entry.Value.Value = int(int32(value.Value.(int)))
+		case TypeString:
+		default:
+			return fmt.Errorf("%s: unexpected value type %d", path, value.Type)
+		}
+
+		entries = append(entries, entry)
+
+		return SkipRecord
+	})
+
+	return entries, err
+}
@@ -0,0 +1,133 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestHTable(t *testing.T) {
+	var mu sync.Mutex
+
+	var calls []string
+
+	client := serve(t, func(records []Record) []any {
+		method, _ := records[0].String()
+
+		mu.Lock()
+		calls = append(calls, method)
+		mu.Unlock()
+
+		switch method {
+		case "htable.get":
+			key, _ := records[2].String()
+
+			if key != "maintenance" {
+				return []any{&RPCError{Code: 500, Message: "Key name doesn't exist in htable."}}
+			}
+
+			return []any{map[string]any{"name": "maintenance", "value": 1}}
+		case "htable.dump":
+			return []any{
+				[]StructItem{
+					{Key: "entry", Value: mustRecord(t, 3)},
+					{Key: "size", Value: mustRecord(t, 2)},
+					{Key: "slot", Value: mustRecord(t, []any{
+						map[string]any{"name": "maintenance", "value": 1},
+						map[string]any{"name": "banner", "value": "closed"},
+					})},
+				},
+				[]StructItem{
+					{Key: "entry", Value: mustRecord(t, 9)},
+					{Key: "size", Value: mustRecord(t, 1)},
+					{Key: "slot", Value: mustRecord(t, []any{
+						map[string]any{"item": map[string]any{"name": "offset", "value": -5}},
+					})},
+				},
+			}
+		case "htable.listTables":
+			return []any{
+				map[string]any{"name": "flags", "dbtable": "", "dbmode": 0, "expire": 0, "updateexpire": 1, "size": 256},
+				map[string]any{"name": "ipban", "dbtable": "", "dbmode": 0, "expire": 300, "updateexpire": 1, "size": 1024},
+			}
+		}
+
+		return []any{"ok"}
+	})
+
+	ctx := context.Background()
+
+	entry, err := HTableGet(ctx, client, "flags", "maintenance")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if entry.Name != "maintenance" || !entry.IsInt() || entry.Value.Value != 1 {
+		t.Errorf("expected maintenance=1, got %+v", entry)
+	}
+
+	var rpcErr *RPCError
+
+	if _, err = HTableGet(ctx, client, "flags", "unknown"); !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+
+	entries, err := HTableDump(ctx, client, "flags")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedEntries := map[string]any{"maintenance": 1, "banner": "closed", "offset": -5}
+
+	if len(entries) != len(expectedEntries) {
+		t.Fatalf("expected %d entries, got %d", len(expectedEntries), len(entries))
+	}
+
+	for _, entry := range entries {
+		if expected := expectedEntries[entry.Name]; entry.Value.Value != expected {
+			t.Errorf("expected %s=%v, got %v", entry.Name, expected, entry.Value.Value)
+		}
+	}
+
+	tables, err := HTableListTables(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTables := []HTable{
+		{Name: "flags", UpdateExpire: 1, Size: 256},
+		{Name: "ipban", Expire: 300, UpdateExpire: 1, Size: 1024},
+	}
+
+	if !reflect.DeepEqual(tables, expectedTables) {
+		t.Errorf("expected %+v, got %+v", expectedTables, tables)
+	}
+
+	if err = HTableSets(ctx, client, "flags", "banner", "open"); err != nil {
+		t.Error(err)
+	}
+
+	if err = HTableSeti(ctx, client, "flags", "maintenance", 0); err != nil {
+		t.Error(err)
+	}
+
+	if err = HTableDelete(ctx, client, "flags", "banner"); err != nil {
+		t.Error(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expectedCalls := []string{
+		"htable.get", "htable.get", "htable.dump", "htable.listTables", "htable.sets", "htable.seti", "htable.delete",
+	}
+
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("expected %v, got %v", expectedCalls, calls)
+	}
+}
@@ -0,0 +1,39 @@
+package binrpc
+
+import "context"
+
+// CallFunc performs a call, with the signature of Client.CallContext.
+type CallFunc func(ctx context.Context, method string, args ...any) ([]Record, error)
+
+// Interceptor wraps the calls of a Client, like the unary interceptors of gRPC. It returns a CallFunc calling next,
+// which performs the call or runs the next interceptor, and can act before and after it: log, measure, retry, or
+// change the method, the args or the context. Returning without calling next short-circuits the call:
+//
+//	func logCalls(next binrpc.CallFunc) binrpc.CallFunc {
+//		return func(ctx context.Context, method string, args ...any) ([]binrpc.Record, error) {
+//			start := time.Now()
+//			records, err := next(ctx, method, args...)
+//			log.Printf("%s: %v (%v)", method, time.Since(start), err)
+//
+//			return records, err
+//		}
+//	}
+type Interceptor func(next CallFunc) CallFunc
+
+// WithInterceptors adds interceptors around each call. The first one is the outermost: it runs first, and sees the
+// result of the others last. Interceptors run before the hooks (see WithHooks) and the logger (see WithLogger), which
+// see the method and the args passed to the innermost next.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
+// chainInterceptors returns call wrapped by interceptors, the first one being the outermost.
+func chainInterceptors(interceptors []Interceptor, call CallFunc) CallFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		call = interceptors[i](call)
+	}
+
+	return call
+}
@@ -0,0 +1,84 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithInterceptors(t *testing.T) {
+	var order []string
+
+	trace := func(name string) Interceptor {
+		return func(next CallFunc) CallFunc {
+			return func(ctx context.Context, method string, args ...any) ([]Record, error) {
+				order = append(order, name+" "+method)
+				records, err := next(ctx, method, args...)
+				order = append(order, name+" done")
+
+				return records, err
+			}
+		}
+	}
+
+	rename := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, method string, args ...any) ([]Record, error) {
+			return next(ctx, strings.ToUpper(method), args...)
+		}
+	}
+
+	client := serve(t, echo, WithInterceptors(trace("outer"), rename), WithInterceptors(trace("inner")))
+
+	records, err := client.Call("core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "CORE.ECHO" {
+		t.Errorf(`expected "CORE.ECHO", got "%s"`, value)
+	}
+
+	expected := "outer core.echo, inner CORE.ECHO, inner done, outer done"
+
+	if got := strings.Join(order, ", "); got != expected {
+		t.Errorf(`expected "%s", got "%s"`, expected, got)
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	errDenied := errors.New("denied")
+	called := false
+
+	deny := func(next CallFunc) CallFunc {
+		return func(ctx context.Context, method string, args ...any) ([]Record, error) {
+			if method == "core.kill" {
+				return nil, errDenied
+			}
+
+			return next(ctx, method, args...)
+		}
+	}
+
+	hooks := Hooks{
+		OnCallStart: func(ctx context.Context, info CallInfo) context.Context {
+			called = true
+			return ctx
+		},
+	}
+
+	client := serve(t, echo, WithInterceptors(deny), WithHooks(hooks))
+
+	if _, err := client.Call("core.kill"); !errors.Is(err, errDenied) {
+		t.Errorf("expected errDenied, got %v", err)
+	}
+
+	if called {
+		t.Error("the call must not reach the hooks")
+	}
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+}
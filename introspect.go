@@ -0,0 +1,70 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+)
+
+// ListMethods calls system.listMethods and returns the RPC methods exported by Kamailio, which depend on the modules
+// loaded.
+func (c *Client) ListMethods(ctx context.Context) ([]string, error) {
+	records, err := c.CallContext(ctx, "system.listMethods")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []string
+
+	if err = Unmarshal(records, &methods); err != nil {
+		return nil, err
+	}
+
+	return methods, nil
+}
+
+// HasMethod reports whether Kamailio exports method, for instance to check that a module is loaded before using it.
+// Each call lists the methods: use ListMethods to check several ones.
+func (c *Client) HasMethod(ctx context.Context, method string) (bool, error) {
+	methods, err := c.ListMethods(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range methods {
+		if m == method {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// MethodSignature calls system.methodSignature and returns the signatures of method. Parameters are named by
+// position ("#1", "#2"...), as Kamailio only returns their types. Kamailio implements system.methodSignature for few
+// modules: the others reply with a fault, returned as an *RPCError, or with no signature.
+func (c *Client) MethodSignature(ctx context.Context, method string) ([]Signature, error) {
+	records, err := c.CallContext(ctx, "system.methodSignature", method)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMethodSignature(method, records), nil
+}
+
+// MethodHelp calls system.methodHelp and returns the documentation of method.
+func (c *Client) MethodHelp(ctx context.Context, method string) (string, error) {
+	records, err := c.CallContext(ctx, "system.methodHelp", method)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(records) == 0 {
+		return "", errors.New("empty reply")
+	}
+
+	return records[0].String()
+}
@@ -0,0 +1,70 @@
+package binrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestIntrospection(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	server.Handle("system.methodSignature", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{[]any{"int", "string", "int"}}, nil
+	})
+
+	server.Handle("system.methodHelp", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{"Echoes the args"}, nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx := context.Background()
+
+	methods, err := client.ListMethods(ctx)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(methods) != 6 {
+		t.Errorf("expected 6 methods, got %v", methods)
+	}
+
+	if ok, err := client.HasMethod(ctx, "core.echo"); err != nil || !ok {
+		t.Errorf("expected core.echo, got %t (%v)", ok, err)
+	}
+
+	if ok, err := client.HasMethod(ctx, "dlg.list"); err != nil || ok {
+		t.Errorf("expected no dlg.list, got %t (%v)", ok, err)
+	}
+
+	signatures, err := client.MethodSignature(ctx, "core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(signatures) != 1 || len(signatures[0].Params) != 2 || signatures[0].Params[1].Type != TypeInt {
+		t.Errorf("unexpected signatures %+v", signatures)
+	}
+
+	if help, err := client.MethodHelp(ctx, "core.echo"); err != nil || help != "Echoes the args" {
+		t.Errorf(`expected "Echoes the args", got "%s" (%v)`, help, err)
+	}
+}
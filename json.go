@@ -0,0 +1,133 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalJSON implements json.Marshaler. Ints, doubles and strings are written as JSON values, bytes as base64
+// strings, arrays as JSON arrays, and structs as JSON objects keeping the order of their items.
+//
+// As BINRPC structs may contain the same key several times, the values of a duplicate key are grouped in an array:
+// the struct {"ip": "10.0.0.1", "ip": "10.0.0.2"} is written {"ip": ["10.0.0.1", "10.0.0.2"]}.
+func (record Record) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if err := record.writeJSON(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// MarshalJSON implements json.Marshaler. The item is written as an object with a single key.
+func (item StructItem) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+
+	if err := writeItemsJSON(&buffer, []StructItem{item}); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// RecordsToJSON returns the JSON array of records, such as the records of a reply. See Record.MarshalJSON.
+func RecordsToJSON(records []Record) ([]byte, error) {
+	if records == nil {
+		records = []Record{}
+	}
+
+	return json.Marshal(records)
+}
+
+func (record *Record) writeJSON(buffer *bytes.Buffer) error {
+	switch record.Type {
+	case TypeInt, TypeString, TypeDouble, TypeBytes:
+		value, err := json.Marshal(record.Value)
+
+		if err != nil {
+			return err
+		}
+
+		buffer.Write(value)
+	case TypeStruct:
+		items, err := record.StructItems()
+
+		if err != nil {
+			return err
+		}
+
+		return writeItemsJSON(buffer, items)
+	case TypeArray:
+		elements, err := record.Array()
+
+		if err != nil {
+			return err
+		}
+
+		buffer.WriteByte('[')
+
+		for i := range elements {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+
+			if err = elements[i].writeJSON(buffer); err != nil {
+				return err
+			}
+		}
+
+		buffer.WriteByte(']')
+	default:
+		return fmt.Errorf("type error: type %d not implemented", record.Type)
+	}
+
+	return nil
+}
+
+// writeItemsJSON writes items as a JSON object, grouping the values of duplicate keys in an array.
+func writeItemsJSON(buffer *bytes.Buffer, items []StructItem) error {
+	var keys []string
+
+	values := make(map[string][]Record)
+
+	for _, item := range items {
+		if _, ok := values[item.Key]; !ok {
+			keys = append(keys, item.Key)
+		}
+
+		values[item.Key] = append(values[item.Key], item.Value)
+	}
+
+	buffer.WriteByte('{')
+
+	for i, key := range keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(key)
+
+		buffer.Write(name)
+		buffer.WriteByte(':')
+
+		if len(values[key]) == 1 {
+			if err := values[key][0].writeJSON(buffer); err != nil {
+				return fmt.Errorf("struct item %s: %w", key, err)
+			}
+
+			continue
+		}
+
+		duplicates := Record{Type: TypeArray, Value: values[key]}
+
+		if err := duplicates.writeJSON(buffer); err != nil {
+			return fmt.Errorf("struct item %s: %w", key, err)
+		}
+	}
+
+	buffer.WriteByte('}')
+
+	return nil
+}
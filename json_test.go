@@ -0,0 +1,47 @@
+package binrpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRecordsToJSON(t *testing.T) {
+	records := []Record{
+		{Type: TypeString, Value: "ok"},
+		{Type: TypeStruct, Value: []StructItem{
+			{Key: "set", Value: Record{Type: TypeInt, Value: 1}},
+			{Key: "weight", Value: Record{Type: TypeDouble, Value: 0.5}},
+			{Key: "uri", Value: Record{Type: TypeString, Value: "sip:10.0.0.1"}},
+			{Key: "uri", Value: Record{Type: TypeString, Value: "sip:10.0.0.2"}},
+			{Key: "attrs", Value: Record{Type: TypeArray, Value: []Record{
+				{Type: TypeStruct, Value: []StructItem{}},
+			}}},
+		}},
+	}
+
+	data, err := RecordsToJSON(records)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `["ok",{"set":1,"weight":0.5,"uri":["sip:10.0.0.1","sip:10.0.0.2"],"attrs":[{}]}]`
+
+	if string(data) != expected {
+		t.Errorf("expected %s, got %s", expected, data)
+	}
+
+	if data, _ = RecordsToJSON(nil); string(data) != "[]" {
+		t.Errorf("expected [], got %s", data)
+	}
+
+	item := StructItem{Key: "uptime", Value: Record{Type: TypeInt, Value: 42}}
+
+	if data, _ = json.Marshal(item); string(data) != `{"uptime":42}` {
+		t.Errorf(`expected {"uptime":42}, got %s`, data)
+	}
+
+	if _, err = json.Marshal(Record{Type: TypeAVP, Value: "key"}); err == nil {
+		t.Error("error must be returned")
+	}
+}
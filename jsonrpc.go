@@ -0,0 +1,264 @@
+package binrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// JSONRPCClient calls RPC functions through the jsonrpcs module of Kamailio, over HTTP. It implements Caller, so that
+// an application can switch from BINRPC to JSONRPC, or fall back to it (see FallbackCaller), without code changes.
+//
+// Replies are converted to records: JSON objects become structs keeping the order and the duplicates of their keys,
+// numbers become ints, or doubles if they have a fractional part, booleans become ints (0 or 1), and null an empty
+// string. As jsonrpcs replies with an array when a command adds several values, each element of an array reply is a
+// record, like the values of a BINRPC reply: a command replying with a single array cannot be told apart.
+type JSONRPCClient struct {
+	url    string
+	client *http.Client
+	id     atomic.Uint64
+}
+
+// NewJSONRPCClient returns a JSONRPCClient posting the requests to url, such as "http://10.0.0.1:5060/RPC" (the path is
+// the one matched by the event route of xhttp calling jsonrpc_dispatch). If client is nil, http.DefaultClient is used.
+func NewJSONRPCClient(url string, client *http.Client) *JSONRPCClient {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &JSONRPCClient{
+		url:    url,
+		client: client,
+	}
+}
+
+// Call calls method with args, which can be of the types accepted by Client.Call.
+//
+// If Kamailio replies with an error, it is returned as an *RPCError holding the code and the message.
+func (c *JSONRPCClient) Call(method string, args ...any) ([]Record, error) {
+	return c.CallContext(context.Background(), method, args...)
+}
+
+// CallContext is like Call, but the call is aborted when ctx is done.
+func (c *JSONRPCClient) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	params, err := createRecords(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(struct {
+		JSONRPC string    `json:"jsonrpc"`
+		Method  string    `json:"method"`
+		Params  []*Record `json:"params"`
+		ID      uint64    `json:"id"`
+	}{"2.0", method, params, c.id.Add(1)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// faults are replied with an HTTP error status, such as 500, and a JSONRPC error
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err = json.Unmarshal(data, &reply); err != nil {
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected HTTP status %s", response.Status)
+		}
+
+		return nil, fmt.Errorf("invalid JSONRPC reply: %w", err)
+	}
+
+	if reply.Error != nil {
+		return nil, &RPCError{
+			Code:    reply.Error.Code,
+			Message: reply.Error.Message,
+		}
+	}
+
+	return jsonToRecords(reply.Result)
+}
+
+// jsonToRecords converts the result of a JSONRPC reply to records, each element of an array being a record.
+func jsonToRecords(result json.RawMessage) ([]Record, error) {
+	result = bytes.TrimSpace(result)
+
+	if len(result) == 0 || bytes.Equal(result, []byte("null")) {
+		return []Record{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+
+	record, err := jsonToRecord(decoder)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONRPC result: %w", err)
+	}
+
+	if record.Type == TypeArray {
+		return record.Value.([]Record), nil
+	}
+
+	return []Record{record}, nil
+}
+
+// jsonToRecord converts the next JSON value of decoder to a record.
+func jsonToRecord(decoder *json.Decoder) (Record, error) {
+	token, err := decoder.Token()
+
+	if err != nil {
+		return Record{}, err
+	}
+
+	switch v := token.(type) {
+	case json.Delim:
+		if v == '{' {
+			return jsonToStruct(decoder)
+		}
+
+		if v == '[' {
+			return jsonToArray(decoder)
+		}
+
+		return Record{}, fmt.Errorf("unexpected %v", v)
+	case string:
+		return Record{Type: TypeString, Value: v}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return Record{Type: TypeInt, Value: int(i)}, nil
+		}
+
+		f, err := v.Float64()
+
+		if err != nil {
+			return Record{}, err
+		}
+
+		return Record{Type: TypeDouble, Value: f}, nil
+	case bool:
+		if v {
+			return Record{Type: TypeInt, Value: 1}, nil
+		}
+
+		return Record{Type: TypeInt, Value: 0}, nil
+	case nil:
+		return Record{Type: TypeString, Value: ""}, nil
+	default:
+		return Record{}, fmt.Errorf("unexpected token %v", token)
+	}
+}
+
+// jsonToStruct converts the items of a JSON object, whose opening brace was read, to a struct.
+func jsonToStruct(decoder *json.Decoder) (Record, error) {
+	items := []StructItem{}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return Record{}, err
+		}
+
+		key, ok := token.(string)
+
+		if !ok {
+			return Record{}, errors.New("object key is not a string")
+		}
+
+		value, err := jsonToRecord(decoder)
+
+		if err != nil {
+			return Record{}, fmt.Errorf("struct item %s: %w", key, err)
+		}
+
+		items = append(items, StructItem{Key: key, Value: value})
+	}
+
+	// closing brace
+	if _, err := decoder.Token(); err != nil {
+		return Record{}, err
+	}
+
+	return Record{Type: TypeStruct, Value: items}, nil
+}
+
+// jsonToArray converts the elements of a JSON array, whose opening bracket was read, to an array.
+func jsonToArray(decoder *json.Decoder) (Record, error) {
+	elements := []Record{}
+
+	for decoder.More() {
+		element, err := jsonToRecord(decoder)
+
+		if err != nil {
+			return Record{}, fmt.Errorf("array element %d: %w", len(elements), err)
+		}
+
+		elements = append(elements, element)
+	}
+
+	// closing bracket
+	if _, err := decoder.Token(); err != nil {
+		return Record{}, err
+	}
+
+	return Record{Type: TypeArray, Value: elements}, nil
+}
+
+// FallbackCaller calls the first Caller, and the next one when a call fails because of the connection, such as a
+// *Client whose ctl socket is unreachable followed by a *JSONRPCClient. As a request may have been executed before
+// the connection failed, only the calls that could be retried (see WithRetry) fall back: calls to idempotent methods,
+// or whose context is made by WithRetryAllowed. Faults never fall back.
+type FallbackCaller []Caller
+
+// CallContext implements Caller.
+func (callers FallbackCaller) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	if len(callers) == 0 {
+		return nil, errors.New("no caller")
+	}
+
+	last := len(callers) - 1
+
+	for _, caller := range callers[:last] {
+		records, err := caller.CallContext(ctx, method, args...)
+
+		if err == nil || ctx.Err() != nil || !isConnError(err) || !retryAllowed(ctx, method) {
+			return records, err
+		}
+	}
+
+	return callers[last].CallContext(ctx, method, args...)
+}
@@ -0,0 +1,155 @@
+package binrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// jsonrpcServer emulates the jsonrpcs module, replying to each request with the reply of the method called.
+func jsonrpcServer(t *testing.T, replies map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string `json:"method"`
+			Params []any  `json:"params"`
+			ID     int    `json:"id"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+
+		reply, ok := replies[request.Method]
+
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"jsonrpc": "2.0", "error": {"code": 500, "message": "command not found"}, "id": 1}`))
+			return
+		}
+
+		if request.Method == "core.echo" {
+			params, _ := json.Marshal(request.Params)
+			reply = string(params)
+		}
+
+		w.Write([]byte(`{"jsonrpc": "2.0", "result": ` + reply + `, "id": 1}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestJSONRPCClient(t *testing.T) {
+	server := jsonrpcServer(t, map[string]string{
+		"core.version": `"kamailio 5.8.0"`,
+		"core.echo":    ``,
+		"ul.dump":      `{"AoR": "alice", "Contact": {"Q": -1, "Expires": 1.5}, "Contact": {"Q": 1000, "Enabled": true}}`,
+		"core.none":    `null`,
+	})
+
+	client := NewJSONRPCClient(server.URL, nil)
+
+	var caller Caller = client
+
+	records, err := caller.CallContext(context.Background(), "core.version")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); len(records) != 1 || value != "kamailio 5.8.0" {
+		t.Errorf(`expected "kamailio 5.8.0", got %v`, records)
+	}
+
+	// the params are sent as JSON, and each element of an array reply is a record
+	records, err = client.Call("core.echo", "a", 1, map[string]any{"b": 2.5})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	if value, _ := records[1].Int(); value != 1 {
+		t.Errorf("expected 1, got %d", value)
+	}
+
+	var params struct {
+		B float64
+	}
+
+	if err = records[2].Scan(&params); err != nil || params.B != 2.5 {
+		t.Errorf("expected 2.5, got %f (%v)", params.B, err)
+	}
+
+	// objects keep the order and the duplicates of their keys
+	records, err = client.Call("ul.dump")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, _ := records[0].StructItems()
+
+	if len(items) != 3 || items[1].Key != "Contact" || items[2].Key != "Contact" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+
+	contact, _ := items[1].Value.StructItems()
+
+	if q, _ := contact[0].Value.Int(); q != -1 {
+		t.Errorf("expected -1, got %d", q)
+	}
+
+	if expires, _ := contact[1].Value.Double(); expires != 1.5 {
+		t.Errorf("expected 1.5, got %f", expires)
+	}
+
+	if records, err = client.Call("core.none"); err != nil || len(records) != 0 {
+		t.Errorf("expected no record, got %v (%v)", records, err)
+	}
+
+	var fault *RPCError
+
+	if _, err = client.Call("core.kill"); !errors.As(err, &fault) || fault.Code != 500 {
+		t.Errorf("expected a fault 500, got %v", err)
+	}
+}
+
+func TestFallbackCaller(t *testing.T) {
+	server := jsonrpcServer(t, map[string]string{
+		"tm.stats":          `{"current": 1}`,
+		"dispatcher.reload": `"ok"`,
+	})
+
+	// nothing listens on the ctl socket anymore
+	client := serve(t, echo)
+	client.Close()
+
+	caller := FallbackCaller{client, NewJSONRPCClient(server.URL, nil)}
+
+	records, err := caller.CallContext(context.Background(), "tm.stats")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 1 || records[0].Type != TypeStruct {
+		t.Errorf("expected the reply of the JSONRPC server, got %v", records)
+	}
+
+	// a mutating method does not fall back, unless the caller allows it
+	if _, err = caller.CallContext(context.Background(), "dispatcher.reload"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	if _, err = caller.CallContext(WithRetryAllowed(context.Background()), "dispatcher.reload"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,96 @@
+package binrpc
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// keepAliveMethod is the RPC function called to keep an idle connection alive.
+const keepAliveMethod = "core.version"
+
+// WithKeepAlive makes the Client call core.version when its connection has been idle for interval, so that a
+// long-lived connection is not closed by a firewall or a NAT without the Client knowing it, which would delay the
+// next call. If the connection is found lost, a Client created by Dial or DialWith dials again right away, instead of
+// on the next call.
+//
+// The calls keeping the connection alive are not passed to the interceptors, the hooks or the statistics. Each one is
+// limited by interval.
+//
+// With NewPool, each connection of the pool is kept alive: the checks before use (see Pool.SetCheckInterval) can then
+// be disabled.
+func WithKeepAlive(interval time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = interval
+	}
+}
+
+// startKeepAlive starts keeping the connection alive, if WithKeepAlive is set, until Close.
+func (c *Client) startKeepAlive() {
+	if c.keepAlive <= 0 {
+		return
+	}
+
+	c.keepAliveStop = make(chan struct{})
+
+	go func() {
+		timer := time.NewTimer(c.keepAlive)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-timer.C:
+			case <-c.keepAliveStop:
+				return
+			}
+
+			idle := c.idle()
+
+			if idle >= c.keepAlive {
+				c.ping()
+				idle = 0
+			}
+
+			timer.Reset(c.keepAlive - idle)
+		}
+	}()
+}
+
+// idle returns the time elapsed since the end of the last call.
+func (c *Client) idle() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Since(c.used)
+}
+
+// markUsed records the end of a call. It must be called with c.mu held.
+func (c *Client) markUsed() {
+	c.used = time.Now()
+}
+
+// ping calls core.version to keep the connection alive.
+func (c *Client) ping() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.keepAlive)
+	defer cancel()
+
+	info := CallInfo{
+		Method: keepAliveMethod,
+		Cookie: c.cookies.Cookie(),
+	}
+
+	info.CorrelationID = correlationIDFromCookie(info.Cookie)
+	ctx = WithCorrelationID(ctx, info.CorrelationID)
+
+	_, err := c.call(ctx, info)
+
+	if c.logEnabled(ctx, slog.LevelDebug) {
+		var attrs []slog.Attr
+
+		if err != nil && !isFault(err) {
+			attrs = append(attrs, errorAttr(err))
+		}
+
+		c.log(ctx, slog.LevelDebug, "binrpc keepalive", attrs...)
+	}
+}
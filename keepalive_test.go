@@ -0,0 +1,73 @@
+package binrpc
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeepAlive(t *testing.T) {
+	var pings atomic.Int32
+
+	client := serve(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == keepAliveMethod {
+			pings.Add(1)
+		}
+
+		return echo(records)
+	}, WithKeepAlive(50*time.Millisecond), WithStats())
+
+	// calls keep the connection busy: no ping
+	for i := 0; i < 5; i++ {
+		if _, err := client.Call("core.echo", 20); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := pings.Load(); n != 0 {
+		t.Errorf("expected no ping while calls are made, got %d", n)
+	}
+
+	time.Sleep(180 * time.Millisecond)
+
+	if n := pings.Load(); n < 2 || n > 4 {
+		t.Errorf("expected about 3 pings while idle, got %d", n)
+	}
+
+	if _, ok := client.Stats()[keepAliveMethod]; ok {
+		t.Error("pings must not be counted in the statistics")
+	}
+
+	client.Close()
+	n := pings.Load()
+	time.Sleep(100 * time.Millisecond)
+
+	if pings.Load() != n {
+		t.Error("no ping must be sent after Close")
+	}
+}
+
+func TestKeepAliveReconnect(t *testing.T) {
+	var reconnected atomic.Int32
+
+	client := serve(t, echo, WithKeepAlive(30*time.Millisecond), WithConnHooks(ConnHooks{
+		OnReconnect: func(info ConnInfo) {
+			if info.Err == nil {
+				reconnected.Add(1)
+			}
+		},
+	}))
+
+	// the connection is lost while idle
+	client.conn.Close()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if reconnected.Load() == 0 {
+		t.Fatal("expected the keepalive to reconnect")
+	}
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+}
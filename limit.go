@@ -0,0 +1,195 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by the calls of a Client whose circuit breaker is open, see WithCircuitBreaker.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// WithRateLimit limits the calls of the Client to rate per second on average, with bursts of up to burst calls (at
+// least 1). A call exceeding the limit waits for its turn, or fails with context.DeadlineExceeded right away if its
+// context expires first. This protects the ctl module, which handles the requests of a connection one at a time,
+// from tooling calling in a loop.
+//
+// The limit applies to the calls, and not to their retries (see WithRetry).
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) {
+		if rate <= 0 {
+			c.limiter = nil
+			return
+		}
+
+		c.limiter = &rateLimiter{
+			interval: time.Duration(float64(time.Second) / rate),
+			burst:    max(burst, 1),
+		}
+	}
+}
+
+// rateLimiter spaces the calls by interval, allowing bursts of up to burst calls.
+type rateLimiter struct {
+	interval time.Duration
+	burst    int
+
+	mu sync.Mutex
+	// next is the time at which the next call would be allowed if there were no bursts
+	next time.Time
+}
+
+// wait waits for the turn of a call, or returns an error if ctx is done first.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+
+	now := time.Now()
+	next := l.next
+
+	if next.Before(now) {
+		next = now
+	}
+
+	// the calls allowed in a burst are ahead of their time
+	delay := next.Sub(now) - time.Duration(l.burst-1)*l.interval
+
+	if deadline, ok := ctx.Deadline(); ok && delay > 0 && now.Add(delay).After(deadline) {
+		l.mu.Unlock()
+		return context.DeadlineExceeded
+	}
+
+	l.next = next.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleep(ctx, delay)
+}
+
+// CircuitBreakerPolicy configures the circuit breaker of a Client, see WithCircuitBreaker.
+type CircuitBreakerPolicy struct {
+	// Failures is the number of consecutive calls failing because of the connection, or timing out, that opens the
+	// circuit.
+	Failures int
+
+	// Cooldown is the duration the circuit stays open, before a call is let through to check Kamailio again.
+	Cooldown time.Duration
+}
+
+// WithCircuitBreaker makes the calls of the Client fail fast with ErrCircuitOpen once policy.Failures consecutive
+// calls failed because of the connection or timed out, instead of piling up on a Kamailio that does not answer.
+// After policy.Cooldown, a single call is let through: its success closes the circuit, and its failure opens it for
+// another cooldown. Faults replied by Kamailio prove it to be answering, and close the circuit.
+func WithCircuitBreaker(policy CircuitBreakerPolicy) Option {
+	return func(c *Client) {
+		if policy.Failures <= 0 {
+			c.breaker = nil
+			return
+		}
+
+		c.breaker = &circuitBreaker{policy: policy}
+	}
+}
+
+// circuitBreaker counts the consecutive failures of the calls, and rejects the calls while open.
+type circuitBreaker struct {
+	policy CircuitBreakerPolicy
+
+	mu       sync.Mutex
+	failures int
+	// openUntil is the end of the cooldown, and probing is set while the call checking Kamailio is in progress
+	openUntil time.Time
+	probing   bool
+}
+
+// allow returns ErrCircuitOpen if the circuit is open. Otherwise, the call must report its result with done, probe
+// being set if the call checks Kamailio after a cooldown.
+func (b *circuitBreaker) allow() (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.policy.Failures {
+		return false, nil
+	}
+
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false, ErrCircuitOpen
+	}
+
+	b.probing = true
+
+	return true, nil
+}
+
+// done records the result of a call allowed by allow, and reports whether it opened the circuit.
+func (b *circuitBreaker) done(probe bool, err error) (opened bool) {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+
+	switch {
+	case isConnError(err) || errors.Is(err, context.DeadlineExceeded):
+		b.failures++
+
+		// the calls in progress when the circuit opened do not extend the cooldown
+		if probe || b.failures == b.policy.Failures {
+			b.openUntil = time.Now().Add(b.policy.Cooldown)
+			return true
+		}
+	case err == nil || isFault(err):
+		b.failures = 0
+	}
+
+	return false
+}
+
+// abort releases a call allowed by allow which was not made.
+func (b *circuitBreaker) abort(probe bool) {
+	if b == nil || !probe {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// admit waits for the turn of a call, see WithRateLimit, and checks the circuit breaker, see WithCircuitBreaker. If
+// the call can be made, its result must be passed to release.
+func (c *Client) admit(ctx context.Context) (release func(err error), err error) {
+	probe, err := c.breaker.allow()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.limiter.wait(ctx); err != nil {
+		// the call was not made: it says nothing about Kamailio
+		c.breaker.abort(probe)
+
+		return nil, err
+	}
+
+	return func(err error) {
+		if c.breaker.done(probe, err) {
+			c.log(ctx, slog.LevelDebug, "binrpc circuit open", slog.Duration("cooldown", c.breaker.policy.Cooldown),
+				errorAttr(err))
+		}
+	}, nil
+}
@@ -0,0 +1,86 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	client := serve(t, echo, WithRateLimit(20, 2))
+
+	start := time.Now()
+
+	// 2 calls in a burst, then one every 50ms
+	for i := 0; i < 5; i++ {
+		if _, err := client.Call("core.echo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond || elapsed > 400*time.Millisecond {
+		t.Errorf("expected about 150ms, got %v", elapsed)
+	}
+
+	// the turn of the next call is after the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+
+	if _, err := client.CallContext(ctx, "core.echo"); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("expected the call to fail right away, got %v", elapsed)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var replying atomic.Bool
+
+	client := serve(t, func(records []Record) []any {
+		if !replying.Load() {
+			return nil
+		}
+
+		return echo(records)
+	}, WithTimeout(20*time.Millisecond), WithCircuitBreaker(CircuitBreakerPolicy{
+		Failures: 2,
+		Cooldown: 100 * time.Millisecond,
+	}))
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call("core.echo"); !isTimeout(err) {
+			t.Fatalf("expected a timeout, got %v", err)
+		}
+	}
+
+	if _, err := client.Call("core.echo"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// after the cooldown, a failed call opens the circuit again
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := client.Call("core.echo"); !isTimeout(err) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+
+	if _, err := client.Call("core.echo"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// a successful call closes it
+	replying.Store(true)
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call("core.echo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
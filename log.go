@@ -0,0 +1,43 @@
+package binrpc
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+)
+
+// LevelTrace is the level of the hex dumps of the packets written and read by a Client, see WithLogger. It is below
+// slog.LevelDebug, so that a handler at debug level logs the events without the dumps.
+const LevelTrace = slog.LevelDebug - 4
+
+// logEnabled reports whether the Client logs at level.
+func (c *Client) logEnabled(ctx context.Context, level slog.Level) bool {
+	return c.logger != nil && c.logger.Enabled(ctx, level)
+}
+
+// log logs msg at level with attrs, and the correlation ID carried by ctx if any.
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	if !c.logEnabled(ctx, level) {
+		return
+	}
+
+	if id := CorrelationID(ctx); id != "" {
+		attrs = append([]slog.Attr{slog.String("correlation_id", id)}, attrs...)
+	}
+
+	c.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logPacket logs the hex dump of packet at LevelTrace, msg describing it.
+func (c *Client) logPacket(ctx context.Context, msg string, cookie uint32, packet []byte) {
+	if !c.logEnabled(ctx, LevelTrace) {
+		return
+	}
+
+	c.log(ctx, LevelTrace, msg, slog.Any("cookie", cookie), slog.String("hex", hex.EncodeToString(packet)))
+}
+
+// errorAttr returns the "error" attribute of err.
+func errorAttr(err error) slog.Attr {
+	return slog.String("error", err.Error())
+}
@@ -0,0 +1,71 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerEvents(t *testing.T) {
+	var output bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: LevelTrace}))
+	client := serve(t, echo, WithLogger(logger), WithCookieSource(SequentialCookies(1)))
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	request, _ := AppendPacket(nil, 1, Record{Type: TypeString, Value: "core.echo"})
+
+	for _, expected := range []string{
+		`msg="binrpc dial"`,
+		`msg="binrpc write" correlation_id=00000001 method=core.echo cookie=1`,
+		`msg="binrpc write packet" correlation_id=00000001 cookie=1 hex=` + hex.EncodeToString(request),
+		`msg="binrpc read" correlation_id=00000001 cookie=1 size=16 fault=false skipped=false`,
+		`msg="binrpc read packet"`,
+		`msg="binrpc call"`,
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("%s not found in log output: %s", expected, output.String())
+		}
+	}
+
+	output.Reset()
+
+	// the dumps are not logged at debug level
+	logger = slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client = serve(t, echo, WithLogger(logger))
+
+	if _, err := client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), `msg="binrpc write"`) || strings.Contains(output.String(), "hex=") {
+		t.Errorf("unexpected log output: %s", output.String())
+	}
+}
+
+func TestLoggerRetry(t *testing.T) {
+	var output bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	address, _ := flakyServer(t)
+	client, err := Dial("tcp", address, WithRetry(RetryPolicy{Attempts: 2}), WithLogger(logger))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Call("tm.stats"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(output.String(), `msg="binrpc retry"`) || !strings.Contains(output.String(), "attempt=1") {
+		t.Errorf("retry not found in log output: %s", output.String())
+	}
+}
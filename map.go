@@ -0,0 +1,89 @@
+package binrpc
+
+import (
+	"fmt"
+)
+
+// DuplicateKeys is the policy of Record.Map for the keys found several times in a struct, such as "SET" in the reply
+// of dispatcher.list.
+type DuplicateKeys int
+
+const (
+	// DuplicateFirst keeps the first value of a duplicate key.
+	DuplicateFirst DuplicateKeys = iota
+
+	// DuplicateLast keeps the last value of a duplicate key.
+	DuplicateLast
+
+	// DuplicateSlice groups the values of a duplicate key in a []any, like Record.MarshalJSON. The values of the other
+	// keys are not wrapped.
+	DuplicateSlice
+)
+
+// Map returns the items of a struct value as a map, or an error if not a struct. Ints, strings, doubles and bytes are
+// converted to int, string, float64 and []byte, nested structs to map[string]any, and arrays to []any. duplicates is
+// the policy for the keys found several times in a struct:
+//
+//	stats, err := records[0].Map(binrpc.DuplicateLast)
+//
+//	fmt.Println(stats["current"])
+func (record *Record) Map(duplicates DuplicateKeys) (map[string]any, error) {
+	items, err := record.StructItems()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(items))
+	grouped := make(map[string]bool)
+
+	for _, item := range items {
+		value, err := item.Value.toAny(duplicates)
+
+		if err != nil {
+			return nil, fmt.Errorf("struct item %s: %w", item.Key, err)
+		}
+
+		previous, found := m[item.Key]
+
+		switch {
+		case !found, duplicates == DuplicateLast:
+			m[item.Key] = value
+		case duplicates == DuplicateSlice && grouped[item.Key]:
+			m[item.Key] = append(previous.([]any), value)
+		case duplicates == DuplicateSlice:
+			m[item.Key] = []any{previous, value}
+			grouped[item.Key] = true
+		}
+	}
+
+	return m, nil
+}
+
+// toAny returns the value of record as a Go value, see Map.
+func (record *Record) toAny(duplicates DuplicateKeys) (any, error) {
+	switch record.Type {
+	case TypeInt, TypeString, TypeDouble, TypeBytes:
+		return record.Value, nil
+	case TypeStruct:
+		return record.Map(duplicates)
+	case TypeArray:
+		elements, err := record.Array()
+
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]any, len(elements))
+
+		for i := range elements {
+			if values[i], err = elements[i].toAny(duplicates); err != nil {
+				return nil, fmt.Errorf("array element %d: %w", i, err)
+			}
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("type error: type %d not implemented", record.Type)
+	}
+}
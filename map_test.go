@@ -0,0 +1,51 @@
+package binrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordMap(t *testing.T) {
+	record := mustRecord(t, []StructItem{
+		{Key: "NRSETS", Value: mustRecord(t, 2)},
+		{Key: "SET", Value: mustRecord(t, []StructItem{{Key: "ID", Value: mustRecord(t, 1)}})},
+		{Key: "SET", Value: mustRecord(t, []StructItem{{Key: "ID", Value: mustRecord(t, 2)}})},
+		{Key: "SET", Value: mustRecord(t, []StructItem{{Key: "ID", Value: mustRecord(t, 3)}})},
+		{Key: "load", Value: mustRecord(t, 0.5)},
+		{Key: "names", Value: mustRecord(t, []any{"a", "b"})},
+	})
+
+	tests := []struct {
+		duplicates DuplicateKeys
+		sets       any
+	}{
+		{DuplicateFirst, map[string]any{"ID": 1}},
+		{DuplicateLast, map[string]any{"ID": 3}},
+		{DuplicateSlice, []any{map[string]any{"ID": 1}, map[string]any{"ID": 2}, map[string]any{"ID": 3}}},
+	}
+
+	for _, test := range tests {
+		m, err := record.Map(test.duplicates)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := map[string]any{
+			"NRSETS": 2,
+			"SET":    test.sets,
+			"load":   0.5,
+			"names":  []any{"a", "b"},
+		}
+
+		if !reflect.DeepEqual(m, expected) {
+			t.Errorf("policy %d: expected %v, got %v", test.duplicates, expected, m)
+		}
+	}
+
+	record = mustRecord(t, 1)
+
+	if _, err := record.Map(DuplicateFirst); err == nil {
+		t.Error("error must be returned")
+	}
+}
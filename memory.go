@@ -0,0 +1,227 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ShmMemStats is the usage of the shared memory, in bytes, as returned by core.shmmem.
+type ShmMemStats struct {
+	Total    uint64 `binrpc:"total"`
+	Free     uint64 `binrpc:"free"`
+	Used     uint64 `binrpc:"used"`
+	RealUsed uint64 `binrpc:"real_used"`
+	MaxUsed  uint64 `binrpc:"max_used"`
+
+	// Fragments is the number of fragments of the free memory.
+	Fragments uint64 `binrpc:"fragments"`
+}
+
+// PkgMemStats is the usage of the private (pkg) memory of a process, in bytes, as returned by pkg.stats.
+type PkgMemStats struct {
+	Entry int `binrpc:"entry"`
+	PID   int `binrpc:"pid"`
+	Rank  int `binrpc:"rank"`
+
+	Used       uint64 `binrpc:"used"`
+	Free       uint64 `binrpc:"free"`
+	RealUsed   uint64 `binrpc:"real_used"`
+	TotalSize  uint64 `binrpc:"total_size"`
+	TotalFrags uint64 `binrpc:"total_frags"`
+
+	// Description is the description of the process, such as "udp receiver child=0 sock=10.0.0.1:5060".
+	Description string `binrpc:"desc"`
+}
+
+// ModMemStats is the memory allocated by a module, as returned by mod.stats.
+type ModMemStats struct {
+	Module string
+
+	// Pkg and Shm are the allocations in the private and in the shared memory, nil if not requested.
+	Pkg *MemAllocations
+	Shm *MemAllocations
+}
+
+// MemAllocations is the memory allocated by a module in the private or in the shared memory.
+type MemAllocations struct {
+	// Total is the sum of the sizes of the allocations, in bytes.
+	Total uint64
+
+	Allocations []MemAllocation
+}
+
+// MemAllocation is the memory allocated at a line of the code of a module.
+type MemAllocation struct {
+	Function string
+	Line     int
+
+	// Size is the number of bytes allocated at this line and not freed yet.
+	Size uint64
+}
+
+// CoreShmMem calls core.shmmem with caller and returns the usage of the shared memory.
+func CoreShmMem(ctx context.Context, caller Caller) (*ShmMemStats, error) {
+	records, err := caller.CallContext(ctx, "core.shmmem")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stats ShmMemStats
+
+	if err = Unmarshal(records, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// PkgStats calls pkg.stats of the kex module with caller and returns the usage of the private memory of each
+// process.
+func PkgStats(ctx context.Context, caller Caller) ([]PkgMemStats, error) {
+	records, err := caller.CallContext(ctx, "pkg.stats")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []PkgMemStats
+
+	if err = Unmarshal(records, &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// ModStats calls mod.stats of the kex module with caller and returns the memory allocated by module, or by all the
+// modules if module is "all". memory is "pkg", "shm" or "all" for both.
+func ModStats(ctx context.Context, caller Caller, module, memory string) ([]ModMemStats, error) {
+	records, err := caller.CallContext(ctx, "mod.stats", module, memory)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseModStats(records, memory)
+}
+
+// ParseModStats decodes the reply of mod.stats called for memory, "pkg", "shm" or "all".
+//
+// Each module is replied as a "Module: <name>" string (or a struct with a Module item), followed by a struct of
+// allocations for each memory requested, pkg first. The allocations are items like "sip_msg_shm_clone(496): 959632",
+// the function and the line of the allocation and its size, and a "Total" item.
+func ParseModStats(records []Record, memory string) ([]ModMemStats, error) {
+	var kinds []string
+
+	switch memory {
+	case "pkg", "shm":
+		kinds = []string{memory}
+	case "all":
+		kinds = []string{"pkg", "shm"}
+	default:
+		return nil, fmt.Errorf("invalid memory %q", memory)
+	}
+
+	var stats []ModMemStats
+
+	// next is the index in kinds of the next struct of allocations of the current module
+	next := 0
+
+	for i := range records {
+		record := &records[i]
+
+		if name, ok := moduleName(record); ok {
+			stats = append(stats, ModMemStats{Module: name})
+			next = 0
+
+			continue
+		}
+
+		if record.Type != TypeStruct {
+			continue
+		}
+
+		if len(stats) == 0 {
+			return nil, fmt.Errorf("record %d: allocations without module", i)
+		}
+
+		if next == len(kinds) {
+			return nil, fmt.Errorf("module %s: unexpected allocations", stats[len(stats)-1].Module)
+		}
+
+		allocations, err := parseAllocations(record)
+
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", stats[len(stats)-1].Module, err)
+		}
+
+		if kinds[next] == "pkg" {
+			stats[len(stats)-1].Pkg = allocations
+		} else {
+			stats[len(stats)-1].Shm = allocations
+		}
+
+		next++
+	}
+
+	return stats, nil
+}
+
+// moduleName returns the name of the module starting at record, a "Module: <name>" string or a struct with a single
+// Module item.
+func moduleName(record *Record) (string, bool) {
+	if s, err := record.String(); err == nil {
+		name, ok := strings.CutPrefix(s, "Module: ")
+		return strings.TrimSpace(name), ok
+	}
+
+	if keys := record.Keys(); len(keys) == 1 && keys[0] == "Module" {
+		value, _ := record.Get("Module")
+		name, err := value.String()
+
+		return name, err == nil
+	}
+
+	return "", false
+}
+
+// parseAllocations returns the allocations of record, a struct.
+func parseAllocations(record *Record) (*MemAllocations, error) {
+	items, err := record.StructItems()
+
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := &MemAllocations{}
+
+	for _, item := range items {
+		var size uint64
+
+		if err = item.Value.Scan(&size); err != nil {
+			return nil, fmt.Errorf("%s: %w", item.Key, err)
+		}
+
+		if item.Key == "Total" {
+			allocations.Total = size
+			continue
+		}
+
+		allocation := MemAllocation{Function: item.Key, Size: size}
+
+		// the key is "function(line)"
+		if i := strings.LastIndexByte(item.Key, '('); i > 0 && strings.HasSuffix(item.Key, ")") {
+			if line, err := strconv.Atoi(item.Key[i+1 : len(item.Key)-1]); err == nil {
+				allocation.Function = item.Key[:i]
+				allocation.Line = line
+			}
+		}
+
+		allocations.Allocations = append(allocations.Allocations, allocation)
+	}
+
+	return allocations, nil
+}
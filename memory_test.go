@@ -0,0 +1,205 @@
+package binrpc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMemoryStats(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "core.shmmem":
+			return []any{map[string]any{
+				"total":     67108864,
+				"free":      60000000,
+				"used":      6000000,
+				"real_used": 7108864,
+				"max_used":  7200000,
+				"fragments": 12,
+			}}
+		case "pkg.stats":
+			return []any{
+				map[string]any{"entry": 0, "pid": 1001, "rank": 0, "used": 1000, "free": 7000, "desc": "main process"},
+				map[string]any{"entry": 1, "pid": 1002, "rank": 1, "used": 2000, "free": 6000, "desc": "udp receiver"},
+			}
+		}
+
+		return nil
+	})
+
+	shm, err := CoreShmMem(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedShm := ShmMemStats{
+		Total:     67108864,
+		Free:      60000000,
+		Used:      6000000,
+		RealUsed:  7108864,
+		MaxUsed:   7200000,
+		Fragments: 12,
+	}
+
+	if *shm != expectedShm {
+		t.Errorf("expected %+v, got %+v", expectedShm, *shm)
+	}
+
+	pkg, err := PkgStats(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPkg := []PkgMemStats{
+		{Entry: 0, PID: 1001, Rank: 0, Used: 1000, Free: 7000, Description: "main process"},
+		{Entry: 1, PID: 1002, Rank: 1, Used: 2000, Free: 6000, Description: "udp receiver"},
+	}
+
+	if !reflect.DeepEqual(pkg, expectedPkg) {
+		t.Errorf("expected %+v, got %+v", expectedPkg, pkg)
+	}
+}
+
+func TestMemoryStatsLarge(t *testing.T) {
+	// sizes of 2 GiB and more, on 4 bytes
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "core.shmmem":
+			return []any{map[string]any{
+				"total":     0xc0000000,
+				"free":      0x80000000,
+				"used":      0x3f000000,
+				"real_used": 0x90000000,
+				"max_used":  0xa0000000,
+				"fragments": 12,
+			}}
+		case "pkg.stats":
+			return []any{map[string]any{
+				"entry":      0,
+				"pid":        1001,
+				"used":       0x3f000000,
+				"free":       0x80000000,
+				"real_used":  0x90000000,
+				"total_size": 0xffffffff,
+				"desc":       "main process",
+			}}
+		}
+
+		return nil
+	})
+
+	shm, err := CoreShmMem(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedShm := ShmMemStats{
+		Total:     0xc0000000,
+		Free:      0x80000000,
+		Used:      0x3f000000,
+		RealUsed:  0x90000000,
+		MaxUsed:   0xa0000000,
+		Fragments: 12,
+	}
+
+	if *shm != expectedShm {
+		t.Errorf("expected %+v, got %+v", expectedShm, *shm)
+	}
+
+	pkg, err := PkgStats(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPkg := []PkgMemStats{{
+		PID:         1001,
+		Used:        0x3f000000,
+		Free:        0x80000000,
+		RealUsed:    0x90000000,
+		TotalSize:   0xffffffff,
+		Description: "main process",
+	}}
+
+	if !reflect.DeepEqual(pkg, expectedPkg) {
+		t.Errorf("expected %+v, got %+v", expectedPkg, pkg)
+	}
+}
+
+func TestModStats(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{
+			"Module: core",
+			[]StructItem{
+				{Key: "sip_msg_shm_clone(496)", Value: mustRecord(t, 959632)},
+				{Key: "Total", Value: mustRecord(t, 959632)},
+			},
+			[]StructItem{
+				{Key: "counters_prefork_init(207)", Value: mustRecord(t, 61440)},
+				{Key: "cfg_clone_str", Value: mustRecord(t, 16)},
+				{Key: "Total", Value: mustRecord(t, 61456)},
+			},
+			[]StructItem{{Key: "Module", Value: mustRecord(t, "tm")}},
+			[]StructItem{{Key: "Total", Value: mustRecord(t, 0)}},
+			[]StructItem{
+				{Key: "init_hash_table(358)", Value: mustRecord(t, 1048576)},
+				{Key: "Total", Value: mustRecord(t, 1048576)},
+			},
+		}
+	})
+
+	stats, err := ModStats(context.Background(), client, "all", "all")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ModMemStats{
+		{
+			Module: "core",
+			Pkg: &MemAllocations{
+				Total:       959632,
+				Allocations: []MemAllocation{{Function: "sip_msg_shm_clone", Line: 496, Size: 959632}},
+			},
+			Shm: &MemAllocations{
+				Total: 61456,
+				Allocations: []MemAllocation{
+					{Function: "counters_prefork_init", Line: 207, Size: 61440},
+					{Function: "cfg_clone_str", Size: 16},
+				},
+			},
+		},
+		{
+			Module: "tm",
+			Pkg:    &MemAllocations{},
+			Shm: &MemAllocations{
+				Total:       1048576,
+				Allocations: []MemAllocation{{Function: "init_hash_table", Line: 358, Size: 1048576}},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("expected %+v, got %+v", expected, stats)
+	}
+}
+
+func TestParseModStatsError(t *testing.T) {
+	allocations := mustRecord(t, []StructItem{{Key: "Total", Value: mustRecord(t, 0)}})
+
+	if _, err := ParseModStats([]Record{allocations}, "shm"); err == nil {
+		t.Error("error must be returned for allocations without module")
+	}
+
+	if _, err := ParseModStats([]Record{mustRecord(t, "Module: core"), allocations, allocations}, "shm"); err == nil {
+		t.Error("error must be returned for too many allocations")
+	}
+
+	if _, err := ParseModStats(nil, "heap"); err == nil {
+		t.Error("error must be returned for an invalid memory")
+	}
+}
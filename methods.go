@@ -0,0 +1,126 @@
+package binrpc
+
+import (
+	"strings"
+	"sync"
+)
+
+var mutatingMethods = struct {
+	sync.RWMutex
+	m map[string][]string
+}{
+	m: make(map[string][]string),
+}
+
+func init() {
+	for method, invalidates := range map[string][]string{
+		"dispatcher.add":            {"dispatcher."},
+		"dispatcher.remove":         {"dispatcher."},
+		"dispatcher.set_state":      {"dispatcher."},
+		"dispatcher.reload":         {"dispatcher."},
+		"htable.sets":               {"htable."},
+		"htable.seti":               {"htable."},
+		"htable.delete":             {"htable."},
+		"htable.flush":              {"htable."},
+		"htable.reload":             {"htable."},
+		"uac.reg_refresh":           {"uac."},
+		"uac.reg_enable":            {"uac."},
+		"uac.reg_disable":           {"uac."},
+		"permissions.addressReload": {"permissions."},
+		"permissions.trustedReload": {"permissions."},
+	} {
+		RegisterMutating(method, invalidates...)
+	}
+}
+
+// RegisterMutating registers method as a command changing the state of Kamailio. invalidates lists the methods whose
+// replies are stale after method is called: an entry ending with "." matches all the methods of a module
+// (e.g. "dispatcher."), other entries match a method exactly.
+//
+// Common dispatcher, htable, uac and permissions commands are registered by default. Unregistered methods named
+// "<module>.reload" or "<module>.set_state" are considered mutating and invalidate all the methods of their module.
+func RegisterMutating(method string, invalidates ...string) {
+	mutatingMethods.Lock()
+	defer mutatingMethods.Unlock()
+
+	mutatingMethods.m[method] = invalidates
+}
+
+// IsMutating reports whether method changes the state of Kamailio. See RegisterMutating.
+func IsMutating(method string) bool {
+	_, ok := invalidatedBy(method)
+
+	return ok
+}
+
+// invalidatedBy returns the patterns of the methods invalidated by method, and whether method is mutating.
+func invalidatedBy(method string) ([]string, bool) {
+	mutatingMethods.RLock()
+	invalidates, ok := mutatingMethods.m[method]
+	mutatingMethods.RUnlock()
+
+	if ok {
+		return invalidates, true
+	}
+
+	module, name, found := strings.Cut(method, ".")
+
+	if found && (name == "reload" || name == "set_state") {
+		return []string{module + "."}, true
+	}
+
+	return nil, false
+}
+
+var idempotentMethods = struct {
+	sync.RWMutex
+	m map[string]bool
+}{
+	m: make(map[string]bool),
+}
+
+func init() {
+	RegisterIdempotent(
+		"core.echo", "core.version", "core.uptime", "core.info", "core.ps", "core.psx", "core.shmmem",
+		"core.sockets_list", "core.aliases_list", "core.tcp_info", "core.tcp_options", "core.udp4_raw_info",
+		"core.modules", "pkg.stats", "mod.stats",
+		"system.listMethods", "system.methodHelp", "system.methodSignature",
+		"stats.get_statistics", "stats.fetch", "stats.fetchn",
+		"tm.stats", "sl.stats", "dispatcher.list", "ul.dump", "ul.lookup", "dlg.list", "dlg.stats_active", "pike.top",
+		"htable.get", "htable.dump", "htable.listTables", "htable.stats", "uac.reg_dump", "uac.reg_info",
+		"permissions.addressDump", "permissions.trustedDump", "dmq.list_nodes",
+		"tls.info", "tls.list",
+	)
+}
+
+// RegisterIdempotent registers methods as commands that can be sent several times without effect beyond the first
+// call, such as statistics and dumps. Only the calls to idempotent methods are retried automatically, see WithRetry.
+//
+// Common read-only commands of the core and of the kex, stats, tm, sl, dispatcher, usrloc, dialog, pike, htable, uac,
+// permissions, dmq and tls modules are registered by default.
+func RegisterIdempotent(methods ...string) {
+	idempotentMethods.Lock()
+	defer idempotentMethods.Unlock()
+
+	for _, method := range methods {
+		idempotentMethods.m[method] = true
+	}
+}
+
+// IsIdempotent reports whether method is registered as idempotent, and is not mutating. See RegisterIdempotent.
+func IsIdempotent(method string) bool {
+	idempotentMethods.RLock()
+	idempotent := idempotentMethods.m[method]
+	idempotentMethods.RUnlock()
+
+	return idempotent && !IsMutating(method)
+}
+
+// matchMethod reports whether method matches pattern, as described in RegisterMutating.
+func matchMethod(pattern, method string) bool {
+	if strings.HasSuffix(pattern, ".") {
+		return strings.HasPrefix(method, pattern)
+	}
+
+	return pattern == method
+}
@@ -0,0 +1,53 @@
+package binrpc
+
+import "testing"
+
+func TestIsMutating(t *testing.T) {
+	tests := map[string]bool{
+		"dispatcher.reload":    true,
+		"dispatcher.set_state": true,
+		"htable.sets":          true,
+		"permissions.reload":   true,
+		"dispatcher.list":      false,
+		"tm.stats":             false,
+	}
+
+	for method, expected := range tests {
+		if IsMutating(method) != expected {
+			t.Errorf("%s: expected %v", method, expected)
+		}
+	}
+}
+
+func TestRegisterMutating(t *testing.T) {
+	RegisterMutating("test.update", "test.list")
+
+	invalidates, ok := invalidatedBy("test.update")
+
+	if !ok {
+		t.Fatal("test.update must be mutating")
+	}
+
+	if !matchMethod(invalidates[0], "test.list") || matchMethod(invalidates[0], "test.list_all") {
+		t.Errorf("unexpected invalidation patterns %v", invalidates)
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	RegisterIdempotent("test.read")
+
+	tests := map[string]bool{
+		"tm.stats":          true,
+		"dispatcher.list":   true,
+		"test.read":         true,
+		"dispatcher.reload": false,
+		"htable.sets":       false,
+		"app.unknown":       false,
+	}
+
+	for method, expected := range tests {
+		if IsIdempotent(method) != expected {
+			t.Errorf("%s: expected %v", method, expected)
+		}
+	}
+}
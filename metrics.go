@@ -0,0 +1,167 @@
+package binrpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of the latency histograms of MethodStats. Changing them affects
+// the Clients created afterwards.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// MethodStats are the statistics of the calls to a method, see Client.Stats.
+type MethodStats struct {
+	// Calls is the number of calls done, and Errors the number of them that failed, faults included.
+	Calls  uint64
+	Errors uint64
+
+	// Faults is the number of calls that Kamailio replied to with a fault.
+	Faults uint64
+
+	// InFlight is the number of calls in progress.
+	InFlight int
+
+	// Latency is the histogram of the durations of the calls done.
+	Latency LatencyHistogram
+}
+
+// LatencyHistogram is a histogram of durations, in the form expected by Prometheus.
+type LatencyHistogram struct {
+	// Buckets are the upper bounds of the buckets (see LatencyBuckets), and Counts the number of durations less than or
+	// equal to each bound: they are cumulative.
+	Buckets []time.Duration
+	Counts  []uint64
+
+	// Sum is the sum of all the durations, whose number is MethodStats.Calls.
+	Sum time.Duration
+}
+
+// WithStats makes the Client keep statistics of its calls by method, see Client.Stats.
+func WithStats() Option {
+	return func(c *Client) {
+		c.stats = &callStats{
+			buckets: append([]time.Duration(nil), LatencyBuckets...),
+			methods: make(map[string]*methodStats),
+		}
+	}
+}
+
+// Stats returns the statistics of the calls by method: number of calls, errors and faults, calls in progress, and
+// histogram of the latencies. It returns nil unless the Client was created with WithStats.
+func (c *Client) Stats() map[string]MethodStats {
+	return c.stats.snapshot()
+}
+
+// callStats are the statistics of the calls of a Client. Its methods do nothing on a nil *callStats.
+type callStats struct {
+	buckets []time.Duration
+
+	mu      sync.Mutex
+	methods map[string]*methodStats
+}
+
+// methodStats are the statistics of a method, the counts of the histogram not being cumulative, the last one counting
+// the durations above all the buckets.
+type methodStats struct {
+	MethodStats
+
+	counts []uint64
+}
+
+// start records a call to method in progress. done must be called once it returns.
+func (s *callStats) start(method string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.method(method).InFlight++
+}
+
+// done records a call to method that lasted d and returned err.
+func (s *callStats) done(method string, d time.Duration, err error) {
+	if s == nil {
+		return
+	}
+
+	bucket := sort.Search(len(s.buckets), func(i int) bool {
+		return d <= s.buckets[i]
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.method(method)
+	stats.InFlight--
+	stats.Calls++
+	stats.Latency.Sum += d
+	stats.counts[bucket]++
+
+	if err != nil {
+		stats.Errors++
+	}
+
+	if isFault(err) {
+		stats.Faults++
+	}
+}
+
+// method returns the statistics of method, creating them if needed. It must be called with s.mu held.
+func (s *callStats) method(method string) *methodStats {
+	stats, ok := s.methods[method]
+
+	if !ok {
+		stats = &methodStats{
+			counts: make([]uint64, len(s.buckets)+1),
+		}
+
+		s.methods[method] = stats
+	}
+
+	return stats
+}
+
+// snapshot returns a copy of the statistics, with cumulative histograms.
+func (s *callStats) snapshot() map[string]MethodStats {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(s.methods))
+
+	for method, stats := range s.methods {
+		copied := stats.MethodStats
+		copied.Latency.Buckets = append([]time.Duration(nil), s.buckets...)
+		copied.Latency.Counts = make([]uint64, len(s.buckets))
+
+		var count uint64
+
+		for i := range s.buckets {
+			count += stats.counts[i]
+			copied.Latency.Counts[i] = count
+		}
+
+		snapshot[method] = copied
+	}
+
+	return snapshot
+}
@@ -0,0 +1,74 @@
+package binrpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClientStats(t *testing.T) {
+	if stats := serve(t, echo).Stats(); stats != nil {
+		t.Errorf("expected no stats without WithStats, got %v", stats)
+	}
+
+	client := serve(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "core.kill" {
+			return []any{&RPCError{Code: 500, Message: "denied"}}
+		}
+
+		return echo(records)
+	}, WithStats())
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call("core.echo"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.Call("core.kill"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	stats := client.Stats()
+	echoStats := stats["core.echo"]
+
+	if echoStats.Calls != 2 || echoStats.Errors != 0 || echoStats.InFlight != 0 {
+		t.Errorf("unexpected core.echo stats %+v", echoStats)
+	}
+
+	if killStats := stats["core.kill"]; killStats.Calls != 1 || killStats.Errors != 1 || killStats.Faults != 1 {
+		t.Errorf("unexpected core.kill stats %+v", killStats)
+	}
+
+	latency := echoStats.Latency
+
+	if len(latency.Buckets) != len(LatencyBuckets) || len(latency.Counts) != len(LatencyBuckets) {
+		t.Fatalf("expected %d buckets, got %d and %d counts", len(LatencyBuckets), len(latency.Buckets), len(latency.Counts))
+	}
+
+	if last := latency.Counts[len(latency.Counts)-1]; last != 2 {
+		t.Errorf("expected 2 calls in the last bucket, got %d", last)
+	}
+
+	if latency.Sum <= 0 {
+		t.Errorf("expected a positive sum, got %v", latency.Sum)
+	}
+}
+
+func TestCallStatsHistogram(t *testing.T) {
+	stats := &callStats{
+		buckets: []time.Duration{time.Millisecond, time.Second},
+		methods: make(map[string]*methodStats),
+	}
+
+	for _, d := range []time.Duration{time.Microsecond, time.Millisecond, 2 * time.Millisecond, time.Minute} {
+		stats.start("tm.stats")
+		stats.done("tm.stats", d, nil)
+	}
+
+	latency := stats.snapshot()["tm.stats"].Latency
+
+	// the minute is only counted by Sum and Calls, as Prometheus counts it in the +Inf bucket
+	if latency.Counts[0] != 2 || latency.Counts[1] != 3 {
+		t.Errorf("expected counts [2 3], got %v", latency.Counts)
+	}
+}
@@ -0,0 +1,117 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// MultiClient makes the same call on several Kamailio nodes concurrently, for fleets managed from one controller,
+// such as reloading the dispatcher lists everywhere:
+//
+//	multi, err := binrpc.DialMulti([]string{"tcp:10.0.0.1:2049", "tcp:10.0.0.2:2049"}, binrpc.WithTimeout(time.Second))
+//	results, err := multi.CallContext(ctx, "dispatcher.reload")
+//
+// A node failing does not cancel the calls to the others.
+type MultiClient struct {
+	nodes   []string
+	callers map[string]Caller
+}
+
+// NewMultiClient returns a MultiClient calling nodes, keyed by name, such as the address of each node.
+func NewMultiClient(nodes map[string]Caller) *MultiClient {
+	m := &MultiClient{
+		callers: make(map[string]Caller, len(nodes)),
+	}
+
+	for name, caller := range nodes {
+		m.nodes = append(m.nodes, name)
+		m.callers[name] = caller
+	}
+
+	sort.Strings(m.nodes)
+
+	return m
+}
+
+// DialMulti dials the nodes at addresses, parsed by ParseAddress, with options, and returns a MultiClient naming the
+// nodes by address. If a node cannot be dialed, the others are closed and the error is returned. An address listed
+// twice is an error, as the nodes are named by address.
+func DialMulti(addresses []string, options ...Option) (*MultiClient, error) {
+	nodes := make(map[string]Caller, len(addresses))
+	seen := make(map[string]bool, len(addresses))
+
+	for _, address := range addresses {
+		if seen[address] {
+			return nil, fmt.Errorf("duplicate node %s", address)
+		}
+
+		seen[address] = true
+	}
+
+	for _, address := range addresses {
+		client, err := DialAddress(address, options...)
+
+		if err != nil {
+			NewMultiClient(nodes).Close()
+			return nil, fmt.Errorf("node %s: %w", address, err)
+		}
+
+		nodes[address] = client
+	}
+
+	return NewMultiClient(nodes), nil
+}
+
+// Nodes returns the names of the nodes, sorted.
+func (m *MultiClient) Nodes() []string {
+	return append([]string(nil), m.nodes...)
+}
+
+// Call calls method with args on all the nodes concurrently. See CallContext.
+func (m *MultiClient) Call(method string, args ...any) (map[string]CallResult, error) {
+	return m.CallContext(context.Background(), method, args...)
+}
+
+// CallContext calls method with args on all the nodes concurrently, and returns the result of each node, keyed by
+// name. The error joins the errors of the nodes that failed, faults included, each one prefixed with the name of its
+// node: it is nil if all the calls succeeded.
+func (m *MultiClient) CallContext(ctx context.Context, method string, args ...any) (map[string]CallResult, error) {
+	group := NewCallGroup(ctx)
+	group.SetFatal(func(err error) bool {
+		return false
+	})
+
+	for _, name := range m.nodes {
+		group.Go(name, m.callers[name], method, args...)
+	}
+
+	results, _ := group.Wait()
+
+	var errs []error
+
+	for _, name := range m.nodes {
+		if err := results[name].Err; err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", name, err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// Close closes the callers of the nodes that implement io.Closer, such as *Client, and returns the first error.
+func (m *MultiClient) Close() error {
+	var first error
+
+	for _, name := range m.nodes {
+		if closer, ok := m.callers[name].(io.Closer); ok {
+			if err := closer.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
@@ -0,0 +1,65 @@
+package binrpc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMultiClient(t *testing.T) {
+	fault := func(records []Record) []any {
+		return []any{&RPCError{Code: 500, Message: "command not found"}}
+	}
+
+	multi, err := DialMulti([]string{"tcp:" + listen(t, echo), "tcp:" + listen(t, echo)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer multi.Close()
+
+	results, err := multi.Call("core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	for _, node := range multi.Nodes() {
+		if value, _ := results[node].Records[0].String(); value != "core.echo" {
+			t.Errorf(`%s: expected "core.echo", got "%s"`, node, value)
+		}
+	}
+
+	// a node failing does not prevent the others from being called
+	multi = NewMultiClient(map[string]Caller{
+		"edge1": serve(t, echo),
+		"edge2": serve(t, fault),
+	})
+
+	results, err = multi.Call("core.echo")
+
+	var rpcErr *RPCError
+
+	if !errors.As(err, &rpcErr) || !strings.Contains(err.Error(), "node edge2:") {
+		t.Errorf("expected the fault of edge2, got %v", err)
+	}
+
+	if results["edge1"].Err != nil || results["edge2"].Err == nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	if _, err = DialMulti([]string{"tcp:" + listen(t, echo), "sctp:127.0.0.1:1"}); err == nil {
+		t.Error("error must be returned")
+	}
+
+	address := "tcp:" + listen(t, echo)
+
+	if _, err = DialMulti([]string{address, address}); err == nil {
+		t.Error("error must be returned for a duplicate address")
+	}
+}
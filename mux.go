@@ -0,0 +1,299 @@
+package binrpc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// MuxClient is a connection to the ctl module on which several calls are in flight at once. Requests are written as
+// soon as they are made, and replies are matched to their call by cookie as they arrive, instead of the write then read
+// lockstep of a Client. This saves a round trip per call to an exporter issuing many calls per scrape, even though
+// Kamailio executes the requests of a connection one after the other.
+//
+// A MuxClient does not reconnect: once the connection is lost, calls fail and a new MuxClient must be dialed.
+//
+// A MuxClient is safe for concurrent use.
+type MuxClient struct {
+	conn          net.Conn
+	timeout       time.Duration
+	timeouts      Timeouts
+	readerOptions ReaderOptions
+	cookies       CookieSource
+
+	writeMu sync.Mutex
+
+	// mu guards pending, abandoned and err
+	mu      sync.Mutex
+	pending map[uint32]chan muxReply
+	err     error
+
+	// cookies of calls that gave up before their reply was read
+	abandoned []uint32
+
+	// done is closed once the connection is closed and the reading goroutine has returned
+	done chan struct{}
+}
+
+// muxReply is the reply to a call of a MuxClient.
+type muxReply struct {
+	records []Record
+	err     error
+}
+
+// DialMux connects to the ctl module listening on address and returns a MuxClient. See net.Dial for network and
+// address. Only the WithTimeout, WithTimeouts, WithReaderOptions and WithCookieSource options apply to a MuxClient.
+func DialMux(network, address string, options ...Option) (*MuxClient, error) {
+	config := newClient(network, address, nil, options)
+	dialer := net.Dialer{Timeout: config.timeouts.Dial}
+
+	conn, err := dialer.Dial(network, address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newMuxClient(conn, config), nil
+}
+
+// NewMuxClient returns a MuxClient using conn, which must be a stream connection. The MuxClient takes ownership of conn
+// and closes it on Close. See DialMux for the options.
+func NewMuxClient(conn net.Conn, options ...Option) *MuxClient {
+	return newMuxClient(conn, newClient("", "", nil, options))
+}
+
+func newMuxClient(conn net.Conn, config *Client) *MuxClient {
+	m := &MuxClient{
+		conn:          conn,
+		timeout:       config.timeout,
+		timeouts:      config.timeouts,
+		readerOptions: config.readerOptions,
+		cookies:       config.cookies,
+		pending:       make(map[uint32]chan muxReply),
+		done:          make(chan struct{}),
+	}
+
+	go m.read()
+
+	return m
+}
+
+// Close closes the connection. Pending calls fail with net.ErrClosed.
+func (m *MuxClient) Close() error {
+	err := m.conn.Close()
+	<-m.done
+
+	return err
+}
+
+// Call is like Client.Call.
+func (m *MuxClient) Call(method string, args ...any) ([]Record, error) {
+	return m.CallContext(context.Background(), method, args...)
+}
+
+// CallContext is like Client.CallContext, but does not wait for the pending calls to be done to write the request.
+// The cookie of a call is random, unless set by WithCookie or WithCookieSource. As with a Client, a cookie set by
+// WithCookie is not reused until the late reply of a call that gave up on it arrives.
+func (m *MuxClient) CallContext(ctx context.Context, method string, args ...any) ([]Record, error) {
+	cookie, hasCookie := cookieFromContext(ctx)
+	cookie, reply, err := m.register(cookie, hasCookie)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var written bool
+
+	defer func() { m.unregister(cookie, written) }()
+
+	request, err := newRequest(cookie, method, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timeouts := m.timeouts.override(callTimeouts(ctx))
+	deadline, hasDeadline := ctx.Deadline()
+
+	if m.timeout > 0 && (!hasDeadline || time.Now().Add(m.timeout).Before(deadline)) {
+		deadline, hasDeadline = time.Now().Add(m.timeout), true
+	}
+
+	if err = m.write(ctx, request, stepDeadline(deadline, hasDeadline, timeouts.Write)); err != nil {
+		return nil, err
+	}
+
+	written = true
+
+	var expired <-chan time.Time
+
+	if readDeadline := stepDeadline(deadline, hasDeadline, timeouts.Read); !readDeadline.IsZero() {
+		timer := time.NewTimer(time.Until(readDeadline))
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	// a reply arriving after the call is done is discarded by the reading goroutine
+	select {
+	case r := <-reply:
+		return r.records, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-expired:
+		return nil, contextError(ctx, fmt.Errorf("read reply: %w", os.ErrDeadlineExceeded))
+	}
+}
+
+// register returns the cookie of a call and the channel receiving its reply. The cookie comes from m.cookies, unless
+// hasCookie is set, in which case cookie is used if it is not the one of a pending or an abandoned call.
+func (m *MuxClient) register(cookie uint32, hasCookie bool) (uint32, chan muxReply, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return 0, nil, fmt.Errorf("connection unusable: %w", m.err)
+	}
+
+	for !hasCookie {
+		cookie = m.cookies.Cookie()
+
+		if !m.inUse(cookie) {
+			break
+		}
+	}
+
+	if m.inUse(cookie) {
+		return 0, nil, ErrCookieInUse
+	}
+
+	reply := make(chan muxReply, 1)
+	m.pending[cookie] = reply
+
+	return cookie, reply, nil
+}
+
+// unregister removes the call of cookie. If its request was written and its reply was not read yet, cookie is
+// abandoned, so that it is not reused before its reply arrives.
+func (m *MuxClient) unregister(cookie uint32, written bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.pending[cookie]; !ok {
+		return
+	}
+
+	delete(m.pending, cookie)
+
+	if written && m.err == nil {
+		if len(m.abandoned) == maxAbandonedCookies {
+			m.abandoned = m.abandoned[1:]
+		}
+
+		m.abandoned = append(m.abandoned, cookie)
+	}
+}
+
+// inUse reports whether cookie is the one of a pending or an abandoned call. It must be called with m.mu held.
+func (m *MuxClient) inUse(cookie uint32) bool {
+	if _, ok := m.pending[cookie]; ok {
+		return true
+	}
+
+	for _, abandoned := range m.abandoned {
+		if abandoned == cookie {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forget removes cookie from the abandoned calls. It must be called with m.mu held.
+func (m *MuxClient) forget(cookie uint32) {
+	for i, abandoned := range m.abandoned {
+		if abandoned == cookie {
+			m.abandoned = append(m.abandoned[:i], m.abandoned[i+1:]...)
+			return
+		}
+	}
+}
+
+// write writes request before deadline. A request partially written makes the connection unusable, so it is closed.
+func (m *MuxClient) write(ctx context.Context, request *Request, deadline time.Time) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	m.conn.SetWriteDeadline(deadline)
+
+	// only the write is interrupted, the reading goroutine is reading the replies of the other calls
+	stop := watchDeadline(ctx, m.conn.SetWriteDeadline)
+	defer stop()
+
+	if _, err := request.WriteTo(m.conn); err != nil {
+		m.conn.Close()
+		return contextError(ctx, err)
+	}
+
+	return nil
+}
+
+// read reads the replies and passes them to their call, until the connection fails or is closed.
+func (m *MuxClient) read() {
+	defer close(m.done)
+
+	reader := bufio.NewReader(m.conn)
+
+	for {
+		buffer := buffers.get()
+		header, err := readPayloadTo(reader, buffer, m.readerOptions.MaxPayload)
+
+		if err != nil {
+			buffers.put(buffer)
+			m.fail(err)
+			return
+		}
+
+		records, err := decodePayload(header, buffer.Bytes(), m.readerOptions)
+		buffers.put(buffer)
+
+		if err == nil && header.Fault() {
+			records, err = nil, faultError(records)
+		}
+
+		m.mu.Lock()
+		reply, ok := m.pending[header.Cookie]
+		delete(m.pending, header.Cookie)
+
+		// the late reply of a call that gave up: its cookie can be reused
+		if !ok {
+			m.forget(header.Cookie)
+		}
+
+		m.mu.Unlock()
+
+		if ok {
+			reply <- muxReply{records: records, err: err}
+		}
+	}
+}
+
+// fail makes the connection unusable because of err, and fails the pending calls.
+func (m *MuxClient) fail(err error) {
+	m.conn.Close()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.err = err
+	m.abandoned = nil
+
+	for cookie, reply := range m.pending {
+		reply <- muxReply{err: err}
+		delete(m.pending, cookie)
+	}
+}
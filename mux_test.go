@@ -0,0 +1,158 @@
+package binrpc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMuxClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	// the server reads 3 requests before replying to them in reverse order
+	go func() {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+
+		var requests []*Request
+
+		for len(requests) < 3 {
+			request, err := ReadRequest(reader)
+
+			if err != nil {
+				return
+			}
+
+			requests = append(requests, request)
+		}
+
+		for i := len(requests) - 1; i >= 0; i-- {
+			response, _ := NewResponse(requests[i], requests[i].Method)
+			response.WriteTo(conn)
+		}
+	}()
+
+	client, err := DialMux("tcp", listener.Addr().String(), WithTimeout(time.Second))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	var wg sync.WaitGroup
+
+	for _, method := range []string{"tm.stats", "sl.stats", "core.uptime"} {
+		wg.Add(1)
+
+		go func(method string) {
+			defer wg.Done()
+
+			records, err := client.Call(method)
+
+			if err != nil {
+				t.Error(err)
+			} else if value, _ := records[0].String(); value != method {
+				t.Errorf(`expected "%s", got "%s"`, method, value)
+			}
+		}(method)
+	}
+
+	wg.Wait()
+}
+
+func TestMuxClientErrors(t *testing.T) {
+	client, err := DialMux("tcp", listen(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "slow":
+			return nil
+		case "fault":
+			return []any{&RPCError{Code: 500, Message: "failed"}}
+		default:
+			return echo(records)
+		}
+	}), WithTimeout(50*time.Millisecond))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = client.Call("slow"); !isTimeout(err) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	var fault *RPCError
+
+	if _, err = client.Call("fault"); !errors.As(err, &fault) {
+		t.Errorf("expected an *RPCError, got %v", err)
+	}
+
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Error(err)
+	}
+
+	client.Close()
+
+	if _, err = client.Call("core.echo"); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected net.ErrClosed, got %v", err)
+	}
+}
+
+func TestMuxClientAbandonedCookie(t *testing.T) {
+	client, err := DialMux("tcp", listen(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "late" {
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		return echo(records)
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(WithCookie(context.Background(), 42), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err = client.CallContext(ctx, "late"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	ctx = WithCookie(context.Background(), 42)
+
+	// the reply of the call that gave up may still arrive
+	if _, err = client.CallContext(ctx, "core.echo"); !errors.Is(err, ErrCookieInUse) {
+		t.Errorf("expected ErrCookieInUse, got %v", err)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+
+	records, err := client.CallContext(ctx, "core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.echo" {
+		t.Errorf(`expected "core.echo", got "%s"`, value)
+	}
+}
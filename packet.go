@@ -0,0 +1,66 @@
+package binrpc
+
+import (
+	"bytes"
+	"errors"
+)
+
+// Packet is a BINRPC packet held in memory, for callers exchanging packets as byte slices, such as datagrams or
+// messages of a queue, rather than through an io.Reader or an io.Writer.
+type Packet struct {
+	Header  Header
+	Records []Record
+}
+
+// Marshal returns the packet containing records, with a random cookie. Use Packet.MarshalBinary to choose the cookie.
+func Marshal(records ...Record) ([]byte, error) {
+	packet := Packet{
+		Header:  Header{Cookie: RandomCookies.Cookie()},
+		Records: records,
+	}
+
+	return packet.MarshalBinary()
+}
+
+// UnmarshalPacket decodes data, which must contain exactly one packet. Faults are not returned as errors: see
+// Header.Fault.
+func UnmarshalPacket(data []byte) (*Packet, error) {
+	var packet Packet
+
+	if err := packet.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return &packet, nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The packet has the cookie and the flags of p.Header, the length
+// being the one of p.Records once encoded.
+func (p *Packet) MarshalBinary() ([]byte, error) {
+	return appendPacket(nil, p.Header.flags, p.Header.Cookie, p.Records)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. data must contain exactly one packet.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+	header, payload, err := readPayload(reader, 0)
+
+	if err != nil {
+		return err
+	}
+
+	if reader.Len() > 0 {
+		return errors.New("trailing data after the packet")
+	}
+
+	records, err := decodePayload(header, payload, ReaderOptions{})
+
+	if err != nil {
+		return err
+	}
+
+	p.Header = *header
+	p.Records = records
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package binrpc
+
+import (
+	"encoding"
+	"encoding/hex"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*Packet)(nil)
+	_ encoding.BinaryUnmarshaler = (*Packet)(nil)
+)
+
+func TestPacket(t *testing.T) {
+	packet := Packet{
+		Header:  Header{Cookie: 0x1234},
+		Records: []Record{mustRecord(t, "core.echo"), mustRecord(t, 42)},
+	}
+
+	data, err := packet.MarshalBinary()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a1010e1234" + "910a636f72652e6563686f00" + "102a"
+
+	if encoded := hex.EncodeToString(data); encoded != expected {
+		t.Errorf("expected %s, got %s", expected, encoded)
+	}
+
+	decoded, err := UnmarshalPacket(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Header.Cookie != 0x1234 || len(decoded.Records) != 2 {
+		t.Fatalf("unexpected packet %+v", decoded)
+	}
+
+	if value, _ := decoded.Records[1].Int(); value != 42 {
+		t.Errorf("expected 42, got %d", value)
+	}
+
+	if _, err = UnmarshalPacket(append(data, 0x00)); err == nil {
+		t.Error("error must be returned for trailing data")
+	}
+
+	if _, err = UnmarshalPacket(data[:len(data)-1]); err == nil {
+		t.Error("error must be returned for a truncated packet")
+	}
+
+	// a fault keeps its flags
+	fault, _ := hex.DecodeString("a1322a9883af2001f49125636f6d6d616e6420636f72652e6563686f20626f6e6a6f757273206e6f7420666f756e6400")
+
+	if decoded, err = UnmarshalPacket(fault); err != nil || !decoded.Header.Fault() {
+		t.Fatalf("expected a fault, got %+v (%v)", decoded, err)
+	}
+
+	if data, err = decoded.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded, err = UnmarshalPacket(data); err != nil || !decoded.Header.Fault() || decoded.Header.Cookie != 0x9883af {
+		t.Errorf("expected the fault to be encoded again, got %+v (%v)", decoded, err)
+	}
+
+	if data, err = Marshal(mustRecord(t, "core.uptime")); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded, err = UnmarshalPacket(data); err != nil || len(decoded.Records) != 1 {
+		t.Errorf("unexpected packet %+v (%v)", decoded, err)
+	}
+}
@@ -0,0 +1,163 @@
+package binrpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a step of a path: the item of a struct with key, or the element of an array at index. end is the
+// position of the end of the step in the path.
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+	end     int
+}
+
+// GetPath returns the value found at path in record, whose steps are separated by dots: a key selects the first item
+// of a struct with that key (see Get), and an index in brackets selects an element of an array:
+//
+//	uri, err := record.GetPath("RECORDS[0].SET.TARGETS[1].DEST.URI")
+//
+//	fmt.Println(uri.String())
+//
+// Keys are compared with case, and cannot contain dots or brackets. An error is returned if the path is invalid, or
+// if a step is not found, with the part of the path that was found.
+func (record *Record) GetPath(path string) (Record, error) {
+	steps, err := parsePath(path)
+
+	if err != nil {
+		return Record{}, err
+	}
+
+	return record.walkPath(path, steps)
+}
+
+// Query is like Record.GetPath on the first record of a reply. A path starting with an index, such as "[1].uri",
+// selects another record of the reply.
+func Query(records []Record, path string) (Record, error) {
+	steps, err := parsePath(path)
+
+	if err != nil {
+		return Record{}, err
+	}
+
+	if steps[0].isIndex {
+		reply := Record{Type: TypeArray, Value: records}
+
+		return reply.walkPath(path, steps)
+	}
+
+	if len(records) == 0 {
+		return Record{}, errors.New("empty reply")
+	}
+
+	return records[0].walkPath(path, steps)
+}
+
+// walkPath returns the value found by following steps from record. path is used in errors.
+func (record *Record) walkPath(path string, steps []pathStep) (Record, error) {
+	current := *record
+	found := ""
+
+	for _, step := range steps {
+		if step.isIndex {
+			elements, err := current.Array()
+
+			if err != nil {
+				return Record{}, fmt.Errorf("%s: %w", pathPrefix(found), err)
+			}
+
+			if step.index >= len(elements) {
+				return Record{}, fmt.Errorf("%s: index %d out of range (%d elements)", pathPrefix(found), step.index,
+					len(elements))
+			}
+
+			current = elements[step.index]
+		} else {
+			if current.Type != TypeStruct {
+				return Record{}, fmt.Errorf("%s: type error: expected type struct (%d), got %d", pathPrefix(found),
+					TypeStruct, current.Type)
+			}
+
+			value, ok := current.Get(step.key)
+
+			if !ok {
+				return Record{}, fmt.Errorf("%s: no item %s", pathPrefix(found), step.key)
+			}
+
+			current = value
+		}
+
+		found = path[:step.end]
+	}
+
+	return current, nil
+}
+
+// pathPrefix returns how the part of a path found is shown in errors.
+func pathPrefix(found string) string {
+	if found == "" {
+		return "path root"
+	}
+
+	return "path " + found
+}
+
+// parsePath returns the steps of path.
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
+
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+
+			index, err := strconv.Atoi(path[i+1 : i+end])
+
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: invalid index %q", path, path[i+1:i+end])
+			}
+
+			i += end + 1
+			steps = append(steps, pathStep{index: index, isIndex: true, end: i})
+
+			if i < len(path) && path[i] != '.' && path[i] != '[' {
+				return nil, fmt.Errorf("invalid path %q: unexpected %q after index", path, path[i])
+			}
+		} else {
+			end := strings.IndexAny(path[i:], ".[")
+
+			if end < 0 {
+				end = len(path) - i
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+
+			steps = append(steps, pathStep{key: path[i : i+end], end: i + end})
+			i += end
+		}
+
+		// a dot must be followed by a key
+		if i < len(path) && path[i] == '.' {
+			i++
+
+			if i == len(path) || path[i] == '[' {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, errors.New("empty path")
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,78 @@
+package binrpc
+
+import (
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+	str := func(s string) Record { return Record{Type: TypeString, Value: s} }
+	item := func(key string, value Record) StructItem { return StructItem{Key: key, Value: value} }
+	object := func(items ...StructItem) Record { return Record{Type: TypeStruct, Value: items} }
+	array := func(elements ...Record) Record { return Record{Type: TypeArray, Value: elements} }
+
+	// the reply of dispatcher.list
+	record := object(
+		item("NRSETS", Record{Type: TypeInt, Value: 1}),
+		item("RECORDS", array(
+			object(item("SET", object(
+				item("ID", Record{Type: TypeInt, Value: 1}),
+				item("TARGETS", array(
+					object(item("DEST", object(item("URI", str("sip:10.0.0.1"))))),
+					object(item("DEST", object(item("URI", str("sip:10.0.0.2"))))),
+				)),
+			))),
+		)),
+	)
+
+	value, err := record.GetPath("RECORDS[0].SET.TARGETS[1].DEST.URI")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri, _ := value.String(); uri != "sip:10.0.0.2" {
+		t.Errorf(`expected "sip:10.0.0.2", got "%s"`, uri)
+	}
+
+	if value, err = record.GetPath("NRSETS"); err != nil || value.Value != 1 {
+		t.Errorf("expected 1, got %v (%v)", value.Value, err)
+	}
+
+	invalid := map[string]string{
+		"RECORDS[0].SET.TARGETS[2]": "path RECORDS[0].SET.TARGETS: index 2 out of range (2 elements)",
+		"RECORDS[0].SET.NAME":       "path RECORDS[0].SET: no item NAME",
+		"NRSETS[0]":                 "path NRSETS: type error: expected type array (4), got 0",
+		"[0]":                       "path root: type error: expected type array (4), got 3",
+		"":                          "empty path",
+		"RECORDS..SET":              `invalid path "RECORDS..SET": empty key`,
+		"RECORDS.":                  `invalid path "RECORDS.": empty key`,
+		"RECORDS[x]":                `invalid path "RECORDS[x]": invalid index "x"`,
+		"RECORDS[0":                 `invalid path "RECORDS[0": missing ]`,
+		"RECORDS[0]SET":             `invalid path "RECORDS[0]SET": unexpected 'S' after index`,
+	}
+
+	for path, expected := range invalid {
+		if _, err = record.GetPath(path); err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %v", path, expected, err)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	records := []Record{
+		{Type: TypeStruct, Value: []StructItem{{Key: "uptime", Value: Record{Type: TypeInt, Value: 42}}}},
+		{Type: TypeString, Value: "second"},
+	}
+
+	if value, err := Query(records, "uptime"); err != nil || value.Value != 42 {
+		t.Errorf("expected 42, got %v (%v)", value.Value, err)
+	}
+
+	if value, err := Query(records, "[1]"); err != nil || value.Value != "second" {
+		t.Errorf(`expected "second", got %v (%v)`, value.Value, err)
+	}
+
+	if _, err := Query(nil, "uptime"); err == nil {
+		t.Error("error must be returned for an empty reply")
+	}
+}
@@ -0,0 +1,140 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// PermissionsAddress is an entry of the address table of the permissions module, as returned by
+// permissions.addressDump.
+type PermissionsAddress struct {
+	Group int    `binrpc:"gid"`
+	IP    string `binrpc:"ip"`
+
+	// Port is zero if the entry matches any port.
+	Port int `binrpc:"port"`
+
+	// Tag is empty if the entry has no tag.
+	Tag string `binrpc:"tag"`
+}
+
+// PermissionsTrusted is an entry of the trusted table of the permissions module, as returned by
+// permissions.trustedDump.
+type PermissionsTrusted struct {
+	IP string `binrpc:"ip"`
+
+	// Proto is the transport matched, such as "udp" or "any".
+	Proto string `binrpc:"proto"`
+
+	// Pattern and RURIPattern are the regular expressions matching the From URI and the Request URI, empty if any
+	// URI matches.
+	Pattern     string `binrpc:"pattern"`
+	RURIPattern string `binrpc:"ruri_pattern"`
+
+	// Tag is empty if the entry has no tag.
+	Tag      string `binrpc:"tag"`
+	Priority int    `binrpc:"priority"`
+}
+
+// PermissionsAddressDump calls permissions.addressDump with caller and returns the entries of the address table.
+func PermissionsAddressDump(ctx context.Context, caller Caller) ([]PermissionsAddress, error) {
+	records, err := caller.CallContext(ctx, "permissions.addressDump")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []PermissionsAddress
+
+	err = walkPermissions(records, func(path string, record Record) error {
+		var address PermissionsAddress
+
+		if err := record.Scan(&address); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		address.Tag = permissionsString(address.Tag)
+		addresses = append(addresses, address)
+
+		return nil
+	})
+
+	return addresses, err
+}
+
+// PermissionsTrustedDump calls permissions.trustedDump with caller and returns the entries of the trusted table.
+func PermissionsTrustedDump(ctx context.Context, caller Caller) ([]PermissionsTrusted, error) {
+	records, err := caller.CallContext(ctx, "permissions.trustedDump")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []PermissionsTrusted
+
+	err = walkPermissions(records, func(path string, record Record) error {
+		var entry PermissionsTrusted
+
+		if err := record.Scan(&entry); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		entry.Pattern = permissionsString(entry.Pattern)
+		entry.RURIPattern = permissionsString(entry.RURIPattern)
+		entry.Tag = permissionsString(entry.Tag)
+		entries = append(entries, entry)
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// PermissionsAddressReload calls permissions.addressReload with caller to reload the address table from the database,
+// and checks that Kamailio confirms it.
+func PermissionsAddressReload(ctx context.Context, caller Caller) error {
+	records, err := caller.CallContext(ctx, "permissions.addressReload")
+
+	if err != nil {
+		return err
+	}
+
+	return checkOK("permissions.addressReload", records)
+}
+
+// PermissionsTrustedReload calls permissions.trustedReload with caller to reload the trusted table from the database,
+// and checks that Kamailio confirms it.
+func PermissionsTrustedReload(ctx context.Context, caller Caller) error {
+	records, err := caller.CallContext(ctx, "permissions.trustedReload")
+
+	if err != nil {
+		return err
+	}
+
+	return checkOK("permissions.trustedReload", records)
+}
+
+// walkPermissions calls fn for each entry of the reply of a permissions dump: the structs with an "ip" item, found at
+// any depth, as the entries are wrapped in structs by hash slot.
+func walkPermissions(records []Record, fn WalkFunc) error {
+	return Walk(records, func(path string, record Record) error {
+		if _, ok := record.Get("ip"); !ok {
+			return nil
+		}
+
+		if err := fn(path, record); err != nil {
+			return err
+		}
+
+		return SkipRecord
+	})
+}
+
+// permissionsString returns s, or an empty string if s is "NULL", as replied for the unset values.
+func permissionsString(s string) string {
+	if s == "NULL" {
+		return ""
+	}
+
+	return s
+}
@@ -0,0 +1,93 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPermissions(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "permissions.addressDump":
+			return []any{
+				map[string]any{"gid": 1, "ip": "10.0.0.1", "port": 0, "tag": "NULL"},
+				map[string]any{"gid": 2, "ip": "2001:db8::1", "port": 5060, "tag": "carrier"},
+			}
+		case "permissions.trustedDump":
+			return []any{[]StructItem{
+				{Key: "table", Value: mustRecord(t, 12)},
+				{Key: "item", Value: mustRecord(t, map[string]any{
+					"ip": "10.0.1.1", "proto": "any", "pattern": "NULL", "ruri_pattern": "NULL", "tag": "NULL",
+					"priority": 0,
+				})},
+				{Key: "table", Value: mustRecord(t, 12)},
+				{Key: "item", Value: mustRecord(t, map[string]any{
+					"ip": "10.0.1.2", "proto": "udp", "pattern": "^sip:.*@carrier", "ruri_pattern": "NULL",
+					"tag": "carrier", "priority": 10,
+				})},
+			}}
+		case "permissions.addressReload":
+			return []any{"Reload OK"}
+		}
+
+		return []any{&RPCError{Code: 500, Message: "Reload failed."}}
+	})
+
+	ctx := context.Background()
+
+	addresses, err := PermissionsAddressDump(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAddresses := []PermissionsAddress{
+		{Group: 1, IP: "10.0.0.1"},
+		{Group: 2, IP: "2001:db8::1", Port: 5060, Tag: "carrier"},
+	}
+
+	if !reflect.DeepEqual(addresses, expectedAddresses) {
+		t.Errorf("expected %+v, got %+v", expectedAddresses, addresses)
+	}
+
+	trusted, err := PermissionsTrustedDump(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTrusted := []PermissionsTrusted{
+		{IP: "10.0.1.1", Proto: "any"},
+		{IP: "10.0.1.2", Proto: "udp", Pattern: "^sip:.*@carrier", Tag: "carrier", Priority: 10},
+	}
+
+	if !reflect.DeepEqual(trusted, expectedTrusted) {
+		t.Errorf("expected %+v, got %+v", expectedTrusted, trusted)
+	}
+
+	if err = PermissionsAddressReload(ctx, client); err != nil {
+		t.Error(err)
+	}
+
+	var rpcErr *RPCError
+
+	if err = PermissionsTrustedReload(ctx, client); !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+}
+
+func TestCheckOK(t *testing.T) {
+	for _, reply := range []string{"ok", "Reload OK", "OK", "Ok. TLS configuration reloaded."} {
+		if err := checkOK("test", []Record{mustRecord(t, reply)}); err != nil {
+			t.Errorf("%s: %v", reply, err)
+		}
+	}
+
+	for _, reply := range []any{"Reload failed", "broken", "not ok", "ok. not reloaded", "ok reloaded", "okay", 1} {
+		if err := checkOK("test", []Record{mustRecord(t, reply)}); err == nil {
+			t.Errorf("%v: error must be returned", reply)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package binrpc
+
+import (
+	"bytes"
+	"sync"
+)
+
+// PoolLimits bound the memory retained by the buffer pool.
+type PoolLimits struct {
+	// MaxBuffers is the number of buffers retained. Zero disables pooling.
+	MaxBuffers int
+
+	// MaxBufferSize is the capacity, in bytes, above which a buffer is not retained, so that a single large reply
+	// (such as a ul.dump) does not stay in memory.
+	MaxBufferSize int
+}
+
+// PoolStats are statistics of the buffer pool, see BufferPoolStats.
+type PoolStats struct {
+	// Buffers is the number of buffers retained, and Bytes their total capacity.
+	Buffers int
+	Bytes   int
+
+	// Gets is the number of buffers requested, and Hits the number of them served by a retained buffer.
+	Gets uint64
+	Hits uint64
+
+	// Discards is the number of buffers released but not retained, because the pool was full or because they were
+	// larger than MaxBufferSize.
+	Discards uint64
+
+	Limits PoolLimits
+}
+
+// HitRate returns the ratio of buffers requested that were served by a retained buffer, between 0 and 1.
+func (s PoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// bufferPool is a bounded pool of buffers. Unlike a sync.Pool, it keeps its buffers across garbage collections, so
+// that its size is known and bounded.
+type bufferPool struct {
+	mu      sync.Mutex
+	buffers []*bytes.Buffer
+	bytes   int
+	stats   PoolStats
+	limits  PoolLimits
+}
+
+// buffers are the buffers reading the replies of Clients, which are released once the reply is decoded.
+var buffers = &bufferPool{
+	limits: PoolLimits{
+		MaxBuffers:    32,
+		MaxBufferSize: 64 << 10,
+	},
+}
+
+// SetBufferPoolLimits sets the limits of the pool of the buffers reading replies. By default, 32 buffers of at most
+// 64 KB are retained.
+func SetBufferPoolLimits(limits PoolLimits) {
+	buffers.setLimits(limits)
+}
+
+// BufferPoolStats returns the statistics of the pool of the buffers reading replies, to verify that pooling is
+// effective: a low hit rate means that the pool is too small for the concurrency of the calls, and many discards that
+// replies are often larger than MaxBufferSize.
+func BufferPoolStats() PoolStats {
+	return buffers.snapshot()
+}
+
+func (p *bufferPool) get() *bytes.Buffer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Gets++
+
+	n := len(p.buffers)
+
+	if n == 0 {
+		return new(bytes.Buffer)
+	}
+
+	p.stats.Hits++
+
+	buffer := p.buffers[n-1]
+	p.buffers[n-1] = nil
+	p.buffers = p.buffers[:n-1]
+	p.bytes -= buffer.Cap()
+
+	return buffer
+}
+
+// put releases buffer, which must not be used anymore.
+func (p *bufferPool) put(buffer *bytes.Buffer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buffers) >= p.limits.MaxBuffers || buffer.Cap() > p.limits.MaxBufferSize {
+		p.stats.Discards++
+		return
+	}
+
+	buffer.Reset()
+
+	p.buffers = append(p.buffers, buffer)
+	p.bytes += buffer.Cap()
+}
+
+func (p *bufferPool) setLimits(limits PoolLimits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limits = limits
+
+	// drop the buffers exceeding the new limits
+	retained := p.buffers[:0]
+	p.bytes = 0
+
+	for _, buffer := range p.buffers {
+		if len(retained) < limits.MaxBuffers && buffer.Cap() <= limits.MaxBufferSize {
+			retained = append(retained, buffer)
+			p.bytes += buffer.Cap()
+		}
+	}
+
+	clear(p.buffers[len(retained):])
+	p.buffers = retained
+}
+
+func (p *bufferPool) snapshot() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Buffers = len(p.buffers)
+	stats.Bytes = p.bytes
+	stats.Limits = p.limits
+
+	return stats
+}
@@ -0,0 +1,57 @@
+package binrpc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBufferPool(t *testing.T) {
+	pool := &bufferPool{limits: PoolLimits{MaxBuffers: 1, MaxBufferSize: 1024}}
+
+	small := pool.get()
+	small.Write(make([]byte, 100))
+
+	large := pool.get()
+	large.Write(make([]byte, 2048))
+
+	pool.put(small)
+	pool.put(large)
+
+	if reused := pool.get(); reused != small || reused.Len() != 0 {
+		t.Error("expected the small buffer to be reused and reset")
+	}
+
+	stats := pool.snapshot()
+
+	if stats.Gets != 3 || stats.Hits != 1 || stats.Discards != 1 || stats.Buffers != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	if rate := stats.HitRate(); rate != 1.0/3 {
+		t.Errorf("expected a hit rate of 1/3, got %f", rate)
+	}
+
+	pool.put(small)
+	pool.setLimits(PoolLimits{MaxBuffers: 1, MaxBufferSize: 64})
+
+	if stats = pool.snapshot(); stats.Buffers != 0 || stats.Bytes != 0 {
+		t.Errorf("expected the buffers exceeding the new limits to be dropped, got %+v", stats)
+	}
+}
+
+func TestBufferPoolStats(t *testing.T) {
+	client := serve(t, echo)
+	before := BufferPoolStats()
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.Call("core.echo", string(bytes.Repeat([]byte("x"), 1000))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	after := BufferPoolStats()
+
+	if after.Gets-before.Gets != 3 || after.Hits == before.Hits {
+		t.Errorf("expected the buffers of the replies to be pooled, got %+v then %+v", before, after)
+	}
+}
@@ -0,0 +1,106 @@
+package binrpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Fprint writes records to w like kamcmd does, one record after the other: structs and arrays are written on several
+// lines, their items and elements being indented with tabs, and the values of the items of a struct are aligned:
+//
+//	{
+//		current:     3
+//		total_local: 12
+//		shmem: {
+//			used: 1024
+//		}
+//	}
+//
+// Package format has other output formats, such as JSON and YAML.
+func Fprint(w io.Writer, records []Record) error {
+	var buffer bytes.Buffer
+
+	for i := range records {
+		appendText(&buffer, &records[i], "")
+		buffer.WriteByte('\n')
+	}
+
+	_, err := w.Write(buffer.Bytes())
+
+	return err
+}
+
+// Format implements fmt.Formatter: the %v and %s verbs write the record like Fprint, for debugging. Other verbs and
+// flags, such as %#v, write the Record struct as usual.
+func (record Record) Format(f fmt.State, verb rune) {
+	if (verb != 'v' && verb != 's') || f.Flag('#') || f.Flag('+') {
+		// a type without the Format method, so that fmt formats the fields
+		type plain Record
+
+		fmt.Fprintf(f, fmt.FormatString(f, verb), plain(record))
+		return
+	}
+
+	var buffer bytes.Buffer
+
+	appendText(&buffer, &record, "")
+	f.Write(buffer.Bytes())
+}
+
+// appendText writes record to buffer, the first line being already indented.
+func appendText(buffer *bytes.Buffer, record *Record, indent string) {
+	switch value := record.Value.(type) {
+	case []StructItem:
+		width := 0
+
+		for _, item := range value {
+			if !isContainer(item.Value) {
+				width = max(width, len(item.Key))
+			}
+		}
+
+		buffer.WriteString("{\n")
+
+		for i := range value {
+			item := &value[i]
+
+			buffer.WriteString(indent + "\t" + item.Key + ":")
+
+			// nested structs and arrays start on the line of their key, and are not aligned
+			if isContainer(item.Value) {
+				buffer.WriteByte(' ')
+			} else {
+				buffer.WriteString(strings.Repeat(" ", width-len(item.Key)+1))
+			}
+
+			appendText(buffer, &item.Value, indent+"\t")
+			buffer.WriteByte('\n')
+		}
+
+		buffer.WriteString(indent + "}")
+	case []Record:
+		buffer.WriteString("[\n")
+
+		for i := range value {
+			buffer.WriteString(indent + "\t")
+			appendText(buffer, &value[i], indent+"\t")
+			buffer.WriteByte('\n')
+		}
+
+		buffer.WriteString(indent + "]")
+	case float64:
+		buffer.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	case []byte:
+		buffer.Write(value)
+	default:
+		fmt.Fprint(buffer, value)
+	}
+}
+
+// isContainer reports whether record is a struct or an array.
+func isContainer(record Record) bool {
+	return record.Type == TypeStruct || record.Type == TypeArray
+}
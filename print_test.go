@@ -0,0 +1,59 @@
+package binrpc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFprint(t *testing.T) {
+	records := []Record{
+		{Type: TypeStruct, Value: []StructItem{
+			{Key: "current", Value: Record{Type: TypeInt, Value: 3}},
+			{Key: "total_local", Value: Record{Type: TypeInt, Value: 12}},
+			{Key: "load", Value: Record{Type: TypeDouble, Value: 0.5}},
+			{Key: "shmem", Value: Record{Type: TypeStruct, Value: []StructItem{
+				{Key: "used", Value: Record{Type: TypeBytes, Value: []byte("1024")}},
+			}}},
+			{Key: "sets", Value: Record{Type: TypeArray, Value: []Record{
+				{Type: TypeString, Value: "a"},
+				{Type: TypeString, Value: "b"},
+			}}},
+		}},
+		{Type: TypeString, Value: "second"},
+	}
+
+	var buffer bytes.Buffer
+
+	if err := Fprint(&buffer, records); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n" +
+		"\tcurrent:     3\n" +
+		"\ttotal_local: 12\n" +
+		"\tload:        0.5\n" +
+		"\tshmem: {\n" +
+		"\t\tused: 1024\n" +
+		"\t}\n" +
+		"\tsets: [\n" +
+		"\t\ta\n" +
+		"\t\tb\n" +
+		"\t]\n" +
+		"}\n" +
+		"second\n"
+
+	if buffer.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buffer.String())
+	}
+
+	if s := fmt.Sprint(records[0].Value.([]StructItem)[3].Value); s != "{\n\tused: 1024\n}" {
+		t.Errorf("unexpected %q", s)
+	}
+
+	// other verbs format the fields
+	if s := fmt.Sprintf("%+v", records[1]); !strings.HasSuffix(s, "Type:1 Value:second}") {
+		t.Errorf("expected the fields, got %q", s)
+	}
+}
@@ -0,0 +1,90 @@
+package binrpc
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateTracker computes per-second rates of counters from successive replies of a command, such as tm.stats.
+// It is safe for concurrent use.
+type RateTracker struct {
+	mu       sync.Mutex
+	previous map[string]float64
+	at       time.Time
+}
+
+// NewRateTracker returns a RateTracker without snapshot.
+func NewRateTracker() *RateTracker {
+	return &RateTracker{}
+}
+
+// Update records the numeric values of records, as a snapshot taken at time at, and returns the per-second rate of each
+// value since the previous snapshot. The first call returns no rates.
+//
+// Values are keyed by path: the keys of nested structs are joined with ".", and the items of top-level structs are not
+// prefixed (e.g. "2xx" for tm.stats). Top-level values that are not structs are keyed by their index ("0", "1", ...).
+//
+// A value lower than in the previous snapshot is a counter reset: the counter is assumed to have restarted from zero.
+func (t *RateTracker) Update(at time.Time, records []Record) map[string]float64 {
+	values := make(map[string]float64)
+
+	for i, record := range records {
+		if record.Type == TypeStruct {
+			flattenNumbers(values, "", record)
+		} else {
+			flattenNumbers(values, strconv.Itoa(i), record)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous, previousAt := t.previous, t.at
+	t.previous, t.at = values, at
+
+	elapsed := at.Sub(previousAt).Seconds()
+
+	if previous == nil || elapsed <= 0 {
+		return nil
+	}
+
+	rates := make(map[string]float64, len(values))
+
+	for key, value := range values {
+		last, ok := previous[key]
+
+		if !ok {
+			continue
+		}
+
+		if value < last {
+			// counter reset
+			last = 0
+		}
+
+		rates[key] = (value - last) / elapsed
+	}
+
+	return rates
+}
+
+// flattenNumbers adds the int and double values of record to values, keyed by path.
+func flattenNumbers(values map[string]float64, path string, record Record) {
+	switch record.Type {
+	case TypeInt:
+		values[path] = float64(record.Value.(int))
+	case TypeDouble:
+		values[path] = record.Value.(float64)
+	case TypeStruct:
+		for _, item := range record.Value.([]StructItem) {
+			key := item.Key
+
+			if path != "" {
+				key = path + "." + key
+			}
+
+			flattenNumbers(values, key, item.Value)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package binrpc
+
+import (
+	"testing"
+	"time"
+)
+
+func statsRecord(values ...any) []Record {
+	var items []StructItem
+
+	for i := 0; i < len(values); i += 2 {
+		items = append(items, StructItem{
+			Key:   values[i].(string),
+			Value: Record{Type: TypeInt, Value: values[i+1].(int)},
+		})
+	}
+
+	return []Record{{Type: TypeStruct, Value: items}}
+}
+
+func TestRateTracker(t *testing.T) {
+	tracker := NewRateTracker()
+	start := time.Now()
+
+	if rates := tracker.Update(start, statsRecord("total", 100, "2xx", 50)); rates != nil {
+		t.Errorf("expected no rates for the first snapshot, got %v", rates)
+	}
+
+	rates := tracker.Update(start.Add(10*time.Second), statsRecord("total", 300, "2xx", 20, "6xx", 1))
+
+	if rates["total"] != 20 {
+		t.Errorf("expected total rate of 20, got %v", rates["total"])
+	}
+
+	// counter reset, 2xx restarted from zero
+	if rates["2xx"] != 2 {
+		t.Errorf("expected 2xx rate of 2, got %v", rates["2xx"])
+	}
+
+	if _, ok := rates["6xx"]; ok {
+		t.Error("6xx must not have a rate without previous value")
+	}
+}
+
+func TestFlattenNumbers(t *testing.T) {
+	values := make(map[string]float64)
+	record := Record{Type: TypeStruct, Value: []StructItem{
+		{Key: "shm", Value: statsRecord("used", 10)[0]},
+		{Key: "load", Value: Record{Type: TypeDouble, Value: 0.5}},
+		{Key: "name", Value: Record{Type: TypeString, Value: "main"}},
+	}}
+
+	flattenNumbers(values, "", record)
+
+	if len(values) != 2 || values["shm.used"] != 10 || values["load"] != 0.5 {
+		t.Errorf("unexpected values %v", values)
+	}
+}
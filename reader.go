@@ -0,0 +1,186 @@
+package binrpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// errorSnippetSize is the maximum number of bytes in DecodeError.Snippet.
+const errorSnippetSize = 16
+
+// ReaderOptions controls the decoding of packets. The zero value is the default behavior.
+type ReaderOptions struct {
+	// RecordOffsets records the position of each record within the packet (see Record.Offset), and makes decoding
+	// errors carry the position of the faulty record (see DecodeError).
+	RecordOffsets bool
+
+	// ErrorSnippets makes decoding errors carry the position of the faulty record and its first bytes,
+	// see DecodeError.
+	ErrorSnippets bool
+
+	// MaxPayload is the maximum payload length of a packet, checked before the payload is read. Zero means no limit.
+	// As the payload of a packet exceeding the limit is not consumed, the connection it was read from cannot be used
+	// anymore.
+	MaxPayload int
+
+	// MaxDepth is the maximum nesting depth of structs and arrays, a struct of the top level having depth 1.
+	// Zero means DefaultMaxDepth: as structs and arrays are decoded recursively, the depth is always limited.
+	MaxDepth int
+
+	// MaxRecords is the maximum number of records of a packet, the ones nested in structs and arrays included.
+	// Zero means no limit.
+	MaxRecords int
+}
+
+// DefaultMaxDepth is the maximum nesting depth of structs and arrays when ReaderOptions.MaxDepth is zero, far above
+// the depth of the replies of Kamailio.
+const DefaultMaxDepth = 100
+
+// ErrLimitExceeded is returned when a packet exceeds a limit of ReaderOptions.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// DecodeError is returned when a record cannot be decoded, if ReaderOptions.RecordOffsets or
+// ReaderOptions.ErrorSnippets is set.
+type DecodeError struct {
+	// Offset is the position of the faulty record within the packet, header included.
+	Offset int
+
+	// Snippet contains the first bytes of the faulty record (at most 16), if ReaderOptions.ErrorSnippets is set.
+	Snippet []byte
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Snippet == nil {
+		return fmt.Sprintf("record at offset %d: %v", e.Offset, e.Err)
+	}
+
+	return fmt.Sprintf("record at offset %d [% x]: %v", e.Offset, e.Snippet, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeState is shared by the records decoded from the same payload.
+type decodeState struct {
+	options ReaderOptions
+	payload *bytes.Reader
+	data    []byte
+
+	// position of the payload within the packet
+	base int
+
+	// depth of the struct or array being decoded, and number of records decoded
+	depth   int
+	records int
+
+	// scratch holds the bytes of the record being decoded, see buffer
+	scratch []byte
+}
+
+// reset prepares state to decode payload, keeping its options and its scratch buffer.
+func (state *decodeState) reset(header *Header, payload []byte) {
+	if state.payload == nil {
+		state.payload = bytes.NewReader(payload)
+	} else {
+		state.payload.Reset(payload)
+	}
+
+	state.data = payload
+	state.base = header.size
+	state.depth = 0
+	state.records = 0
+}
+
+// buffer returns a buffer of n bytes, which is reused by the next records decoded with state: values must be copied
+// out of it.
+func (state *decodeState) buffer(n int) []byte {
+	if state == nil {
+		return make([]byte, n)
+	}
+
+	if cap(state.scratch) < n {
+		state.scratch = make([]byte, n)
+	}
+
+	return state.scratch[:n]
+}
+
+// count counts a record decoded, and returns an error if there are too many.
+func (state *decodeState) count() error {
+	if state == nil {
+		return nil
+	}
+
+	state.records++
+
+	if limit := state.options.MaxRecords; limit > 0 && state.records > limit {
+		return fmt.Errorf("%w: more than %d records", ErrLimitExceeded, limit)
+	}
+
+	return nil
+}
+
+// enter is called when a struct or an array is decoded, and returns an error if it is nested too deep. leave must be
+// called once it is decoded.
+func (state *decodeState) enter() error {
+	if state == nil {
+		return nil
+	}
+
+	state.depth++
+
+	limit := state.options.MaxDepth
+
+	if limit <= 0 {
+		limit = DefaultMaxDepth
+	}
+
+	if state.depth > limit {
+		return fmt.Errorf("%w: nesting deeper than %d", ErrLimitExceeded, limit)
+	}
+
+	return nil
+}
+
+func (state *decodeState) leave() {
+	if state != nil {
+		state.depth--
+	}
+}
+
+// offset returns the position within the packet of the next byte to decode, or -1 if positions are not tracked.
+func (state *decodeState) offset() int {
+	if state == nil || !(state.options.RecordOffsets || state.options.ErrorSnippets) {
+		return -1
+	}
+
+	return state.base + int(state.payload.Size()) - state.payload.Len()
+}
+
+// wrap adds the position of the record starting at offset to err, unless err already has a position.
+func (state *decodeState) wrap(offset int, err error) error {
+	var decodeErr *DecodeError
+
+	if offset < 0 || err == errEndOfStruct || err == errEndOfArray || errors.As(err, &decodeErr) {
+		return err
+	}
+
+	decodeErr = &DecodeError{
+		Offset: offset,
+		Err:    err,
+	}
+
+	if state.options.ErrorSnippets {
+		start := offset - state.base
+		end := min(start+errorSnippetSize, len(state.data))
+
+		// the payload may be reused once decoded
+		decodeErr.Snippet = bytes.Clone(state.data[start:end])
+	}
+
+	return decodeErr
+}
@@ -0,0 +1,170 @@
+package binrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestRecordOffsets(t *testing.T) {
+	var buffer bytes.Buffer
+
+	records, _ := createRecords([]any{"a", 42})
+
+	if err := writePacket(&buffer, 0, 0x12, records); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := ReadPacketWithOptions(&buffer, 0x12, ReaderOptions{RecordOffsets: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]int{{4, 3}, {7, 2}}
+
+	for i, record := range response {
+		offset, length, ok := record.Offset()
+
+		if !ok {
+			t.Fatalf("offset of record %d not recorded", i)
+		}
+
+		if offset != expected[i][0] || length != expected[i][1] {
+			t.Errorf("record %d: expected offset %d and length %d, got %d and %d", i, expected[i][0], expected[i][1], offset, length)
+		}
+	}
+}
+
+func TestRecordOffsetsDisabled(t *testing.T) {
+	data, _ := hex.DecodeString("a1322a9883af2001f49125636f6d6d616e6420636f72652e6563686f20626f6e6a6f757273206e6f7420666f756e6400")
+
+	// a fault reply: the records are returned with the *RPCError
+	response, err := ReadPacket(bytes.NewReader(data), 0x9883af)
+
+	if len(response) == 0 {
+		t.Fatal(err)
+	}
+
+	if _, _, ok := response[0].Offset(); ok {
+		t.Error("offset must not be recorded")
+	}
+}
+
+func TestDecodeErrorOffset(t *testing.T) {
+	// a struct containing an int instead of an avp
+	data, _ := hex.DecodeString("a1000312" + "03102a")
+
+	_, err := ReadPacketWithOptions(bytes.NewReader(data), 0x12, ReaderOptions{RecordOffsets: true})
+
+	var decodeErr *DecodeError
+
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a *DecodeError, got %v", err)
+	}
+
+	if decodeErr.Offset != 4 {
+		t.Errorf("expected offset 4, got %d", decodeErr.Offset)
+	}
+}
+
+func TestDecodeErrorSnippet(t *testing.T) {
+	// a struct containing an int instead of an avp
+	data, _ := hex.DecodeString("a1000312" + "03102a")
+
+	response, err := ReadPacketWithOptions(bytes.NewReader(data), 0x12, ReaderOptions{ErrorSnippets: true})
+
+	if response != nil {
+		t.Error("response must be nil")
+	}
+
+	var decodeErr *DecodeError
+
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a *DecodeError, got %v", err)
+	}
+
+	if expected := []byte{0x03, 0x10, 0x2a}; !bytes.Equal(decodeErr.Snippet, expected) {
+		t.Errorf("expected snippet %x, got %x", expected, decodeErr.Snippet)
+	}
+
+	if expected := "record at offset 4 [03 10 2a]: struct contains something else than avp: 0"; err.Error() != expected {
+		t.Errorf(`expected error "%s", got "%s"`, expected, err.Error())
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	// an array containing an array containing an int
+	nested := []any{[]any{1}}
+
+	var buffer bytes.Buffer
+
+	records, _ := createRecords([]any{nested, "a"})
+
+	if err := writePacket(&buffer, 0, 0x12, records); err != nil {
+		t.Fatal(err)
+	}
+
+	packet := buffer.Bytes()
+
+	tests := []struct {
+		options ReaderOptions
+		fail    bool
+	}{
+		{ReaderOptions{}, false},
+		{ReaderOptions{MaxPayload: len(packet) - 4}, false},
+		{ReaderOptions{MaxPayload: len(packet) - 5}, true},
+		{ReaderOptions{MaxDepth: 2}, false},
+		{ReaderOptions{MaxDepth: 1}, true},
+		{ReaderOptions{MaxRecords: 4}, false},
+		{ReaderOptions{MaxRecords: 3}, true},
+	}
+
+	for _, test := range tests {
+		_, err := ReadPacketWithOptions(bytes.NewReader(packet), 0x12, test.options)
+
+		if test.fail && !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%+v: expected ErrLimitExceeded, got %v", test.options, err)
+		}
+
+		if !test.fail && err != nil {
+			t.Errorf("%+v: %v", test.options, err)
+		}
+	}
+}
+
+func TestReaderDefaultMaxDepth(t *testing.T) {
+	// arrays nested depth times
+	nested := func(depth int) []byte {
+		var value any = 1
+
+		for i := 0; i < depth; i++ {
+			value = []any{value}
+		}
+
+		var buffer bytes.Buffer
+
+		records, _ := createRecords([]any{value})
+
+		if err := writePacket(&buffer, 0, 0x12, records); err != nil {
+			t.Fatal(err)
+		}
+
+		return buffer.Bytes()
+	}
+
+	if _, err := ReadPacket(bytes.NewReader(nested(DefaultMaxDepth)), 0x12); err != nil {
+		t.Errorf("depth %d: %v", DefaultMaxDepth, err)
+	}
+
+	if _, err := ReadPacket(bytes.NewReader(nested(DefaultMaxDepth+1)), 0x12); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("depth %d: expected ErrLimitExceeded, got %v", DefaultMaxDepth+1, err)
+	}
+
+	options := ReaderOptions{MaxDepth: DefaultMaxDepth + 1}
+
+	if _, err := ReadPacketWithOptions(bytes.NewReader(nested(DefaultMaxDepth+1)), 0x12, options); err != nil {
+		t.Errorf("depth %d with MaxDepth %d: %v", DefaultMaxDepth+1, options.MaxDepth, err)
+	}
+}
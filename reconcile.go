@@ -0,0 +1,215 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Flags of dispatcher.add, setting the initial state of a destination.
+const (
+	dispatcherFlagInactive = 1
+	dispatcherFlagTrying   = 2
+	dispatcherFlagDisabled = 4
+	dispatcherFlagProbing  = 8
+)
+
+// DispatcherChange is a call changing the destinations of the dispatcher module, planned by Dispatcher.Plan.
+type DispatcherChange struct {
+	// Method is dispatcher.add, dispatcher.remove or dispatcher.set_state.
+	Method string
+	Set    int
+	URI    string
+
+	// Args is the args of the call, the set and the URI included.
+	Args []any
+
+	// Applied is set once the call succeeded, see Dispatcher.Reconcile.
+	Applied bool
+}
+
+// String returns the change as a command line, such as "dispatcher.set_state ip 1 sip:10.0.0.1:5060".
+func (change DispatcherChange) String() string {
+	var b strings.Builder
+
+	b.WriteString(change.Method)
+
+	for _, arg := range change.Args {
+		fmt.Fprintf(&b, " %v", arg)
+	}
+
+	return b.String()
+}
+
+// Plan returns the changes that would make the destinations of the dispatcher module match desired, without applying
+// them, for a dry run. See Reconcile.
+func (ds *Dispatcher) Plan(ctx context.Context, desired []DispatcherSet) ([]DispatcherChange, error) {
+	current, err := ds.List(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return planDispatcher(current, desired)
+}
+
+// Reconcile makes the destinations of the dispatcher module match desired, calling only the commands needed, and
+// returns the changes planned:
+//
+//	changes, err := ds.Reconcile(ctx, []binrpc.DispatcherSet{
+//		{ID: 1, Destinations: []binrpc.DispatcherDestination{
+//			{URI: "sip:10.0.0.1:5060", Flags: "AP"},
+//			{URI: "sip:10.0.0.2:5060", Flags: "IP"},
+//		}},
+//	})
+//
+// desired is the complete list of the destinations: the destinations missing from it are removed. A destination is
+// matched by its set and URI. If its priority or its attributes differ, it is removed and added again. If its Flags
+// differ, in state (A, I, D or T) or in probing (P), whatever their case, its state is set. Empty Flags leave the
+// state of a destination as is, and add it active. Other Flags, such as "P" without a state, are an error.
+//
+// The new destinations are added first, and the destinations missing from desired removed last, so that a set is
+// not left empty while its destinations are replaced. The destinations whose priority or attributes differ are
+// replaced next, each one being removed then added: if the add fails, the destination is left removed until
+// Reconcile is called again. If a call fails, Reconcile stops and returns its error: the changes applied before are
+// marked Applied.
+//
+// As for Add and Remove, the changes are lost on the next reload of the dispatcher lists.
+func (ds *Dispatcher) Reconcile(ctx context.Context, desired []DispatcherSet) ([]DispatcherChange, error) {
+	changes, err := ds.Plan(ctx, desired)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range changes {
+		if err = ds.call(ctx, changes[i].Method, changes[i].Args...); err != nil {
+			return changes, fmt.Errorf("%s: %w", changes[i], err)
+		}
+
+		changes[i].Applied = true
+	}
+
+	return changes, nil
+}
+
+// dispatcherKey identifies a destination.
+type dispatcherKey struct {
+	set int
+	uri string
+}
+
+// planDispatcher returns the changes turning the current destinations into the desired ones: adds, replacements,
+// states, then removals.
+func planDispatcher(current, desired []DispatcherSet) ([]DispatcherChange, error) {
+	existing := make(map[dispatcherKey]DispatcherDestination)
+	wanted := make(map[dispatcherKey]bool)
+
+	for _, set := range current {
+		for _, destination := range set.Destinations {
+			existing[dispatcherKey{set.ID, destination.URI}] = destination
+		}
+	}
+
+	var adds, replacements, states, removals []DispatcherChange
+
+	for _, set := range desired {
+		for _, destination := range set.Destinations {
+			key := dispatcherKey{set.ID, destination.URI}
+			wanted[key] = true
+			destination.Flags = strings.ToUpper(destination.Flags)
+
+			if !validDispatcherFlags(destination.Flags) {
+				return nil, fmt.Errorf("set %d: %s: invalid flags %q", set.ID, destination.URI, destination.Flags)
+			}
+
+			old, ok := existing[key]
+
+			switch {
+			case !ok:
+				adds = append(adds, addChange(set.ID, destination))
+			case old.Priority != destination.Priority || old.Attrs != destination.Attrs:
+				replacements = append(replacements, removeChange(set.ID, old.URI), addChange(set.ID, destination))
+			case destination.Flags != "" && !sameDispatcherState(old.Flags, destination.Flags):
+				state := strings.ToLower(destination.Flags[:1])
+
+				if destination.Probing() {
+					state += DispatcherProbing
+				}
+
+				states = append(states, DispatcherChange{
+					Method: "dispatcher.set_state",
+					Set:    set.ID,
+					URI:    destination.URI,
+					Args:   []any{state, set.ID, destination.URI},
+				})
+			}
+		}
+	}
+
+	for _, set := range current {
+		for _, destination := range set.Destinations {
+			if !wanted[dispatcherKey{set.ID, destination.URI}] {
+				removals = append(removals, removeChange(set.ID, destination.URI))
+			}
+		}
+	}
+
+	changes := append(adds, replacements...)
+	changes = append(changes, states...)
+
+	return append(changes, removals...), nil
+}
+
+// validDispatcherFlags reports whether flags, in upper case, are empty or a state (A, I, D or T) followed by P or X,
+// or by nothing.
+func validDispatcherFlags(flags string) bool {
+	switch {
+	case flags == "":
+		return true
+	case len(flags) > 2 || !strings.Contains("AIDT", flags[:1]):
+		return false
+	default:
+		return len(flags) == 1 || flags[1] == 'P' || flags[1] == 'X'
+	}
+}
+
+// addChange returns the change adding destination to set, in the state of its flags.
+func addChange(set int, destination DispatcherDestination) DispatcherChange {
+	flags := 0
+
+	switch {
+	case strings.HasPrefix(destination.Flags, "I"):
+		flags = dispatcherFlagInactive
+	case strings.HasPrefix(destination.Flags, "T"):
+		flags = dispatcherFlagTrying
+	case strings.HasPrefix(destination.Flags, "D"):
+		flags = dispatcherFlagDisabled
+	}
+
+	if destination.Probing() {
+		flags |= dispatcherFlagProbing
+	}
+
+	return DispatcherChange{
+		Method: "dispatcher.add",
+		Set:    set,
+		URI:    destination.URI,
+		Args:   []any{set, destination.URI, flags, destination.Priority, destination.Attrs},
+	}
+}
+
+// removeChange returns the change removing uri from set.
+func removeChange(set int, uri string) DispatcherChange {
+	return DispatcherChange{
+		Method: "dispatcher.remove",
+		Set:    set,
+		URI:    uri,
+		Args:   []any{set, uri},
+	}
+}
+
+// sameDispatcherState reports whether the flags a and b have the same state and probing.
+func sameDispatcherState(a, b string) bool {
+	return len(a) > 0 && len(b) > 0 && a[0] == b[0] && strings.Contains(a, "P") == strings.Contains(b, "P")
+}
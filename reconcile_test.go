@@ -0,0 +1,147 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDispatcherReconcile(t *testing.T) {
+	fake := &fakeDispatcher{sets: []DispatcherSet{
+		{ID: 1, Destinations: []DispatcherDestination{
+			{URI: "sip:10.0.0.1:5060", Flags: "AP", Priority: 5},
+			{URI: "sip:10.0.0.2:5060", Flags: "AP"},
+			{URI: "sip:10.0.0.3:5060", Flags: "AX", Attrs: "weight=10"},
+		}},
+		{ID: 2, Destinations: []DispatcherDestination{
+			{URI: "sip:10.0.1.1:5060", Flags: "AX"},
+		}},
+	}}
+
+	ds := serveDispatcher(t, fake)
+
+	desired := []DispatcherSet{
+		{ID: 1, Destinations: []DispatcherDestination{
+			// unchanged, state left as is
+			{URI: "sip:10.0.0.1:5060", Priority: 5},
+			// put in maintenance
+			{URI: "sip:10.0.0.2:5060", Flags: "ip"},
+			// new weight
+			{URI: "sip:10.0.0.3:5060", Flags: "AX", Attrs: "weight=50"},
+			// new destination
+			{URI: "sip:10.0.0.4:5060", Flags: "AP", Priority: 1},
+		}},
+	}
+
+	plan, err := ds.Plan(context.Background(), desired)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"dispatcher.add 1 sip:10.0.0.4:5060 8 1 ",
+		"dispatcher.remove 1 sip:10.0.0.3:5060",
+		"dispatcher.add 1 sip:10.0.0.3:5060 0 0 weight=50",
+		"dispatcher.set_state ip 1 sip:10.0.0.2:5060",
+		"dispatcher.remove 2 sip:10.0.1.1:5060",
+	}
+
+	if got := changeStrings(plan); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	fake.mu.Lock()
+
+	if len(fake.calls) != 0 {
+		t.Errorf("Plan must not change the destinations, got %q", fake.calls)
+	}
+
+	fake.mu.Unlock()
+
+	changes, err := ds.Reconcile(context.Background(), desired)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, change := range changes {
+		if !change.Applied {
+			t.Errorf("expected %s to be applied", change)
+		}
+	}
+
+	// the destinations match: nothing left to do
+	if plan, err = ds.Plan(context.Background(), desired); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan) != 0 {
+		t.Errorf("expected no changes, got %q", changeStrings(plan))
+	}
+}
+
+func TestDispatcherReconcileError(t *testing.T) {
+	fake := &fakeDispatcher{sets: []DispatcherSet{
+		{ID: 1, Destinations: []DispatcherDestination{{URI: "sip:10.0.0.1:5060", Flags: "AP"}}},
+	}}
+
+	client := serveDispatcher(t, fake).caller
+
+	// the removals fail
+	ds := NewDispatcher(callerFunc(func(ctx context.Context, method string, args ...any) ([]Record, error) {
+		if method == "dispatcher.remove" {
+			return nil, &RPCError{Code: 500, Message: "Removing dispatcher dst failed"}
+		}
+
+		return client.CallContext(ctx, method, args...)
+	}))
+
+	changes, err := ds.Reconcile(context.Background(), []DispatcherSet{
+		{ID: 1, Destinations: []DispatcherDestination{{URI: "sip:10.0.0.2:5060", Flags: "AP"}}},
+	})
+
+	var rpcErr *RPCError
+
+	if !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+
+	if len(changes) != 2 || !changes[0].Applied || changes[1].Applied {
+		t.Errorf("expected the add only to be applied, got %+v", changes)
+	}
+}
+
+func TestDispatcherPlanInvalidFlags(t *testing.T) {
+	for _, flags := range []string{"P", "x", "APX", "AA", "B"} {
+		_, err := planDispatcher(nil, []DispatcherSet{
+			{ID: 1, Destinations: []DispatcherDestination{{URI: "sip:10.0.0.1:5060", Flags: flags}}},
+		})
+
+		if err == nil {
+			t.Errorf("%s: error must be returned", flags)
+		}
+	}
+
+	for _, flags := range []string{"", "a", "I", "dp", "TX"} {
+		_, err := planDispatcher(nil, []DispatcherSet{
+			{ID: 1, Destinations: []DispatcherDestination{{URI: "sip:10.0.0.1:5060", Flags: flags}}},
+		})
+
+		if err != nil {
+			t.Errorf("%s: %v", flags, err)
+		}
+	}
+}
+
+// changeStrings returns the changes as strings.
+func changeStrings(changes []DispatcherChange) []string {
+	var s []string
+
+	for _, change := range changes {
+		s = append(s, change.String())
+	}
+
+	return s
+}
@@ -0,0 +1,108 @@
+package binrpc
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// ReconnectPolicy configures how a Client dials again after losing its connection, such as when Kamailio restarts.
+type ReconnectPolicy struct {
+	// Attempts is the maximum number of dials made by a call to replace the lost connection. The call gives up earlier
+	// if its context is done.
+	Attempts int
+
+	// Backoff is the delay after the first failed dial. It is multiplied by Multiplier (2 if zero) after each failure,
+	// up to MaxBackoff if not zero.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Multiplier float64
+
+	// Jitter is the fraction of each delay which is randomized, between 0 and 1: with 0.2, a delay of 1s is between
+	// 800ms and 1.2s, so that clients losing their connection at the same time do not dial again all at once.
+	Jitter float64
+}
+
+// WithReconnect makes the Client dial again with exponential backoff when its connection is lost, instead of dialing
+// once. The dials are made by the call following the disconnection, which waits until a connection is opened, the
+// attempts are exhausted or its context is done. Each dial is limited by the dial timeout, see WithTimeouts.
+//
+// The call that found the connection lost still fails: use WithRetry as well to retry it transparently.
+func WithReconnect(policy ReconnectPolicy) Option {
+	return func(c *Client) {
+		c.reconnectPolicy = policy
+	}
+}
+
+// redial replaces the lost connection, as configured by WithReconnect. It must be called with c.mu held.
+func (c *Client) redial(ctx context.Context, timeout time.Duration) error {
+	policy := c.reconnectPolicy
+	delay := policy.Backoff
+
+	for attempt := 1; ; attempt++ {
+		err := c.reconnect(ctx, timeout)
+
+		if err == nil || attempt >= policy.Attempts || ctx.Err() != nil || c.isClosed() {
+			return err
+		}
+
+		if sleep(ctx, policy.jitter(delay)) != nil {
+			return err
+		}
+
+		delay = policy.next(delay)
+	}
+}
+
+// next returns the delay following delay.
+func (policy ReconnectPolicy) next(delay time.Duration) time.Duration {
+	multiplier := policy.Multiplier
+
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	return nextBackoff(delay, multiplier, policy.MaxBackoff)
+}
+
+// jitter returns delay randomized by policy.Jitter.
+func (policy ReconnectPolicy) jitter(delay time.Duration) time.Duration {
+	return jitter(delay, policy.Jitter)
+}
+
+// nextBackoff returns delay multiplied by multiplier, up to maxDelay if not zero.
+func nextBackoff(delay time.Duration, multiplier float64, maxDelay time.Duration) time.Duration {
+	delay = time.Duration(float64(delay) * multiplier)
+
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// jitter returns delay randomized by fraction, between 0 and 1.
+func jitter(delay time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return delay
+	}
+
+	return time.Duration(float64(delay) * (1 + fraction*(2*rand.Float64()-1)))
+}
+
+// sleep waits for d, and returns the error of ctx if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
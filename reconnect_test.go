@@ -0,0 +1,109 @@
+package binrpc
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := listener.Addr().String()
+
+	var reconnects atomic.Int32
+
+	client, err := Dial("tcp", address,
+		WithReconnect(ReconnectPolicy{Attempts: 50, Backoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, Jitter: 0.2}),
+		WithConnHooks(ConnHooks{
+			OnReconnect: func(info ConnInfo) {
+				reconnects.Add(1)
+			},
+		}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	// Kamailio stops
+	conn, err := listener.Accept()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+	listener.Close()
+
+	if _, err = client.Call("core.echo"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	// and restarts a bit later
+	restarted := make(chan net.Listener, 1)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+
+		listener, err := net.Listen("tcp", address)
+
+		if err != nil {
+			t.Error(err)
+			close(restarted)
+			return
+		}
+
+		restarted <- listener
+
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go handle(conn, echo)
+		}
+	}()
+
+	defer func() {
+		if listener, ok := <-restarted; ok {
+			listener.Close()
+		}
+	}()
+
+	if _, err = client.Call("core.echo"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := reconnects.Load(); n < 2 {
+		t.Errorf("expected several reconnection attempts, got %d", n)
+	}
+}
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	policy := ReconnectPolicy{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	delay := policy.Backoff
+
+	for _, expected := range []time.Duration{200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		if delay = policy.next(delay); delay != expected {
+			t.Errorf("expected %s, got %s", expected, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+
+	for i := 0; i < 100; i++ {
+		if delay := policy.jitter(time.Second); delay < 500*time.Millisecond || delay > 1500*time.Millisecond {
+			t.Fatalf("expected a delay between 500ms and 1.5s, got %s", delay)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// Request is an encoded call of an RPC function, created by NewRequest.
+//
+// Request and ReadResponse give control over both directions of an exchange, for callers managing the connection
+// themselves:
+//
+//	request, err := binrpc.NewRequest("core.uptime")
+//
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	if _, err = request.WriteTo(conn); err != nil {
+//		panic(err)
+//	}
+//
+//	response, err := binrpc.ReadResponse(conn, request)
+type Request struct {
+	Method string
+	Args   []any
+	Cookie uint32
+
+	packet []byte
+}
+
+// Response is the reply to a Request, read by ReadResponse or created by NewResponse and NewFaultResponse.
+type Response struct {
+	Cookie  uint32
+	Records []Record
+
+	// Size is the size of the packet, header included, in bytes.
+	Size int
+
+	packet []byte
+}
+
+// NewRequest encodes a call of method with args, using a random cookie. Valid args are those of Client.Call.
+func NewRequest(method string, args ...any) (*Request, error) {
+	return newRequest(RandomCookies.Cookie(), method, args)
+}
+
+func newRequest(cookie uint32, method string, args []any) (*Request, error) {
+	packet, _, err := encodePacket(0, cookie, append([]any{method}, args...))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Request{
+		Method: method,
+		Args:   args,
+		Cookie: cookie,
+		packet: packet,
+	}, nil
+}
+
+// ReadRequest reads a request from r, for servers. The args of the request are Record values.
+// To read several packets from the same stream, r must be a *bufio.Reader, as a new one would read ahead.
+func ReadRequest(r io.Reader) (*Request, error) {
+	header, payload, err := readPayload(bufio.NewReader(r), 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := decodePayload(header, payload, ReaderOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("missing method")
+	}
+
+	method, err := records[0].String()
+
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]any, 0, len(records)-1)
+
+	for _, record := range records[1:] {
+		args = append(args, record)
+	}
+
+	return newRequest(header.Cookie, method, args)
+}
+
+// Size returns the size of the encoded packet, header included, in bytes.
+func (request *Request) Size() int {
+	return len(request.packet)
+}
+
+// WriteTo writes the encoded packet to w. It implements io.WriterTo.
+func (request *Request) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(request.packet)
+
+	return int64(n), err
+}
+
+// NewResponse encodes the reply to request with values, for servers. Valid values are the args of Client.Call.
+func NewResponse(request *Request, values ...any) (*Response, error) {
+	return newResponse(0, request.Cookie, values)
+}
+
+// NewFaultResponse encodes a reply to request reporting fault, for servers.
+func NewFaultResponse(request *Request, fault *RPCError) (*Response, error) {
+	return newResponse(flagFault, request.Cookie, []any{fault.Code, fault.Message})
+}
+
+func newResponse(flags uint8, cookie uint32, values []any) (*Response, error) {
+	packet, records, err := encodePacket(flags, cookie, values)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Cookie:  cookie,
+		Records: records,
+		Size:    len(packet),
+		packet:  packet,
+	}, nil
+}
+
+// WriteTo writes the encoded packet to w. It implements io.WriterTo.
+func (response *Response) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(response.packet)
+
+	return int64(n), err
+}
+
+// encodePacket encodes a packet containing values, and returns it with the records of the values.
+func encodePacket(flags uint8, cookie uint32, values []any) ([]byte, []Record, error) {
+	records, err := createRecords(values)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var packet bytes.Buffer
+
+	if err = writePacket(&packet, flags, cookie, records); err != nil {
+		return nil, nil, err
+	}
+
+	decoded := make([]Record, 0, len(records))
+
+	for _, record := range records {
+		decoded = append(decoded, *record)
+	}
+
+	return packet.Bytes(), decoded, nil
+}
+
+// ReadResponse reads the reply to request from r.
+// To read several packets from the same stream, r must be a *bufio.Reader, as a new one would read ahead.
+//
+// If Kamailio replies with a fault, the response is returned with an *RPCError.
+func ReadResponse(r io.Reader, request *Request) (*Response, error) {
+	header, payload, err := readPayload(bufio.NewReader(r), 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Cookie != request.Cookie {
+		return nil, errors.New("expected cookie did not match")
+	}
+
+	records, err := decodePayload(header, payload, ReaderOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the payload as read, for WriteTo
+	packet, _ := appendHeader(nil, header.flags, header.Cookie, len(payload))
+	packet = append(packet, payload...)
+
+	response := &Response{
+		Cookie:  header.Cookie,
+		Records: records,
+		Size:    len(packet),
+		packet:  packet,
+	}
+
+	if header.Fault() {
+		return response, faultError(records)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,136 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestRequestResponse(t *testing.T) {
+	address := listen(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "core.fail" {
+			return []any{&RPCError{Code: 400, Message: "Invalid parameters"}}
+		}
+
+		return echo(records)
+	})
+
+	conn, err := net.Dial("tcp", address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	request, err := NewRequest("core.echo", 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := request.WriteTo(conn)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if int(n) != request.Size() {
+		t.Errorf("expected %d bytes written, got %d", request.Size(), n)
+	}
+
+	response, err := ReadResponse(reader, request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Cookie != request.Cookie {
+		t.Errorf("expected cookie %d, got %d", request.Cookie, response.Cookie)
+	}
+
+	if value, _ := response.Records[0].String(); value != "core.echo" {
+		t.Errorf(`expected "core.echo", got "%s"`, value)
+	}
+
+	// 4 bytes header and cookie at least, and the string record
+	if response.Size < 4+len("core.echo")+2 {
+		t.Errorf("unexpected size %d", response.Size)
+	}
+
+	request, _ = NewRequest("core.fail")
+	request.WriteTo(conn)
+
+	var fault *RPCError
+
+	if response, err = ReadResponse(reader, request); !errors.As(err, &fault) || fault.Code != 400 {
+		t.Errorf("expected a 400 fault, got %v", err)
+	}
+
+	if response == nil || len(response.Records) != 2 {
+		t.Errorf("expected the records of the fault, got %v", response)
+	}
+}
+
+func TestNewRequestInvalidArg(t *testing.T) {
+	if _, err := NewRequest("core.echo", true); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestReadRequestNewResponse(t *testing.T) {
+	request, err := NewRequest("htable.get", "users", 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	request.WriteTo(&buffer)
+
+	received, err := ReadRequest(&buffer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Method != "htable.get" || received.Cookie != request.Cookie || len(received.Args) != 2 {
+		t.Fatalf("expected the request written, got %+v", received)
+	}
+
+	if arg, _ := received.Args[1].(Record).Int(); arg != 42 {
+		t.Errorf("expected 42, got %d", arg)
+	}
+
+	response, err := NewFaultResponse(received, &RPCError{Code: 404, Message: "Not found"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.WriteTo(&buffer)
+
+	var fault *RPCError
+
+	if _, err = ReadResponse(&buffer, request); !errors.As(err, &fault) || fault.Code != 404 {
+		t.Errorf("expected a 404 fault, got %v", err)
+	}
+
+	response, _ = NewResponse(received, "alice")
+	response.WriteTo(&buffer)
+
+	read, err := ReadResponse(&buffer, request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := read.Records[0].String(); value != "alice" || read.Size != response.Size {
+		t.Errorf(`expected "alice" in %d bytes, got "%s" in %d bytes`, response.Size, value, read.Size)
+	}
+}
@@ -0,0 +1,69 @@
+package binrpc
+
+import (
+	"context"
+	"time"
+)
+
+// RetransmitPolicy configures the retransmission of the requests sent on a datagram network, see WithRetransmit.
+type RetransmitPolicy struct {
+	// Interval is the delay after which a request without reply is sent again.
+	Interval time.Duration
+
+	// Attempts is the maximum number of times a request is sent, including the first one.
+	Attempts int
+}
+
+// WithRetransmit makes the Client send a request again when its reply is not received within the interval of policy,
+// as a datagram may be lost on UDP. It applies to the udp and unixgram networks only, where each packet is sent and
+// received as a single datagram.
+//
+// A request received twice is executed twice by Kamailio, so only the calls to idempotent methods are retransmitted
+// (see RegisterIdempotent), unless allowed by WithRetryAllowed. The replies to the copies of a request are discarded
+// once the first one is read.
+func WithRetransmit(policy RetransmitPolicy) Option {
+	return func(c *Client) {
+		c.retransmit = policy
+	}
+}
+
+// retransmits reports whether the request of a call of method is retransmitted.
+func (c *Client) retransmits(ctx context.Context, method string) bool {
+	return c.retransmit.Interval > 0 && c.retransmit.Attempts > 1 && isDatagram(c.network) && retryAllowed(ctx, method)
+}
+
+// readReplyRetransmit is like readReply, but sends request again each time no reply is read within the retransmission
+// interval, until readDeadline. It must be called with c.mu held, after request was sent once.
+func (c *Client) readReplyRetransmit(ctx context.Context, request *Request, readDeadline time.Time) ([]Record, error) {
+	for attempt := 1; ; attempt++ {
+		deadline := readDeadline
+		last := attempt >= c.retransmit.Attempts
+
+		if next := time.Now().Add(c.retransmit.Interval); !last && (deadline.IsZero() || next.Before(deadline)) {
+			deadline = next
+		} else {
+			last = true
+		}
+
+		c.conn.SetReadDeadline(deadline)
+
+		// the watcher may have interrupted the call before the read deadline was set
+		if ctx.Err() != nil {
+			c.conn.SetReadDeadline(time.Unix(1, 0))
+		}
+
+		records, err := c.readReply(ctx, request.Cookie)
+
+		if last || !isTimeout(err) || ctx.Err() != nil {
+			return records, err
+		}
+
+		// the reply of the new request is expected, not discarded as a late one
+		c.forget(request.Cookie)
+
+		if err = c.writeRequest(ctx, request); err != nil {
+			c.fail(err)
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lossyServer starts a UDP server which replies late to the first copy of each request, as if it was lost, and
+// immediately to the next ones. It returns its address and a function returning the number of requests of a method.
+func lossyServer(t *testing.T, delay time.Duration) (string, func(method string) int) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { server.Close() })
+
+	var mu sync.Mutex
+
+	received := make(map[string]int)
+	cookies := make(map[uint32]bool)
+
+	go func() {
+		buffer := make([]byte, datagramReaderSize)
+
+		for {
+			n, addr, err := server.ReadFrom(buffer)
+
+			if err != nil {
+				return
+			}
+
+			request, err := ReadRequest(bufio.NewReader(bytes.NewReader(buffer[:n])))
+
+			if err != nil {
+				return
+			}
+
+			mu.Lock()
+			received[request.Method]++
+			first := !cookies[request.Cookie]
+			cookies[request.Cookie] = true
+			mu.Unlock()
+
+			response, _ := NewResponse(request, request.Method)
+
+			if first {
+				time.AfterFunc(delay, func() { response.WriteTo(&packetWriter{server, addr}) })
+				continue
+			}
+
+			response.WriteTo(&packetWriter{server, addr})
+		}
+	}()
+
+	return server.LocalAddr().String(), func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return received[method]
+	}
+}
+
+func TestWithRetransmit(t *testing.T) {
+	address, received := lossyServer(t, 100*time.Millisecond)
+
+	client, err := Dial("udp", address,
+		WithTimeout(50*time.Millisecond),
+		WithRetransmit(RetransmitPolicy{Interval: 20 * time.Millisecond, Attempts: 3}),
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	records, err := client.Call("core.uptime")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.uptime" {
+		t.Errorf(`expected "core.uptime", got "%s"`, value)
+	}
+
+	if count := received("core.uptime"); count != 2 {
+		t.Errorf("expected 2 requests, got %d", count)
+	}
+
+	// a mutating method is not retransmitted
+	if _, err = client.Call("dispatcher.reload"); !isTimeout(err) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	if count := received("dispatcher.reload"); count != 1 {
+		t.Errorf("expected 1 request, got %d", count)
+	}
+
+	// the late replies to the first copies are skipped
+	time.Sleep(100 * time.Millisecond)
+
+	if records, err = client.Call("core.version"); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.version" {
+		t.Errorf(`expected "core.version", got "%s"`, value)
+	}
+}
@@ -0,0 +1,131 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+)
+
+type retryAllowedKey struct{}
+
+// RetryPolicy configures the retries of the calls failing because of the connection, such as when Kamailio restarts.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts of a call, including the first one.
+	Attempts int
+
+	// Backoff is the delay before the first retry. It is multiplied by Multiplier (1 if zero, for a constant delay)
+	// before each following retry, up to MaxBackoff if not zero.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Multiplier float64
+
+	// Jitter is the fraction of each delay which is randomized, between 0 and 1, see ReconnectPolicy.
+	Jitter float64
+
+	// Retryable reports whether a call failing with err can be retried. If nil, the calls failing because of the
+	// connection are retried. Faults replied by Kamailio are *RPCError values: a Retryable function can accept some
+	// of them, such as a module busy reloading.
+	Retryable func(err error) bool
+}
+
+// WithRetry makes the Client retry the calls failing because of the connection, reconnecting first if needed, or
+// with the errors accepted by policy.Retryable.
+//
+// Only the calls to idempotent methods are retried (see RegisterIdempotent), as a request may have been executed
+// before the connection failed: retrying a reload during a network flap could reload twice. Mutating methods, such as
+// dispatcher.set_state, are never idempotent (see RegisterMutating). Use WithRetryAllowed to retry a call to another
+// method. Calls whose context is done are never retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithRetryAllowed returns a copy of ctx allowing the retry of a call whose method is not idempotent. See WithRetry.
+func WithRetryAllowed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryAllowedKey{}, true)
+}
+
+// callWithRetry writes the request described by info and reads its reply, retrying as configured by WithRetry. It
+// returns the number of attempts. Each attempt has a new cookie unless keepCookie is set.
+func (c *Client) callWithRetry(ctx context.Context, info CallInfo, keepCookie bool) ([]Record, int, error) {
+	delay := c.retry.Backoff
+
+	for attempt := 1; ; attempt++ {
+		records, err := c.call(ctx, info)
+
+		if err == nil || !c.shouldRetry(ctx, info.Method, err, attempt) {
+			return records, attempt, err
+		}
+
+		c.log(ctx, slog.LevelDebug, "binrpc retry",
+			slog.String("method", info.Method),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", delay),
+			errorAttr(err),
+		)
+
+		if sleep(ctx, jitter(delay, c.retry.Jitter)) != nil {
+			return nil, attempt, err
+		}
+
+		delay = c.retry.next(delay)
+
+		// the cookie of a call that timed out stays in use until its late reply is read
+		if !keepCookie {
+			info.Cookie = c.cookies.Cookie()
+		}
+	}
+}
+
+// shouldRetry reports whether the call of method which failed with err on its attempt-th attempt can be retried.
+func (c *Client) shouldRetry(ctx context.Context, method string, err error, attempt int) bool {
+	if attempt >= c.retry.Attempts || ctx.Err() != nil || c.isClosed() || !c.retry.retryable(err) {
+		return false
+	}
+
+	// a Client created by NewClient cannot replace its lost connection
+	if c.dial == nil && isConnError(err) {
+		return false
+	}
+
+	return retryAllowed(ctx, method)
+}
+
+// retryable reports whether a call failing with err can be retried, see RetryPolicy.Retryable.
+func (policy RetryPolicy) retryable(err error) bool {
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+
+	return isConnError(err)
+}
+
+// next returns the delay following delay.
+func (policy RetryPolicy) next(delay time.Duration) time.Duration {
+	multiplier := policy.Multiplier
+
+	if multiplier == 0 {
+		multiplier = 1
+	}
+
+	return nextBackoff(delay, multiplier, policy.MaxBackoff)
+}
+
+// retryAllowed reports whether a request of method can be sent again: method is idempotent, or ctx allows it.
+func retryAllowed(ctx context.Context, method string) bool {
+	allowed, _ := ctx.Value(retryAllowedKey{}).(bool)
+
+	return allowed || IsIdempotent(method)
+}
+
+// isConnError reports whether err is caused by the connection, as opposed to a fault or an invalid call.
+func isConnError(err error) bool {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed)
+}
@@ -0,0 +1,193 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyServer closes the connection instead of replying to the first request of each method, and replies to the next
+// ones. It returns its address and the number of requests received by method.
+func flakyServer(t *testing.T) (string, func(method string) int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+
+	received := make(map[string]int)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				for {
+					request, err := ReadRequest(conn)
+
+					if err != nil {
+						return
+					}
+
+					mu.Lock()
+					received[request.Method]++
+					first := received[request.Method] == 1
+					mu.Unlock()
+
+					if first {
+						return
+					}
+
+					response, _ := NewResponse(request, "ok")
+					response.WriteTo(conn)
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return received[method]
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	address, received := flakyServer(t)
+	client, err := Dial("tcp", address, WithRetry(RetryPolicy{Attempts: 3, Backoff: 10 * time.Millisecond}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	if _, err = client.Call("tm.stats"); err != nil {
+		t.Error(err)
+	}
+
+	if count := received("tm.stats"); count != 2 {
+		t.Errorf("expected 2 requests, got %d", count)
+	}
+
+	// a mutating method is not retried
+	if _, err = client.Call("dispatcher.reload"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	if count := received("dispatcher.reload"); count != 1 {
+		t.Errorf("expected 1 request, got %d", count)
+	}
+
+	// unless the caller allows it
+	if _, err = client.CallContext(WithRetryAllowed(context.Background()), "app.update"); err != nil {
+		t.Error(err)
+	}
+
+	if count := received("app.update"); count != 2 {
+		t.Errorf("expected 2 requests, got %d", count)
+	}
+}
+
+func TestWithRetryFault(t *testing.T) {
+	var mu sync.Mutex
+
+	calls := 0
+
+	client := serve(t, func(records []Record) []any {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+
+		return []any{&RPCError{Code: 500, Message: "internal error"}}
+	}, WithRetry(RetryPolicy{Attempts: 3}))
+
+	if _, err := client.Call("tm.stats"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls != 1 {
+		t.Errorf("faults must not be retried, got %d calls", calls)
+	}
+}
+
+func TestWithRetryRetryable(t *testing.T) {
+	var mu sync.Mutex
+
+	calls := make(map[string]int)
+
+	client := serve(t, func(records []Record) []any {
+		method, _ := records[0].String()
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if calls[method]++; calls[method] < 3 {
+			return []any{&RPCError{Code: 503, Message: "busy"}}
+		}
+
+		return []any{"ok"}
+	}, WithRetry(RetryPolicy{
+		Attempts: 3,
+		Retryable: func(err error) bool {
+			var rpcErr *RPCError
+			return errors.As(err, &rpcErr) && rpcErr.Code == 503
+		},
+	}))
+
+	if _, err := client.Call("tm.stats"); err != nil {
+		t.Error(err)
+	}
+
+	// mutating methods are never retried
+	if _, err := client.Call("dispatcher.set_state", "ip", 1, "sip:10.0.0.1"); err == nil {
+		t.Error("error must be returned")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls["tm.stats"] != 3 {
+		t.Errorf("expected 3 calls, got %d", calls["tm.stats"])
+	}
+
+	if calls["dispatcher.set_state"] != 1 {
+		t.Errorf("expected 1 call, got %d", calls["dispatcher.set_state"])
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Backoff: 100 * time.Millisecond}
+
+	// the delay is constant by default
+	if delay := policy.next(policy.Backoff); delay != policy.Backoff {
+		t.Errorf("expected %s, got %s", policy.Backoff, delay)
+	}
+
+	policy.Multiplier = 3
+	policy.MaxBackoff = time.Second
+	delay := policy.Backoff
+
+	for _, expected := range []time.Duration{300 * time.Millisecond, 900 * time.Millisecond, time.Second} {
+		if delay = policy.next(delay); delay != expected {
+			t.Errorf("expected %s, got %s", expected, delay)
+		}
+	}
+}
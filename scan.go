@@ -0,0 +1,255 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// scanStruct sets the fields of the struct v from the items of record, which must be a struct.
+//
+// An item is stored in the field whose binrpc tag is the item key:
+//
+//	type Stats struct {
+//		Replied int `binrpc:"rpl_received"`
+//	}
+//
+// Fields without tag match the keys written in snake_case: letters and digits of the key and of the field name are
+// compared ignoring case, other characters being ignored. So "total_local" is stored in TotalLocal. The words that
+// Kamailio abbreviates in its keys, listed in keyAbbreviations, also match their Go spelling: "rpl_received" is stored
+// in ReplReceived, or in RplReceived. Fields tagged `binrpc:"-"` and unexported fields are ignored.
+//
+// Items without a matching field are ignored, and fields without a matching item are left unchanged. A field can be of
+// any type accepted by Scan, including another struct or a slice. As the same key may be found several times in a
+// struct, such as "SET" in the reply of dispatcher.list, the values of a key which are not arrays are appended to a
+// slice field.
+func (record *Record) scanStruct(v reflect.Value) error {
+	items, err := record.StructItems()
+
+	if err != nil {
+		return err
+	}
+
+	tagged := make(map[string]int)
+	named := make(map[string]int)
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("binrpc")
+
+		switch {
+		case tag == "-":
+		case ok:
+			tagged[tag] = i
+		default:
+			named[normalizeKey(field.Name)] = i
+		}
+	}
+
+	// slice fields whose elements were appended
+	appended := make(map[int]bool)
+
+	for _, item := range items {
+		i, ok := tagged[item.Key]
+
+		if !ok {
+			if i, ok = named[normalizeKey(item.Key)]; !ok {
+				if i, ok = named[normalizeKey(expandKey(item.Key))]; !ok {
+					continue
+				}
+			}
+		}
+
+		field := v.Field(i)
+
+		if item.Value.Type != TypeArray && isElementSlice(field.Type()) {
+			if !appended[i] {
+				appended[i] = true
+				field.SetLen(0)
+			}
+
+			element := reflect.New(field.Type().Elem())
+
+			if err = item.Value.Scan(element.Interface()); err != nil {
+				return fmt.Errorf("%s: %w", item.Key, err)
+			}
+
+			field.Set(reflect.Append(field, element.Elem()))
+			continue
+		}
+
+		if err = item.Value.Scan(field.Addr().Interface()); err != nil {
+			return fmt.Errorf("%s: %w", item.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// scanSlice sets the slice v to the elements of record, which must be an array.
+func (record *Record) scanSlice(v reflect.Value) error {
+	elements, err := record.Array()
+
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(elements), len(elements))
+
+	for i := range elements {
+		if err = elements[i].Scan(slice.Index(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("array element %d: %w", i, err)
+		}
+	}
+
+	v.Set(slice)
+
+	return nil
+}
+
+// scanUint returns the value of record, an int or a decimal string, as an unsigned integer of the given size in bits.
+func (record *Record) scanUint(bitSize int) (uint64, error) {
+	switch record.Type {
+	case TypeString:
+		return strconv.ParseUint(record.Value.(string), 10, bitSize)
+	case TypeInt:
+		i := record.Value.(int)
+
+		if i < 0 || (bitSize < 64 && uint64(i) >= 1<<bitSize) {
+			return 0, fmt.Errorf("value out of range: %d does not fit in uint%d", i, bitSize)
+		}
+
+		return uint64(i), nil
+	default:
+		return 0, fmt.Errorf("type error: cannot convert type %d to uint%d", record.Type, bitSize)
+	}
+}
+
+// scanBool returns the value of record, 0 or 1, or a string "yes" or "no" (in any case), "1" or "0", as a boolean.
+func (record *Record) scanBool() (bool, error) {
+	switch record.Type {
+	case TypeString:
+		switch strings.ToLower(record.Value.(string)) {
+		case "yes", "1":
+			return true, nil
+		case "no", "0":
+			return false, nil
+		}
+
+		return false, fmt.Errorf("invalid boolean %q", record.Value)
+	case TypeInt:
+		switch record.Value.(int) {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		}
+
+		return false, fmt.Errorf("invalid boolean %d", record.Value)
+	default:
+		return false, fmt.Errorf("type error: cannot convert type %d to bool", record.Type)
+	}
+}
+
+// isElementSlice reports whether t is a slice whose elements are scanned one by one, unlike []byte, []StructItem and
+// []Record which hold a single value.
+func isElementSlice(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf([]byte(nil)), reflect.TypeOf([]StructItem(nil)), reflect.TypeOf([]Record(nil)):
+		return false
+	default:
+		return t.Kind() == reflect.Slice
+	}
+}
+
+// Unmarshal stores the records of a reply into v, like json.Unmarshal does with a JSON document.
+//
+// If v is a pointer to a slice, each record of the reply is an element of the slice, except if the reply is a single
+// array, whose elements are then the elements of the slice. Otherwise, the first record of the reply is stored into
+// v. See Record.Scan for the types accepted and scanStruct for how struct items are mapped to fields:
+//
+//	var dialogs []struct {
+//		CallID string `binrpc:"call-id"`
+//		State  int
+//	}
+//
+//	err = binrpc.Unmarshal(records, &dialogs)
+func Unmarshal(records []Record, v any) error {
+	dest := reflect.ValueOf(v)
+
+	if dest.Kind() != reflect.Pointer || dest.IsNil() {
+		return errors.New("invalid dest type")
+	}
+
+	if !isElementSlice(dest.Elem().Type()) || (len(records) == 1 && records[0].Type == TypeArray) {
+		if len(records) == 0 {
+			return errors.New("empty reply")
+		}
+
+		return records[0].Scan(v)
+	}
+
+	array := Record{Type: TypeArray, Value: records}
+
+	return array.scanSlice(dest.Elem())
+}
+
+// Call calls method with caller and unmarshals the reply into a T, see Unmarshal:
+//
+//	uptime, err := binrpc.Call[struct{ Uptime int }](client, "core.uptime")
+func Call[T any](caller Caller, method string, args ...any) (T, error) {
+	return CallContext[T](context.Background(), caller, method, args...)
+}
+
+// CallContext is like Call, with a context.
+func CallContext[T any](ctx context.Context, caller Caller, method string, args ...any) (T, error) {
+	var v T
+
+	records, err := caller.CallContext(ctx, method, args...)
+
+	if err != nil {
+		return v, err
+	}
+
+	err = Unmarshal(records, &v)
+
+	return v, err
+}
+
+// normalizeKey returns the letters and digits of s, in lower case.
+func normalizeKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, s)
+}
+
+// keyAbbreviations are the words abbreviated in the keys of Kamailio, with their spelling in field names.
+var keyAbbreviations = map[string]string{
+	"rpl": "repl",
+}
+
+// expandKey returns the key s, a snake_case key, with its abbreviated words replaced by their spelling in field names.
+func expandKey(s string) string {
+	words := strings.Split(s, "_")
+
+	for i, word := range words {
+		if expanded, ok := keyAbbreviations[strings.ToLower(word)]; ok {
+			words[i] = expanded
+		}
+	}
+
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,294 @@
+package binrpc
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+func TestScanStruct(t *testing.T) {
+	type memory struct {
+		Used int
+	}
+
+	type stats struct {
+		Current     int
+		TotalLocal  int
+		ReplSent    int
+		RplRelayed  int
+		Replied     int    `binrpc:"rpl_received"`
+		Ignored     int    `binrpc:"-"`
+		Version     string `binrpc:"version"`
+		Load        float64
+		Memory      memory `binrpc:"shmem"`
+		Missing     int
+		unexported  int
+		Unsupported complex128
+	}
+
+	record := Record{
+		Type: TypeStruct,
+		Value: []StructItem{
+			{Key: "current", Value: Record{Type: TypeInt, Value: 3}},
+			{Key: "total_local", Value: Record{Type: TypeInt, Value: 12}},
+			{Key: "rpl_received", Value: Record{Type: TypeInt, Value: 42}},
+			{Key: "rpl_sent", Value: Record{Type: TypeInt, Value: 40}},
+			{Key: "rpl_relayed", Value: Record{Type: TypeInt, Value: 2}},
+			{Key: "ignored", Value: Record{Type: TypeInt, Value: 1}},
+			{Key: "version", Value: Record{Type: TypeString, Value: "5.8.0"}},
+			{Key: "load", Value: Record{Type: TypeDouble, Value: 0.5}},
+			{Key: "shmem", Value: Record{Type: TypeStruct, Value: []StructItem{
+				{Key: "used", Value: Record{Type: TypeInt, Value: 1024}},
+			}}},
+			{Key: "unknown", Value: Record{Type: TypeInt, Value: 7}},
+		},
+	}
+
+	s := stats{Missing: -1}
+
+	if err := record.Scan(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := stats{
+		Current:    3,
+		TotalLocal: 12,
+		Replied:    42,
+		ReplSent:   40,
+		RplRelayed: 2,
+		Version:    "5.8.0",
+		Load:       0.5,
+		Memory:     memory{Used: 1024},
+		Missing:    -1,
+	}
+
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+
+	record.Value = append(record.Value.([]StructItem), StructItem{Key: "unsupported", Value: Record{Type: TypeInt, Value: 1}})
+
+	if err := record.Scan(&s); err == nil {
+		t.Error("error must be returned for an unsupported field type")
+	}
+
+	if err := (&Record{Type: TypeInt, Value: 1}).Scan(&s); err == nil {
+		t.Error("error must be returned for a record that is not a struct")
+	}
+}
+
+func TestScanBoolUintAddr(t *testing.T) {
+	integer := func(i int) *Record { return &Record{Type: TypeInt, Value: i} }
+	str := func(s string) *Record { return &Record{Type: TypeString, Value: s} }
+
+	var b bool
+
+	for _, record := range []*Record{integer(1), str("yes"), str("YES")} {
+		if err := record.Scan(&b); err != nil || !b {
+			t.Errorf("expected true from %v, got %t (%v)", record.Value, b, err)
+		}
+	}
+
+	for _, record := range []*Record{integer(0), str("no")} {
+		if err := record.Scan(&b); err != nil || b {
+			t.Errorf("expected false from %v, got %t (%v)", record.Value, b, err)
+		}
+	}
+
+	if err := integer(2).Scan(&b); err == nil {
+		t.Error("error must be returned for 2")
+	}
+
+	var (
+		u   uint
+		u32 uint32
+		u64 uint64
+	)
+
+	if err := integer(42).Scan(&u); err != nil || u != 42 {
+		t.Errorf("expected 42, got %d (%v)", u, err)
+	}
+
+	if err := str("4294967295").Scan(&u32); err != nil || u32 != 4294967295 {
+		t.Errorf("expected 4294967295, got %d (%v)", u32, err)
+	}
+
+	if err := str("18446744073709551615").Scan(&u64); err != nil || u64 != 18446744073709551615 {
+		t.Errorf("expected 18446744073709551615, got %d (%v)", u64, err)
+	}
+
+	if err := integer(-1).Scan(&u64); err == nil {
+		t.Error("error must be returned for a negative value")
+	}
+
+	if err := str("4294967296").Scan(&u32); err == nil {
+		t.Error("error must be returned for a value out of range")
+	}
+
+	if err := (&Record{Type: TypeDouble, Value: 1.0}).Scan(&u); err == nil {
+		t.Error("error must be returned for a double")
+	}
+
+	var addr netip.Addr
+
+	if err := str("2001:db8::1").Scan(&addr); err != nil || addr != netip.MustParseAddr("2001:db8::1") {
+		t.Errorf("expected 2001:db8::1, got %s (%v)", addr, err)
+	}
+
+	if err := str("sip1.example.com").Scan(&addr); err == nil {
+		t.Error("error must be returned for a host name")
+	}
+
+	// in struct fields, such as the ones of core.sockets_list
+	var socket struct {
+		Proto   string
+		Address netip.Addr
+		Port    uint32
+		Mcast   bool
+	}
+
+	record := Record{
+		Type: TypeStruct,
+		Value: []StructItem{
+			{Key: "proto", Value: *str("udp")},
+			{Key: "address", Value: *str("10.0.0.1")},
+			{Key: "port", Value: *integer(5060)},
+			{Key: "mcast", Value: *str("no")},
+		},
+	}
+
+	if err := record.Scan(&socket); err != nil {
+		t.Fatal(err)
+	}
+
+	if socket.Address != netip.MustParseAddr("10.0.0.1") || socket.Port != 5060 || socket.Mcast {
+		t.Errorf("unexpected socket %+v", socket)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	str := func(s string) Record { return Record{Type: TypeString, Value: s} }
+	integer := func(i int) Record { return Record{Type: TypeInt, Value: i} }
+	structure := func(items ...StructItem) Record { return Record{Type: TypeStruct, Value: items} }
+
+	destination := func(uri string, flags string) StructItem {
+		return StructItem{Key: "DEST", Value: structure(
+			StructItem{Key: "URI", Value: str(uri)},
+			StructItem{Key: "FLAGS", Value: str(flags)},
+		)}
+	}
+
+	// the shape of the reply of dispatcher.list
+	records := []Record{structure(
+		StructItem{Key: "NRSETS", Value: integer(2)},
+		StructItem{Key: "RECORDS", Value: structure(
+			StructItem{Key: "SET", Value: structure(
+				StructItem{Key: "ID", Value: integer(1)},
+				StructItem{Key: "TARGETS", Value: structure(
+					destination("sip:10.0.0.1:5060", "AP"),
+					destination("sip:10.0.0.2:5060", "IP"),
+				)},
+			)},
+			StructItem{Key: "SET", Value: structure(
+				StructItem{Key: "ID", Value: integer(2)},
+				StructItem{Key: "TARGETS", Value: structure(destination("sip:10.0.1.1:5060", "AP"))},
+			)},
+		)},
+	)}
+
+	type set struct {
+		ID      int
+		Targets struct {
+			Destinations []struct {
+				URI   string
+				Flags string
+			} `binrpc:"DEST"`
+		}
+	}
+
+	var list struct {
+		Sets    int `binrpc:"NRSETS"`
+		Records struct {
+			Sets []set `binrpc:"SET"`
+		}
+	}
+
+	if err := Unmarshal(records, &list); err != nil {
+		t.Fatal(err)
+	}
+
+	if list.Sets != 2 || len(list.Records.Sets) != 2 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	if destinations := list.Records.Sets[0].Targets.Destinations; len(destinations) != 2 || destinations[1].Flags != "IP" {
+		t.Errorf("unexpected destinations %+v", destinations)
+	}
+
+	// one record per element, and an array
+	var ids []int
+
+	if err := Unmarshal([]Record{integer(1), integer(2)}, &ids); err != nil || len(ids) != 2 || ids[1] != 2 {
+		t.Errorf("unexpected ids %v: %v", ids, err)
+	}
+
+	var names []string
+
+	array := Record{Type: TypeArray, Value: []Record{str("a"), str("b"), str("c")}}
+
+	if err := Unmarshal([]Record{array}, &names); err != nil || len(names) != 3 {
+		t.Errorf("unexpected names %v: %v", names, err)
+	}
+
+	if err := Unmarshal(nil, &list); err == nil {
+		t.Error("error must be returned")
+	}
+
+	if err := Unmarshal([]Record{str("x")}, &ids); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestCall(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer()
+	defer server.Close()
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	uptime, err := Call[struct{ Uptime int }](client, "core.uptime")
+
+	if err != nil || uptime.Uptime != 42 {
+		t.Errorf("expected uptime 42, got %d (%v)", uptime.Uptime, err)
+	}
+
+	args, err := Call[[]string](client, "core.echo", "a", "b")
+
+	if err != nil || len(args) != 2 || args[1] != "b" {
+		t.Errorf("unexpected args %v: %v", args, err)
+	}
+
+	var fault *RPCError
+
+	if _, err = Call[int](client, "dispatcher.reload"); !errors.As(err, &fault) {
+		t.Errorf("expected an *RPCError, got %v", err)
+	}
+
+	if _, err = Call[int](client, "core.uptime"); err == nil {
+		t.Error("error must be returned")
+	}
+}
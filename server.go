@@ -0,0 +1,324 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+)
+
+// Handler returns the values of the reply to request, or an error. An *RPCError is replied as a fault, other errors
+// as faults with code 500. The args of request are Record values. ctx is canceled when the Server is closed.
+type Handler func(ctx context.Context, request *Request) ([]any, error)
+
+// Server is a BINRPC server, which replies to requests with the handlers registered for their method, like the ctl
+// module of Kamailio. It is meant for simulators and test doubles, see also package binrpctest.
+//
+//	server := binrpc.NewServer()
+//
+//	server.Handle("core.uptime", func(ctx context.Context, request *binrpc.Request) ([]any, error) {
+//		return []any{map[string]any{"uptime": 42}}, nil
+//	})
+//
+//	listener, err := net.Listen("tcp", "127.0.0.1:2049")
+//	err = server.Serve(listener)
+//
+// Methods without handler are replied with a "500 command not found" fault, and system.listMethods returns the methods
+// registered, unless it has its own handler.
+//
+// The requests of a connection are handled one at a time, in order.
+type Server struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu           sync.Mutex
+	handlers     map[string]Handler
+	interceptors []ServerInterceptor
+	listeners    map[net.Listener]bool
+	conns        map[net.Conn]bool
+	packets      map[net.PacketConn]bool
+	closed       bool
+}
+
+// ServerInterceptor wraps the handling of the requests of a Server, like Interceptor wraps the calls of a Client. It
+// returns a Handler calling next, which runs the handler of the method or the next interceptor. For the methods
+// without handler, next returns the "command not found" fault, so that an interceptor sees all the requests, such as
+// to record them.
+type ServerInterceptor func(next Handler) Handler
+
+// ErrServerClosed is returned by Serve and ServePacket after Close.
+var ErrServerClosed = errors.New("binrpc: server closed")
+
+// NewServer returns a Server without handler.
+func NewServer() *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Server{
+		ctx:       ctx,
+		cancel:    cancel,
+		handlers:  make(map[string]Handler),
+		listeners: make(map[net.Listener]bool),
+		conns:     make(map[net.Conn]bool),
+		packets:   make(map[net.PacketConn]bool),
+	}
+}
+
+// Handle registers handler for method, replacing the previous one if any.
+func (s *Server) Handle(method string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Intercept adds interceptors around the handling of each request. The first one is the outermost.
+func (s *Server) Intercept(interceptors ...ServerInterceptor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.interceptors = append(s.interceptors, interceptors...)
+}
+
+// Serve accepts connections on listener, such as a TCP or unix listener, and replies to their requests. It returns
+// ErrServerClosed after Close, or the error of Accept.
+func (s *Server) Serve(listener net.Listener) error {
+	if !s.track(func() { s.listeners[listener] = true }) {
+		return ErrServerClosed
+	}
+
+	defer s.untrack(func() { delete(s.listeners, listener) })
+
+	for {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		// the handler is added to s.wg with s.mu held, so that Close does not wait before it is added
+		if !s.track(func() {
+			s.conns[conn] = true
+			s.wg.Add(1)
+		}) {
+			conn.Close()
+			return ErrServerClosed
+		}
+
+		go func() {
+			defer s.wg.Done()
+			defer s.untrack(func() { delete(s.conns, conn) })
+
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// ServePacket replies to the requests received on conn, such as a UDP socket, each request and reply being a single
+// datagram. It returns ErrServerClosed after Close, or the error of ReadFrom.
+func (s *Server) ServePacket(conn net.PacketConn) error {
+	// the requests are handled by this goroutine, which Close waits for
+	if !s.track(func() {
+		s.packets[conn] = true
+		s.wg.Add(1)
+	}) {
+		return ErrServerClosed
+	}
+
+	defer s.wg.Done()
+	defer s.untrack(func() { delete(s.packets, conn) })
+
+	buffer := make([]byte, datagramReaderSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+
+			return err
+		}
+
+		request, err := ReadRequest(bytes.NewReader(buffer[:n]))
+
+		if err != nil {
+			// a datagram is self-contained: an invalid one does not affect the next ones
+			continue
+		}
+
+		if response, err := s.respond(request); err == nil {
+			response.WriteTo(&packetWriter{conn, addr})
+		}
+	}
+}
+
+// Close stops the listeners and closes the connections of the Server, then waits for the handlers in progress to
+// return. The context of the handlers is canceled.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+
+	for listener := range s.listeners {
+		listener.Close()
+	}
+
+	for conn := range s.conns {
+		conn.Close()
+	}
+
+	for conn := range s.packets {
+		conn.Close()
+	}
+
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+
+	return nil
+}
+
+// serveConn replies to the requests received on conn until it is closed.
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		request, err := ReadRequest(reader)
+
+		if err != nil {
+			return
+		}
+
+		response, err := s.respond(request)
+
+		if err != nil {
+			return
+		}
+
+		if _, err = response.WriteTo(conn); err != nil {
+			return
+		}
+	}
+}
+
+// respond returns the response of the handler of request, run through the interceptors.
+func (s *Server) respond(request *Request) (*Response, error) {
+	handler := Handler(s.dispatch)
+
+	s.mu.Lock()
+
+	for i := len(s.interceptors) - 1; i >= 0; i-- {
+		handler = s.interceptors[i](handler)
+	}
+
+	s.mu.Unlock()
+
+	values, err := handler(s.ctx, request)
+
+	var fault *RPCError
+
+	if errors.As(err, &fault) {
+		return NewFaultResponse(request, fault)
+	}
+
+	if err != nil {
+		return NewFaultResponse(request, &RPCError{Code: 500, Message: err.Error()})
+	}
+
+	response, err := NewResponse(request, values...)
+
+	if err != nil {
+		return NewFaultResponse(request, &RPCError{Code: 500, Message: err.Error()})
+	}
+
+	return response, nil
+}
+
+// dispatch runs the handler of the method of request.
+func (s *Server) dispatch(ctx context.Context, request *Request) ([]any, error) {
+	s.mu.Lock()
+	handler, ok := s.handlers[request.Method]
+	s.mu.Unlock()
+
+	if !ok && request.Method == "system.listMethods" {
+		handler, ok = s.listMethods, true
+	}
+
+	if !ok {
+		return nil, &RPCError{Code: 500, Message: "command " + request.Method + " not found"}
+	}
+
+	return handler(ctx, request)
+}
+
+// listMethods is the default handler of system.listMethods.
+func (s *Server) listMethods(context.Context, *Request) ([]any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	methods := make([]string, 0, len(s.handlers))
+
+	for method := range s.handlers {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+
+	values := make([]any, 0, len(methods))
+
+	for _, method := range methods {
+		values = append(values, method)
+	}
+
+	return values, nil
+}
+
+// track calls add with s.mu held, unless the Server is closed, and reports whether it was called.
+func (s *Server) track(add func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	add()
+
+	return true
+}
+
+// untrack calls remove with s.mu held.
+func (s *Server) untrack(remove func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remove()
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// packetWriter writes datagrams to addr.
+type packetWriter struct {
+	conn net.PacketConn
+	addr net.Addr
+}
+
+func (w *packetWriter) Write(p []byte) (int, error) {
+	return w.conn.WriteTo(p, w.addr)
+}
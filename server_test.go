@@ -0,0 +1,216 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestServer() *Server {
+	server := NewServer()
+
+	server.Handle("core.uptime", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{map[string]any{"uptime": 42}}, nil
+	})
+
+	server.Handle("core.echo", func(ctx context.Context, request *Request) ([]any, error) {
+		return request.Args, nil
+	})
+
+	server.Handle("dispatcher.reload", func(ctx context.Context, request *Request) ([]any, error) {
+		return nil, &RPCError{Code: 503, Message: "busy"}
+	})
+
+	server.Handle("app.fail", func(ctx context.Context, request *Request) ([]any, error) {
+		return nil, errors.New("failed")
+	})
+
+	return server
+}
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer()
+	served := make(chan error, 1)
+
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	var uptime struct {
+		Uptime int
+	}
+
+	if err = Unmarshal(must(client.Call("core.uptime")), &uptime); err != nil || uptime.Uptime != 42 {
+		t.Errorf("expected uptime 42, got %d (%v)", uptime.Uptime, err)
+	}
+
+	records, err := client.Call("core.echo", "a", 1)
+
+	if err != nil {
+		t.Fatal(err)
+	} else if value, _ := records[1].Int(); len(records) != 2 || value != 1 {
+		t.Errorf("expected the args, got %v", records)
+	}
+
+	var fault *RPCError
+
+	if _, err = client.Call("dispatcher.reload"); !errors.As(err, &fault) || fault.Code != 503 {
+		t.Errorf("expected a 503 fault, got %v", err)
+	}
+
+	if _, err = client.Call("app.fail"); !errors.As(err, &fault) || fault.Code != 500 || fault.Message != "failed" {
+		t.Errorf(`expected "500 failed", got %v`, err)
+	}
+
+	if _, err = client.Call("core.foo"); !errors.As(err, &fault) || fault.Message != "command core.foo not found" {
+		t.Errorf(`expected "500 command core.foo not found", got %v`, err)
+	}
+
+	var methods []string
+
+	if err = Unmarshal(must(client.Call("system.listMethods")), &methods); err != nil || len(methods) != 4 {
+		t.Errorf("expected 4 methods, got %v (%v)", methods, err)
+	}
+
+	server.Close()
+
+	if err = <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("expected ErrServerClosed, got %v", err)
+	}
+
+	if _, err = client.Call("core.uptime"); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestServerPacket(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer()
+	defer server.Close()
+
+	go server.ServePacket(conn)
+
+	client, err := Dial("udp", conn.LocalAddr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	records, err := client.Call("core.echo", "udp")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "udp" {
+		t.Errorf(`expected "udp", got "%s"`, value)
+	}
+}
+
+func TestServerIntercept(t *testing.T) {
+	server := newTestServer()
+	var methods []string
+
+	server.Intercept(func(next Handler) Handler {
+		return func(ctx context.Context, request *Request) ([]any, error) {
+			methods = append(methods, request.Method)
+			return next(ctx, request)
+		}
+	})
+
+	for _, method := range []string{"core.uptime", "core.foo"} {
+		request, err := NewRequest(method)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = server.respond(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(methods) != 2 || methods[0] != "core.uptime" || methods[1] != "core.foo" {
+		t.Errorf("expected the methods of all the requests, got %v", methods)
+	}
+}
+
+func TestServerPacketClose(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer()
+	started := make(chan bool)
+	var done atomic.Bool
+
+	server.Handle("app.slow", func(ctx context.Context, request *Request) ([]any, error) {
+		close(started)
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		done.Store(true)
+
+		return nil, ctx.Err()
+	})
+
+	go server.ServePacket(conn)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	request, err := NewRequest("app.slow")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = request.WriteTo(client); err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+	server.Close()
+
+	if !done.Load() {
+		t.Error("Close must wait for the handler in progress")
+	}
+}
+
+func must(records []Record, err error) []Record {
+	if err != nil {
+		return nil
+	}
+
+	return records
+}
@@ -0,0 +1,206 @@
+package binrpc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Param describes a positional parameter of an RPC command.
+type Param struct {
+	Name string
+
+	// Type is the BINRPC type of the parameter: TypeInt, TypeString, TypeDouble, TypeBytes, TypeStruct or TypeArray.
+	Type uint8
+
+	// Optional parameters can be omitted. Because parameters are positional, an optional parameter can only be
+	// omitted if all the parameters after it are omitted too.
+	Optional bool
+}
+
+// Signature describes the parameters of an RPC command, in positional order.
+type Signature struct {
+	Method string
+	Params []Param
+}
+
+var signatures = struct {
+	sync.RWMutex
+	m map[string]Signature
+}{
+	m: make(map[string]Signature),
+}
+
+func init() {
+	for _, signature := range []Signature{
+		{"dispatcher.add", []Param{{"group", TypeInt, false}, {"address", TypeString, false}, {"flags", TypeInt, true}, {"priority", TypeInt, true}, {"attrs", TypeString, true}}},
+		{"dispatcher.remove", []Param{{"group", TypeInt, false}, {"address", TypeString, false}}},
+		{"dispatcher.set_state", []Param{{"state", TypeString, false}, {"group", TypeInt, false}, {"address", TypeString, false}}},
+		{"dispatcher.list", nil},
+		{"dispatcher.reload", nil},
+		{"htable.get", []Param{{"htable", TypeString, false}, {"key", TypeString, false}}},
+		{"htable.sets", []Param{{"htable", TypeString, false}, {"key", TypeString, false}, {"value", TypeString, false}}},
+		{"htable.seti", []Param{{"htable", TypeString, false}, {"key", TypeString, false}, {"value", TypeInt, false}}},
+		{"htable.delete", []Param{{"htable", TypeString, false}, {"key", TypeString, false}}},
+		{"htable.dump", []Param{{"htable", TypeString, false}}},
+		{"htable.flush", []Param{{"htable", TypeString, false}}},
+		{"htable.reload", []Param{{"htable", TypeString, false}}},
+		{"htable.listTables", nil},
+		{"htable.stats", nil},
+	} {
+		RegisterSignature(signature)
+	}
+}
+
+// RegisterSignature registers the signature of an RPC command, replacing any previous signature of the same method.
+// Signatures of common dispatcher and htable commands are registered by default.
+func RegisterSignature(signature Signature) {
+	signatures.Lock()
+	defer signatures.Unlock()
+
+	signatures.m[signature.Method] = signature
+}
+
+// LookupSignature returns the signature registered for method.
+func LookupSignature(method string) (Signature, bool) {
+	signatures.RLock()
+	defer signatures.RUnlock()
+
+	signature, ok := signatures.m[method]
+
+	return signature, ok
+}
+
+// NamedArgs returns the positional args of method from named parameters, using the signature registered for method.
+// See Signature.Args.
+func NamedArgs(method string, params map[string]any) ([]any, error) {
+	signature, ok := LookupSignature(method)
+
+	if !ok {
+		return nil, fmt.Errorf("no signature registered for method %s", method)
+	}
+
+	return signature.Args(params)
+}
+
+// Args returns the positional args matching params, which maps parameter names to values.
+// It returns an error if a parameter is unknown, missing, or of the wrong type.
+func (signature Signature) Args(params map[string]any) ([]any, error) {
+	var args []any
+
+	known := make(map[string]bool, len(signature.Params))
+	omitted := ""
+
+	for _, param := range signature.Params {
+		known[param.Name] = true
+
+		value, ok := params[param.Name]
+
+		if !ok {
+			if !param.Optional {
+				return nil, fmt.Errorf("%s: missing parameter %s", signature.Method, param.Name)
+			}
+
+			if omitted == "" {
+				omitted = param.Name
+			}
+
+			continue
+		}
+
+		if omitted != "" {
+			return nil, fmt.Errorf("%s: parameter %s requires parameter %s", signature.Method, param.Name, omitted)
+		}
+
+		if err := checkParamType(param, value); err != nil {
+			return nil, fmt.Errorf("%s: %w", signature.Method, err)
+		}
+
+		args = append(args, value)
+	}
+
+	var unknown []string
+
+	for name := range params {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("%s: unknown parameters %s", signature.Method, strings.Join(unknown, ", "))
+	}
+
+	return args, nil
+}
+
+// Validate returns an error if args, as passed to Client.Call, do not match the parameters of signature: if a
+// parameter is missing, if there are too many args, or if an arg is of the wrong type.
+func (signature Signature) Validate(args []any) error {
+	required := 0
+
+	for i, param := range signature.Params {
+		if !param.Optional {
+			required = i + 1
+		}
+	}
+
+	if len(args) < required {
+		return fmt.Errorf("%s: missing parameter %s", signature.Method, signature.Params[len(args)].Name)
+	}
+
+	if len(args) > len(signature.Params) {
+		return fmt.Errorf("%s: expected at most %d args, got %d", signature.Method, len(signature.Params), len(args))
+	}
+
+	for i, arg := range args {
+		if err := checkParamType(signature.Params[i], arg); err != nil {
+			return fmt.Errorf("%s: %w", signature.Method, err)
+		}
+	}
+
+	return nil
+}
+
+// checkParamType returns an error if value cannot be used for param.
+func checkParamType(param Param, value any) error {
+	switch param.Type {
+	case TypeInt, TypeString, TypeDouble, TypeBytes, TypeStruct, TypeArray:
+	default:
+		return fmt.Errorf("parameter %s: type %d not implemented", param.Name, param.Type)
+	}
+
+	var valueType uint8
+
+	switch v := value.(type) {
+	case int:
+		valueType = TypeInt
+	case string:
+		valueType = TypeString
+	case float64:
+		valueType = TypeDouble
+	case []byte:
+		valueType = TypeBytes
+	case Record:
+		valueType = v.Type
+	case *Record:
+		valueType = v.Type
+	case map[string]any, []StructItem:
+		valueType = TypeStruct
+	default:
+		if reflect.ValueOf(value).Kind() != reflect.Slice {
+			return fmt.Errorf("type error: parameter %s: type %T not implemented", param.Name, value)
+		}
+
+		valueType = TypeArray
+	}
+
+	if valueType != param.Type {
+		return fmt.Errorf("type error: parameter %s expects type %d, got %T", param.Name, param.Type, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package binrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamedArgs(t *testing.T) {
+	args, err := NamedArgs("dispatcher.set_state", map[string]any{
+		"address": "sip:10.0.0.1:5060",
+		"group":   1,
+		"state":   "ip",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []any{"ip", 1, "sip:10.0.0.1:5060"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+}
+
+func TestNamedArgsOptional(t *testing.T) {
+	args, err := NamedArgs("dispatcher.add", map[string]any{
+		"group":   1,
+		"address": "sip:10.0.0.1:5060",
+		"flags":   8,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []any{1, "sip:10.0.0.1:5060", 8}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v, got %v", expected, args)
+	}
+
+	// priority is omitted, so attrs cannot be positioned
+	_, err = NamedArgs("dispatcher.add", map[string]any{
+		"group":   1,
+		"address": "sip:10.0.0.1:5060",
+		"attrs":   "weight=50",
+	})
+
+	if err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestNamedArgsInvalid(t *testing.T) {
+	tests := map[string]map[string]any{
+		"missing":      {"htable": "ipban"},
+		"unknown":      {"htable": "ipban", "key": "1.2.3.4", "value": "1", "ttl": 60},
+		"wrong type":   {"htable": "ipban", "key": "1.2.3.4", "value": 1},
+		"unregistered": nil,
+	}
+
+	for name, params := range tests {
+		method := "htable.sets"
+
+		if params == nil {
+			method = "unknown.method"
+		}
+
+		if _, err := NamedArgs(method, params); err == nil {
+			t.Errorf("%s: error must be returned", name)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup collapses identical concurrent calls into one, see WithSingleflight.
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// flight is a call in progress, whose result is shared by identical calls.
+type flight struct {
+	// done is closed once records and err are set
+	done    chan struct{}
+	records []Record
+	err     error
+}
+
+// WithSingleflight makes identical concurrent calls share a single request: a call to an idempotent method (see
+// RegisterIdempotent) with the same args as a call in progress waits for its reply instead of sending its own. Several
+// collectors asking for tm.stats at the same time then cost a single request to Kamailio.
+//
+// The shared call goes through the interceptors and the hooks once, with the context of the first caller. If it fails
+// because that context is done, the other callers make their own call. Calls with a cookie set by WithCookie are
+// never shared.
+//
+// Records returned to several callers are shared between them and must not be modified.
+func WithSingleflight() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{flights: make(map[string]*flight)}
+	}
+}
+
+// do calls call, unless an identical call of method with args is in progress, whose result is then returned.
+func (g *flightGroup) do(ctx context.Context, call CallFunc, method string, args []any) ([]Record, error) {
+	key := cacheKey(method, args)
+
+	g.mu.Lock()
+
+	if f, ok := g.flights[key]; ok {
+		g.mu.Unlock()
+
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if isContextError(f.err) {
+			return call(ctx, method, args...)
+		}
+
+		return f.records, f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	g.flights[key] = f
+	g.mu.Unlock()
+
+	f.records, f.err = call(ctx, method, args...)
+
+	g.mu.Lock()
+	delete(g.flights, key)
+	g.mu.Unlock()
+
+	close(f.done)
+
+	return f.records, f.err
+}
+
+// isContextError reports whether err is the error of a done context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
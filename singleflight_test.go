@@ -0,0 +1,96 @@
+package binrpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleflight(t *testing.T) {
+	var requests atomic.Int32
+
+	client := serve(t, func(records []Record) []any {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+
+		method, _ := records[0].String()
+
+		return []any{method}
+	}, WithSingleflight())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			records, err := client.Call("tm.stats")
+
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if method, _ := records[0].String(); method != "tm.stats" {
+				t.Errorf(`expected "tm.stats", got "%s"`, method)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("expected 1 request, got %d", n)
+	}
+
+	// mutating methods are not shared
+	requests.Store(0)
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			client.Call("dispatcher.reload")
+		}()
+	}
+
+	wg.Wait()
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestSingleflightCanceled(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		time.Sleep(50 * time.Millisecond)
+
+		return []any{"ok"}
+	}, WithSingleflight())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	first := make(chan error, 1)
+
+	go func() {
+		_, err := client.CallContext(ctx, "core.uptime")
+		first <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+
+	// the first call times out: the second one makes its own call
+	if _, err := client.Call("core.uptime"); err != nil {
+		t.Error(err)
+	}
+
+	if err := <-first; err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
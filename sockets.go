@@ -0,0 +1,204 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// Socket is a listening socket of Kamailio, as returned by core.sockets_list.
+type Socket struct {
+	// Proto is the transport of the socket, such as "udp", "tcp", "tls", "sctp" or "ws".
+	Proto string
+
+	// Address is the address of the socket as configured by listen, which may be a host name, and IPAddresses its IP
+	// addresses. A multihomed SCTP socket has several.
+	Address     string
+	IPAddresses []netip.Addr
+
+	Port int
+
+	Multicast  bool
+	Multihomed bool
+
+	// Name is the name of the socket, set by the name attribute of listen.
+	Name string
+
+	// AdvertisedAddress and AdvertisedPort are set by the advertise attribute of listen, to be used in the SIP
+	// headers instead of the address of the socket, such as behind a NAT.
+	AdvertisedAddress string
+	AdvertisedPort    int
+}
+
+// String returns the socket as written in the configuration and in the Socket field of contacts and dialogs, such as
+// "udp:10.0.0.1:5060".
+func (socket Socket) String() string {
+	address := socket.Address
+
+	// IPv6 addresses are replied without brackets by some versions
+	if strings.Contains(address, ":") && !strings.HasPrefix(address, "[") {
+		address = "[" + address + "]"
+	}
+
+	return socket.Proto + ":" + address + ":" + strconv.Itoa(socket.Port)
+}
+
+// Alias is a domain that Kamailio considers as itself, set by alias in the configuration, as returned by
+// core.aliases_list.
+type Alias struct {
+	Proto   string
+	Address string
+
+	// Port is zero if the alias matches any port.
+	Port int
+}
+
+// CoreSocketsList calls core.sockets_list with caller and returns the listening sockets, such as to discover the SIP
+// listeners of Kamailio.
+func CoreSocketsList(ctx context.Context, caller Caller) ([]Socket, error) {
+	records, err := caller.CallContext(ctx, "core.sockets_list")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSocketsList(records)
+}
+
+// CoreAliasesList calls core.aliases_list with caller and returns the aliases.
+func CoreAliasesList(ctx context.Context, caller Caller) ([]Alias, error) {
+	records, err := caller.CallContext(ctx, "core.aliases_list")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseAliasesList(records)
+}
+
+// ParseSocketsList decodes the reply of core.sockets_list, a struct with a "socket" item per listening socket.
+func ParseSocketsList(records []Record) ([]Socket, error) {
+	var sockets []Socket
+
+	for _, record := range records {
+		for _, item := range record.GetAll("socket") {
+			socket, err := parseSocket(item)
+
+			if err != nil {
+				return nil, fmt.Errorf("socket %d: %w", len(sockets), err)
+			}
+
+			sockets = append(sockets, socket)
+		}
+	}
+
+	return sockets, nil
+}
+
+// parseSocket returns the socket of record, a struct.
+func parseSocket(record Record) (Socket, error) {
+	var socket Socket
+
+	items, err := record.StructItems()
+
+	if err != nil {
+		return socket, err
+	}
+
+	fields := map[string]any{
+		"proto":     &socket.Proto,
+		"address":   &socket.Address,
+		"port":      &socket.Port,
+		"mcast":     &socket.Multicast,
+		"mhomed":    &socket.Multihomed,
+		"sockname":  &socket.Name,
+		"advertise": &socket.AdvertisedAddress,
+		"advport":   &socket.AdvertisedPort,
+	}
+
+	for _, item := range items {
+		if item.Key == "ipaddress" {
+			addr, err := parseSocketAddr(item.Value)
+
+			if err != nil {
+				return socket, fmt.Errorf("%s: %w", item.Key, err)
+			}
+
+			socket.IPAddresses = append(socket.IPAddresses, addr)
+
+			continue
+		}
+
+		dest, ok := fields[item.Key]
+
+		if !ok {
+			continue
+		}
+
+		if err := item.Value.Scan(dest); err != nil {
+			return socket, fmt.Errorf("%s: %w", item.Key, err)
+		}
+	}
+
+	return socket, nil
+}
+
+// parseSocketAddr returns the IP address of record, a string with or without brackets for IPv6.
+func parseSocketAddr(record Record) (netip.Addr, error) {
+	s, err := record.String()
+
+	if err != nil {
+		return netip.Addr{}, err
+	}
+
+	return netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+}
+
+// ParseAliasesList decodes the reply of core.aliases_list, a struct with an "alias" item per alias.
+func ParseAliasesList(records []Record) ([]Alias, error) {
+	var aliases []Alias
+
+	for _, record := range records {
+		for _, item := range record.GetAll("alias") {
+			alias, err := parseAlias(item)
+
+			if err != nil {
+				return nil, fmt.Errorf("alias %d: %w", len(aliases), err)
+			}
+
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases, nil
+}
+
+// parseAlias returns the alias of record, a struct.
+func parseAlias(record Record) (Alias, error) {
+	var alias Alias
+
+	if proto, ok := record.Get("proto"); ok {
+		if err := proto.Scan(&alias.Proto); err != nil {
+			return alias, fmt.Errorf("proto: %w", err)
+		}
+	}
+
+	if address, ok := record.Get("address"); ok {
+		if err := address.Scan(&alias.Address); err != nil {
+			return alias, fmt.Errorf("address: %w", err)
+		}
+	}
+
+	// any port is "*"
+	if port, ok := record.Get("port"); ok {
+		if s, err := port.String(); err != nil || s != "*" {
+			if err = port.Scan(&alias.Port); err != nil {
+				return alias, fmt.Errorf("port: %w", err)
+			}
+		}
+	}
+
+	return alias, nil
+}
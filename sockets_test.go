@@ -0,0 +1,117 @@
+package binrpc
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestCoreSocketsList(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{[]StructItem{
+			{Key: "socket", Value: mustRecord(t, []StructItem{
+				{Key: "proto", Value: mustRecord(t, "udp")},
+				{Key: "address", Value: mustRecord(t, "10.0.0.1")},
+				{Key: "ipaddress", Value: mustRecord(t, "10.0.0.1")},
+				{Key: "port", Value: mustRecord(t, "5060")},
+				{Key: "mcast", Value: mustRecord(t, "no")},
+				{Key: "mhomed", Value: mustRecord(t, "no")},
+				{Key: "advertise", Value: mustRecord(t, "203.0.113.1")},
+				{Key: "advport", Value: mustRecord(t, 5060)},
+			})},
+			{Key: "socket", Value: mustRecord(t, []StructItem{
+				{Key: "proto", Value: mustRecord(t, "sctp")},
+				{Key: "address", Value: mustRecord(t, "sip.example.com")},
+				{Key: "ipaddress", Value: mustRecord(t, "10.0.0.1")},
+				{Key: "ipaddress", Value: mustRecord(t, "[2001:db8::1]")},
+				{Key: "port", Value: mustRecord(t, "5060")},
+				{Key: "mcast", Value: mustRecord(t, "no")},
+				{Key: "mhomed", Value: mustRecord(t, "yes")},
+				{Key: "sockname", Value: mustRecord(t, "sctp0")},
+			})},
+		}}
+	})
+
+	sockets, err := CoreSocketsList(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Socket{
+		{
+			Proto:             "udp",
+			Address:           "10.0.0.1",
+			IPAddresses:       []netip.Addr{netip.MustParseAddr("10.0.0.1")},
+			Port:              5060,
+			AdvertisedAddress: "203.0.113.1",
+			AdvertisedPort:    5060,
+		},
+		{
+			Proto:       "sctp",
+			Address:     "sip.example.com",
+			IPAddresses: []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("2001:db8::1")},
+			Port:        5060,
+			Multihomed:  true,
+			Name:        "sctp0",
+		},
+	}
+
+	if !reflect.DeepEqual(sockets, expected) {
+		t.Errorf("expected %+v, got %+v", expected, sockets)
+	}
+
+	if s := sockets[0].String(); s != "udp:10.0.0.1:5060" {
+		t.Errorf("expected udp:10.0.0.1:5060, got %s", s)
+	}
+
+	if s := (Socket{Proto: "tcp", Address: "::1", Port: 5060}).String(); s != "tcp:[::1]:5060" {
+		t.Errorf("expected tcp:[::1]:5060, got %s", s)
+	}
+}
+
+func TestParseSocketsListError(t *testing.T) {
+	records := []Record{mustRecord(t, []StructItem{
+		{Key: "socket", Value: mustRecord(t, []StructItem{
+			{Key: "ipaddress", Value: mustRecord(t, "not an address")},
+		})},
+	})}
+
+	if _, err := ParseSocketsList(records); err == nil {
+		t.Error("error must be returned")
+	}
+}
+
+func TestCoreAliasesList(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{[]StructItem{
+			{Key: "myself_callbacks", Value: mustRecord(t, "no")},
+			{Key: "alias", Value: mustRecord(t, []StructItem{
+				{Key: "proto", Value: mustRecord(t, "*")},
+				{Key: "address", Value: mustRecord(t, "example.com")},
+				{Key: "port", Value: mustRecord(t, "*")},
+			})},
+			{Key: "alias", Value: mustRecord(t, []StructItem{
+				{Key: "proto", Value: mustRecord(t, "tls")},
+				{Key: "address", Value: mustRecord(t, "sip.example.com")},
+				{Key: "port", Value: mustRecord(t, 5061)},
+			})},
+		}}
+	})
+
+	aliases, err := CoreAliasesList(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Alias{
+		{Proto: "*", Address: "example.com"},
+		{Proto: "tls", Address: "sip.example.com", Port: 5061},
+	}
+
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected %+v, got %+v", expected, aliases)
+	}
+}
@@ -0,0 +1,176 @@
+package binrpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TMStats is the reply of tm.stats, the transaction counters of the tm module.
+//
+//	var stats binrpc.TMStats
+//
+//	err = stats.Fetch(ctx, client)
+type TMStats struct {
+	// Current is the number of transactions in memory, and Waiting the number of transactions waiting to be deleted.
+	Current int64 `binrpc:"current"`
+	Waiting int64 `binrpc:"waiting"`
+
+	Total        int64 `binrpc:"total"`
+	TotalLocal   int64 `binrpc:"total_local"`
+	RplReceived  int64 `binrpc:"rpl_received"`
+	RplGenerated int64 `binrpc:"rpl_generated"`
+	RplSent      int64 `binrpc:"rpl_sent"`
+
+	// Replies6xx to Replies2xx are the number of transactions completed, by class of final reply.
+	Replies6xx int64 `binrpc:"6xx"`
+	Replies5xx int64 `binrpc:"5xx"`
+	Replies4xx int64 `binrpc:"4xx"`
+	Replies3xx int64 `binrpc:"3xx"`
+	Replies2xx int64 `binrpc:"2xx"`
+
+	Created     int64 `binrpc:"created"`
+	Freed       int64 `binrpc:"freed"`
+	DelayedFree int64 `binrpc:"delayed_free"`
+}
+
+// Fetch calls tm.stats with caller and stores the reply into s.
+func (s *TMStats) Fetch(ctx context.Context, caller Caller) error {
+	return fetchStats(ctx, caller, "tm.stats", s)
+}
+
+// SLStats is the reply of sl.stats, the number of replies sent by the sl module, by code and by class.
+type SLStats struct {
+	Replies200 int64 `binrpc:"200"`
+	Replies202 int64 `binrpc:"202"`
+	Replies2xx int64 `binrpc:"2xx"`
+
+	Replies300 int64 `binrpc:"300"`
+	Replies301 int64 `binrpc:"301"`
+	Replies302 int64 `binrpc:"302"`
+	Replies3xx int64 `binrpc:"3xx"`
+
+	Replies400 int64 `binrpc:"400"`
+	Replies401 int64 `binrpc:"401"`
+	Replies403 int64 `binrpc:"403"`
+	Replies404 int64 `binrpc:"404"`
+	Replies407 int64 `binrpc:"407"`
+	Replies408 int64 `binrpc:"408"`
+	Replies483 int64 `binrpc:"483"`
+	Replies4xx int64 `binrpc:"4xx"`
+
+	Replies500 int64 `binrpc:"500"`
+	Replies5xx int64 `binrpc:"5xx"`
+
+	Replies6xx int64 `binrpc:"6xx"`
+
+	// RepliesXxx is the number of replies whose code is not in the classes above.
+	RepliesXxx int64 `binrpc:"xxx"`
+}
+
+// Fetch calls sl.stats with caller and stores the reply into s.
+func (s *SLStats) Fetch(ctx context.Context, caller Caller) error {
+	return fetchStats(ctx, caller, "sl.stats", s)
+}
+
+// fetchStats calls method with caller and stores the reply into v.
+func fetchStats(ctx context.Context, caller Caller, method string, v any) error {
+	records, err := caller.CallContext(ctx, method)
+
+	if err != nil {
+		return err
+	}
+
+	return Unmarshal(records, v)
+}
+
+// GetStatistics calls stats.get_statistics with caller for groups, such as "shmem:" for a group or "tm:current" for a
+// single statistic, or "all" if groups is empty, and returns the values of the statistics, see ParseStatistics.
+func GetStatistics(ctx context.Context, caller Caller, groups ...string) (map[string]int64, error) {
+	return callStatistics(ctx, caller, "stats.get_statistics", groups)
+}
+
+// FetchStatistics is like GetStatistics, calling stats.fetch, whose reply is a struct instead of strings.
+func FetchStatistics(ctx context.Context, caller Caller, groups ...string) (map[string]int64, error) {
+	return callStatistics(ctx, caller, "stats.fetch", groups)
+}
+
+// callStatistics calls method with groups as args, or "all", and returns the statistics replied.
+func callStatistics(ctx context.Context, caller Caller, method string, groups []string) (map[string]int64, error) {
+	args := []any{"all"}
+
+	if len(groups) > 0 {
+		args = make([]any, len(groups))
+
+		for i, group := range groups {
+			args[i] = group
+		}
+	}
+
+	records, err := caller.CallContext(ctx, method, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseStatistics(records)
+}
+
+// ParseStatistics decodes the reply of stats.get_statistics, strings like "shmem:free_size = 1024", and of stats.fetch
+// and stats.fetchn, structs with items like "shmem.free_size": "1024". The statistics are keyed by "group.name" in
+// both cases, such as "shmem.free_size".
+func ParseStatistics(records []Record) (map[string]int64, error) {
+	statistics := make(map[string]int64)
+
+	return statistics, parseStatistics(records, statistics)
+}
+
+// parseStatistics adds the statistics found in records, and in their arrays, to statistics.
+func parseStatistics(records []Record, statistics map[string]int64) error {
+	for _, record := range records {
+		switch record.Type {
+		case TypeArray:
+			elements, _ := record.Array()
+
+			if err := parseStatistics(elements, statistics); err != nil {
+				return err
+			}
+		case TypeString:
+			line, _ := record.String()
+			key, value, found := strings.Cut(line, "=")
+
+			if !found {
+				continue
+			}
+
+			group, name, found := strings.Cut(strings.TrimSpace(key), ":")
+
+			if !found {
+				continue
+			}
+
+			n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+
+			if err != nil {
+				return fmt.Errorf("%s:%s: %w", group, name, err)
+			}
+
+			statistics[group+"."+name] = n
+		case TypeStruct:
+			items, _ := record.StructItems()
+
+			for _, item := range items {
+				var n int64
+
+				if err := item.Value.Scan(&n); err != nil {
+					return fmt.Errorf("%s: %w", item.Key, err)
+				}
+
+				statistics[item.Key] = n
+			}
+		}
+	}
+
+	return nil
+}
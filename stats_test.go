@@ -0,0 +1,158 @@
+package binrpc
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFetchStats(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.Handle("tm.stats", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{[]StructItem{
+			{Key: "current", Value: mustRecord(t, 3)},
+			{Key: "total", Value: mustRecord(t, 1200)},
+			{Key: "total_local", Value: mustRecord(t, 20)},
+			{Key: "6xx", Value: mustRecord(t, 1)},
+			{Key: "5xx", Value: mustRecord(t, 2)},
+			{Key: "4xx", Value: mustRecord(t, 30)},
+			{Key: "2xx", Value: mustRecord(t, 1000)},
+			{Key: "delayed_free", Value: mustRecord(t, 4)},
+		}}, nil
+	})
+
+	server.Handle("sl.stats", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{[]StructItem{
+			{Key: "200", Value: mustRecord(t, 10)},
+			{Key: "404", Value: mustRecord(t, 3)},
+			{Key: "xxx", Value: mustRecord(t, 1)},
+		}}, nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	var tm TMStats
+
+	if err = tm.Fetch(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTM := TMStats{
+		Current:     3,
+		Total:       1200,
+		TotalLocal:  20,
+		Replies6xx:  1,
+		Replies5xx:  2,
+		Replies4xx:  30,
+		Replies2xx:  1000,
+		DelayedFree: 4,
+	}
+
+	if tm != expectedTM {
+		t.Errorf("expected %+v, got %+v", expectedTM, tm)
+	}
+
+	var sl SLStats
+
+	if err = sl.Fetch(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSL := SLStats{Replies200: 10, Replies404: 3, RepliesXxx: 1}
+
+	if sl != expectedSL {
+		t.Errorf("expected %+v, got %+v", expectedSL, sl)
+	}
+}
+
+func TestGetStatistics(t *testing.T) {
+	var mu sync.Mutex
+
+	var args []string
+
+	client := serve(t, func(records []Record) []any {
+		mu.Lock()
+		args = args[:0]
+
+		for _, record := range records[1:] {
+			arg, _ := record.String()
+			args = append(args, arg)
+		}
+
+		mu.Unlock()
+
+		switch method, _ := records[0].String(); method {
+		case "stats.get_statistics":
+			return []any{
+				[]any{"shmem:free_size = 60000000", "shmem:fragments = 12"},
+				"tm:current = 3",
+			}
+		case "stats.fetch":
+			return []any{map[string]any{"shmem.free_size": "60000000", "tm.current": 3}}
+		}
+
+		return nil
+	})
+
+	expected := map[string]int64{"shmem.free_size": 60000000, "shmem.fragments": 12, "tm.current": 3}
+	statistics, err := GetStatistics(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(statistics, expected) {
+		t.Errorf("expected %v, got %v", expected, statistics)
+	}
+
+	mu.Lock()
+
+	if !reflect.DeepEqual(args, []string{"all"}) {
+		t.Errorf("expected [all], got %v", args)
+	}
+
+	mu.Unlock()
+
+	statistics, err = FetchStatistics(context.Background(), client, "shmem:", "tm:current")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	delete(expected, "shmem.fragments")
+
+	if !reflect.DeepEqual(statistics, expected) {
+		t.Errorf("expected %v, got %v", expected, statistics)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !reflect.DeepEqual(args, []string{"shmem:", "tm:current"}) {
+		t.Errorf("expected [shmem: tm:current], got %v", args)
+	}
+}
+
+func TestParseStatisticsError(t *testing.T) {
+	if _, err := ParseStatistics([]Record{mustRecord(t, "shmem:free_size = a lot")}); err == nil {
+		t.Error("error must be returned")
+	}
+}
@@ -0,0 +1,120 @@
+package binrpc
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// RecordIterator decodes the records of a packet one at a time, as they are read, see ReadPacketStream.
+type RecordIterator struct {
+	header  *Header
+	payload *payloadReader
+	err     error
+}
+
+// payloadReader reads the payload of a packet from r, and reports the bytes left to decodeRecord, which checks the
+// size of records against it before allocating their value.
+type payloadReader struct {
+	r    io.Reader
+	left int
+}
+
+func (p *payloadReader) Read(b []byte) (int, error) {
+	if p.left <= 0 {
+		return 0, io.EOF
+	}
+
+	if len(b) > p.left {
+		b = b[:p.left]
+	}
+
+	n, err := p.r.Read(b)
+	p.left -= n
+
+	if err == io.EOF && p.left > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+// Len returns the number of bytes of the payload left to read.
+func (p *payloadReader) Len() int {
+	return p.left
+}
+
+// ReadPacketStream reads the header of a packet from r, and returns an iterator decoding its records as they are read,
+// instead of reading the whole payload first like ReadPacket. It is meant for very large replies, such as the dump of
+// 100k contacts. If expectedCookie is not zero, it verifies the cookie.
+//
+// Only the top level records are decoded one at a time: a struct or an array is decoded as a whole. The payload is
+// buffered, but no byte past the packet is read from r, so that the next packet can be read from it.
+//
+// If Kamailio replies with a fault, the error is an *RPCError.
+func ReadPacketStream(r io.Reader, expectedCookie uint32) (*RecordIterator, error) {
+	header, err := ReadHeader(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedCookie != 0 && expectedCookie != header.Cookie {
+		return nil, errors.New("expected cookie did not match")
+	}
+
+	// the payload is buffered up to its end only
+	payload := bufio.NewReader(io.LimitReader(r, int64(header.PayloadLength)))
+
+	it := &RecordIterator{
+		header:  header,
+		payload: &payloadReader{r: payload, left: header.PayloadLength},
+	}
+
+	if header.Fault() {
+		var records []Record
+
+		for {
+			record, err := it.Next()
+
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			records = append(records, *record)
+		}
+
+		return nil, faultError(records)
+	}
+
+	return it, nil
+}
+
+// Header returns the header of the packet.
+func (it *RecordIterator) Header() *Header {
+	return it.header
+}
+
+// Next decodes and returns the next record. It returns io.EOF once all the records of the packet are read, and the
+// same error for all the calls following an error.
+func (it *RecordIterator) Next() (*Record, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+
+	if it.payload.left == 0 {
+		it.err = io.EOF
+		return nil, it.err
+	}
+
+	record, err := ReadRecord(it.payload)
+
+	if err != nil {
+		it.err = err
+		return nil, err
+	}
+
+	return record, nil
+}
@@ -0,0 +1,147 @@
+package binrpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestReadPacketStream(t *testing.T) {
+	var buf bytes.Buffer
+
+	contacts := make([]any, 1000)
+
+	for i := range contacts {
+		contacts[i] = map[string]any{"aor": fmt.Sprintf("sip:%d@example.com", i), "expires": i}
+	}
+
+	response, err := NewResponse(&Request{Cookie: 42}, contacts...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response.WriteTo(&buf)
+
+	it, err := ReadPacketStream(&buf, 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if it.Header().Cookie != 42 {
+		t.Errorf("expected cookie 42, got %d", it.Header().Cookie)
+	}
+
+	var count int
+
+	for {
+		record, err := it.Next()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		var contact struct {
+			AOR     string
+			Expires int
+		}
+
+		if err = record.Scan(&contact); err != nil {
+			t.Fatal(err)
+		}
+
+		if contact.Expires != count {
+			t.Errorf("expected expires %d, got %d", count, contact.Expires)
+		}
+
+		count++
+	}
+
+	if count != len(contacts) {
+		t.Errorf("expected %d records, got %d", len(contacts), count)
+	}
+
+	if _, err = it.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReadPacketStreamNextPacket(t *testing.T) {
+	var buf bytes.Buffer
+
+	for cookie := uint32(1); cookie <= 2; cookie++ {
+		response, err := NewResponse(&Request{Cookie: cookie}, "first", "second")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response.WriteTo(&buf)
+	}
+
+	it, err := ReadPacketStream(&buf, 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		if _, err = it.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := ReadPacket(&buf, 2)
+
+	if err != nil {
+		t.Fatalf("the next packet must be left unread: %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+}
+
+func TestReadPacketStreamErrors(t *testing.T) {
+	var buf bytes.Buffer
+
+	response, _ := NewResponse(&Request{Cookie: 42}, "a", "b")
+	response.WriteTo(&buf)
+
+	// a truncated packet
+	it, err := ReadPacketStream(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = it.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = it.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	if _, err = ReadPacketStream(bytes.NewReader(buf.Bytes()), 43); err == nil {
+		t.Error("error must be returned")
+	}
+
+	buf.Reset()
+
+	response, _ = NewFaultResponse(&Request{Cookie: 42}, &RPCError{Code: 404, Message: "command not found"})
+	response.WriteTo(&buf)
+
+	var fault *RPCError
+
+	if _, err = ReadPacketStream(&buf, 42); !errors.As(err, &fault) || fault.Code != 404 {
+		t.Errorf("expected a 404 fault, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package binrpc
+
+// Get returns the value of the first item of a struct value with key. ok is false if there is no such item, or if
+// the record is not a struct.
+//
+// Keys may be found several times in a struct, such as "SET" in the reply of dispatcher.list: see GetAll.
+func (record *Record) Get(key string) (value Record, ok bool) {
+	if record.Type != TypeStruct {
+		return Record{}, false
+	}
+
+	for _, item := range record.Value.([]StructItem) {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+
+	return Record{}, false
+}
+
+// GetAll returns the values of the items of a struct value with key, in order. It returns nil if there is no such
+// item, or if the record is not a struct.
+func (record *Record) GetAll(key string) []Record {
+	if record.Type != TypeStruct {
+		return nil
+	}
+
+	var values []Record
+
+	for _, item := range record.Value.([]StructItem) {
+		if item.Key == key {
+			values = append(values, item.Value)
+		}
+	}
+
+	return values
+}
+
+// Keys returns the keys of a struct value in the order they are first found, each key once even if found several
+// times. It returns nil if the record is not a struct.
+func (record *Record) Keys() []string {
+	if record.Type != TypeStruct {
+		return nil
+	}
+
+	items := record.Value.([]StructItem)
+	keys := make([]string, 0, len(items))
+	seen := make(map[string]bool, len(items))
+
+	for _, item := range items {
+		if !seen[item.Key] {
+			seen[item.Key] = true
+			keys = append(keys, item.Key)
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,45 @@
+package binrpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecordGet(t *testing.T) {
+	record := Record{
+		Type: TypeStruct,
+		Value: []StructItem{
+			{Key: "NRSETS", Value: Record{Type: TypeInt, Value: 2}},
+			{Key: "SET", Value: Record{Type: TypeString, Value: "a"}},
+			{Key: "SET", Value: Record{Type: TypeString, Value: "b"}},
+		},
+	}
+
+	if value, ok := record.Get("SET"); !ok || value.Value != "a" {
+		t.Errorf(`expected "a", got %v (%t)`, value.Value, ok)
+	}
+
+	if _, ok := record.Get("missing"); ok {
+		t.Error("missing key must not be found")
+	}
+
+	values := record.GetAll("SET")
+
+	if len(values) != 2 || values[0].Value != "a" || values[1].Value != "b" {
+		t.Errorf(`expected "a" and "b", got %v`, values)
+	}
+
+	if values = record.GetAll("missing"); values != nil {
+		t.Errorf("expected nil, got %v", values)
+	}
+
+	if keys := record.Keys(); !reflect.DeepEqual(keys, []string{"NRSETS", "SET"}) {
+		t.Errorf("expected [NRSETS SET], got %v", keys)
+	}
+
+	notStruct := Record{Type: TypeInt, Value: 1}
+
+	if _, ok := notStruct.Get("SET"); ok || notStruct.GetAll("SET") != nil || notStruct.Keys() != nil {
+		t.Error("a record that is not a struct must have no items")
+	}
+}
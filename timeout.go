@@ -0,0 +1,78 @@
+package binrpc
+
+import (
+	"context"
+	"time"
+)
+
+type callTimeoutsKey struct{}
+
+// Timeouts limit the steps of a call separately, as dialing a dead host and waiting for the reply of a large dump
+// need very different limits. Zero values mean no limit.
+//
+// Timeouts apply in addition to the one set by WithTimeout and to the deadline of the context of the call: the
+// earliest deadline wins.
+type Timeouts struct {
+	// Dial limits opening a connection, by Dial and when reconnecting.
+	Dial time.Duration
+
+	// Write limits writing the request.
+	Write time.Duration
+
+	// Read limits waiting for the reply, once the request is written.
+	Read time.Duration
+}
+
+// WithTimeouts sets the timeouts of the steps of each call. See WithCallTimeouts to override them for one call.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(c *Client) {
+		c.timeouts = timeouts
+	}
+}
+
+// WithCallTimeouts returns a copy of ctx carrying timeouts. When passed to CallContext, the non-zero timeouts
+// override those set by WithTimeouts:
+//
+//	ctx = binrpc.WithCallTimeouts(ctx, binrpc.Timeouts{Read: time.Minute})
+//	records, err := client.CallContext(ctx, "ul.dump")
+func WithCallTimeouts(ctx context.Context, timeouts Timeouts) context.Context {
+	return context.WithValue(ctx, callTimeoutsKey{}, timeouts)
+}
+
+// callTimeouts returns the timeouts set by WithCallTimeouts, or zero timeouts.
+func callTimeouts(ctx context.Context) Timeouts {
+	timeouts, _ := ctx.Value(callTimeoutsKey{}).(Timeouts)
+
+	return timeouts
+}
+
+// override returns t with the non-zero timeouts of overrides.
+func (t Timeouts) override(overrides Timeouts) Timeouts {
+	if overrides.Dial > 0 {
+		t.Dial = overrides.Dial
+	}
+
+	if overrides.Write > 0 {
+		t.Write = overrides.Write
+	}
+
+	if overrides.Read > 0 {
+		t.Read = overrides.Read
+	}
+
+	return t
+}
+
+// stepDeadline returns the deadline of a step limited by timeout, which cannot exceed the deadline of the call if
+// hasDeadline is set. It returns the zero time if there is no limit.
+func stepDeadline(deadline time.Time, hasDeadline bool, timeout time.Duration) time.Time {
+	if timeout > 0 && (!hasDeadline || time.Now().Add(timeout).Before(deadline)) {
+		return time.Now().Add(timeout)
+	}
+
+	if hasDeadline {
+		return deadline
+	}
+
+	return time.Time{}
+}
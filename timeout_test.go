@@ -0,0 +1,54 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// blockingDialer never opens a connection.
+type blockingDialer struct{}
+
+func (blockingDialer) Dial(network, address string) (net.Conn, error) {
+	select {}
+}
+
+func TestWithTimeouts(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		if method, _ := records[0].String(); method == "ul.dump" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		return []any{"ok"}
+	}, WithTimeouts(Timeouts{Write: time.Second, Read: 50 * time.Millisecond}))
+
+	if _, err := client.Call("core.uptime"); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := client.Call("ul.dump"); !isTimeout(err) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	ctx := WithCallTimeouts(context.Background(), Timeouts{Read: time.Second})
+
+	if _, err := client.CallContext(ctx, "ul.dump"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDialTimeout(t *testing.T) {
+	start := time.Now()
+
+	_, err := DialWith(blockingDialer{}, "tcp", "10.0.0.1:2049", WithTimeouts(Timeouts{Dial: 50 * time.Millisecond}))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the dial to time out quickly, took %s", elapsed)
+	}
+}
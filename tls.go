@@ -0,0 +1,21 @@
+package binrpc
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// DialTLS is like Dial, but wraps the connection in TLS with config, for a ctl socket exposed across network
+// boundaries behind a TLS terminator, such as stunnel. For mutual authentication, config.Certificates holds the
+// certificate of the client. If config.ServerName is empty, it is taken from address.
+//
+// As TLS needs a stream, network must be "tcp", "tcp4", "tcp6" or "unix".
+func DialTLS(network, address string, config *tls.Config, options ...Option) (*Client, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+	default:
+		return nil, fmt.Errorf("network %s does not support TLS", network)
+	}
+
+	return DialWith(&tls.Dialer{Config: config}, network, address, options...)
+}
@@ -0,0 +1,121 @@
+package binrpc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a certificate for 127.0.0.1, signed by parent (self-signed if nil), usable by clients and
+// servers.
+func testCertificate(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := template, any(key)
+
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, _ := x509.ParseCertificate(der)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestDialTLS(t *testing.T) {
+	ca := testCertificate(t, "ca", nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, "server", &ca)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go handle(conn, echo)
+		}
+	}()
+
+	address := listener.Addr().String()
+
+	client, err := DialTLS("tcp", address, &tls.Config{
+		Certificates: []tls.Certificate{testCertificate(t, "client", &ca)},
+		RootCAs:      pool,
+	}, WithTimeout(5*time.Second))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	records, err := client.Call("core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); value != "core.echo" {
+		t.Errorf(`expected "core.echo", got "%s"`, value)
+	}
+
+	// without a client certificate, the server rejects the handshake
+	client, err = DialTLS("tcp", address, &tls.Config{RootCAs: pool}, WithTimeout(5*time.Second))
+
+	if err == nil {
+		_, err = client.Call("core.echo")
+		client.Close()
+	}
+
+	if err == nil {
+		t.Error("error must be returned without a client certificate")
+	}
+
+	if _, err = DialTLS("udp", address, &tls.Config{RootCAs: pool}); err == nil {
+		t.Error("error must be returned for a datagram network")
+	}
+}
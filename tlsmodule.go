@@ -0,0 +1,111 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TLSModuleInfo is the state of the TLS connections of the tls module, as returned by tls.info.
+type TLSModuleInfo struct {
+	MaxConnections    int `binrpc:"max_connections"`
+	OpenedConnections int `binrpc:"opened_connections"`
+
+	// ClearTextWriteQueuedBytes is the number of bytes waiting to be encrypted and written to the connections.
+	ClearTextWriteQueuedBytes int `binrpc:"clear_text_write_queued_bytes"`
+}
+
+// TLSConnection is a TLS connection, as returned by tls.list.
+type TLSConnection struct {
+	ID int `binrpc:"id"`
+
+	// Timeout is the number of seconds before the connection is closed if idle.
+	Timeout int `binrpc:"timeout"`
+
+	SrcIP   string `binrpc:"src_ip"`
+	SrcPort int    `binrpc:"src_port"`
+	DstIP   string `binrpc:"dst_ip"`
+	DstPort int    `binrpc:"dst_port"`
+
+	// Cipher is the description of the cipher of the connection, such as "ECDHE-RSA-AES256-GCM-SHA384 TLSv1.2
+	// Kx=ECDH Au=RSA Enc=AESGCM(256) Mac=AEAD", or "unknown" before the handshake.
+	Cipher string `binrpc:"cipher"`
+
+	// CTWQSize is the number of clear text bytes queued for writing, and EncRdBuf the number of encrypted bytes read
+	// and not decrypted yet.
+	CTWQSize int `binrpc:"ct_wq_size"`
+	EncRdBuf int `binrpc:"enc_rd_buf"`
+	Flags    int `binrpc:"flags"`
+
+	// State is the state of the connection, such as "established", "connecting", "accepting", "init" or
+	// "pre-init".
+	State string `binrpc:"state"`
+}
+
+// TLSReloadError is returned by TLSReload when Kamailio rejects the new TLS configuration, such as a missing
+// certificate or a key not matching it. The previous configuration is still in use. Fault is the fault replied.
+type TLSReloadError struct {
+	Fault *RPCError
+}
+
+func (e *TLSReloadError) Error() string {
+	return fmt.Sprintf("tls.reload: %v", e.Fault)
+}
+
+func (e *TLSReloadError) Unwrap() error {
+	return e.Fault
+}
+
+// TLSInfo calls tls.info with caller and returns the state of the TLS connections.
+func TLSInfo(ctx context.Context, caller Caller) (*TLSModuleInfo, error) {
+	records, err := caller.CallContext(ctx, "tls.info")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var info TLSModuleInfo
+
+	if err = Unmarshal(records, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// TLSList calls tls.list with caller and returns the TLS connections.
+func TLSList(ctx context.Context, caller Caller) ([]TLSConnection, error) {
+	records, err := caller.CallContext(ctx, "tls.list")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var connections []TLSConnection
+
+	if err = Unmarshal(records, &connections); err != nil {
+		return nil, err
+	}
+
+	return connections, nil
+}
+
+// TLSReload calls tls.reload with caller to reload the TLS configuration file, and the certificates and keys it
+// refers to, such as after a certificate renewal. If Kamailio rejects the configuration, a *TLSReloadError is
+// returned.
+func TLSReload(ctx context.Context, caller Caller) error {
+	records, err := caller.CallContext(ctx, "tls.reload")
+
+	var fault *RPCError
+
+	if errors.As(err, &fault) {
+		return &TLSReloadError{Fault: fault}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the reply is "Ok. TLS configuration reloaded."
+	return checkOK("tls.reload", records)
+}
@@ -0,0 +1,122 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestTLSModule(t *testing.T) {
+	var mu sync.Mutex
+
+	reload := []any{"Ok. TLS configuration reloaded."}
+
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "tls.info":
+			return []any{map[string]any{
+				"max_connections":               2048,
+				"opened_connections":            2,
+				"clear_text_write_queued_bytes": 128,
+			}}
+		case "tls.list":
+			return []any{
+				map[string]any{
+					"id": 3, "timeout": 120, "src_ip": "203.0.113.7", "src_port": 40312, "dst_ip": "10.0.0.1",
+					"dst_port": 5061, "cipher": "TLS_AES_256_GCM_SHA384 TLSv1.3 Kx=any Au=any Enc=AESGCM(256) Mac=AEAD",
+					"ct_wq_size": 0, "enc_rd_buf": 0, "flags": 4, "state": "established",
+				},
+				map[string]any{
+					"id": 4, "timeout": 118, "src_ip": "203.0.113.8", "src_port": 40313, "dst_ip": "10.0.0.1",
+					"dst_port": 5061, "cipher": "unknown", "ct_wq_size": 0, "enc_rd_buf": 0, "flags": 0,
+					"state": "pre-init",
+				},
+			}
+		case "tls.reload":
+			mu.Lock()
+			defer mu.Unlock()
+
+			return reload
+		}
+
+		return nil
+	})
+
+	ctx := context.Background()
+
+	info, err := TLSInfo(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (TLSModuleInfo{2048, 2, 128}); *info != expected {
+		t.Errorf("expected %+v, got %+v", expected, *info)
+	}
+
+	connections, err := TLSList(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TLSConnection{
+		{
+			ID:      3,
+			Timeout: 120,
+			SrcIP:   "203.0.113.7",
+			SrcPort: 40312,
+			DstIP:   "10.0.0.1",
+			DstPort: 5061,
+			Cipher:  "TLS_AES_256_GCM_SHA384 TLSv1.3 Kx=any Au=any Enc=AESGCM(256) Mac=AEAD",
+			Flags:   4,
+			State:   "established",
+		},
+		{
+			ID:      4,
+			Timeout: 118,
+			SrcIP:   "203.0.113.8",
+			SrcPort: 40313,
+			DstIP:   "10.0.0.1",
+			DstPort: 5061,
+			Cipher:  "unknown",
+			State:   "pre-init",
+		},
+	}
+
+	if !reflect.DeepEqual(connections, expected) {
+		t.Errorf("expected %+v, got %+v", expected, connections)
+	}
+
+	if err = TLSReload(ctx, client); err != nil {
+		t.Error(err)
+	}
+
+	mu.Lock()
+	reload = []any{&RPCError{Code: 500, Message: "Error while fixing TLS configuration (consult server log)"}}
+	mu.Unlock()
+
+	err = TLSReload(ctx, client)
+
+	var reloadErr *TLSReloadError
+
+	if !errors.As(err, &reloadErr) || reloadErr.Fault.Code != 500 {
+		t.Errorf("expected *TLSReloadError, got %v", err)
+	}
+
+	var rpcErr *RPCError
+
+	if !errors.As(err, &rpcErr) {
+		t.Errorf("expected the fault to be unwrapped, got %v", err)
+	}
+
+	mu.Lock()
+	reload = []any{"TLS configuration reload not available"}
+	mu.Unlock()
+
+	if err = TLSReload(ctx, client); err == nil || errors.As(err, &reloadErr) {
+		t.Errorf("expected an unexpected reply error, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package binrpc
+
+import (
+	"context"
+)
+
+// TCPInfo is the state of the TCP connections, as returned by core.tcp_info. The counts include the TLS connections.
+type TCPInfo struct {
+	// Readers is the number of processes reading the TCP connections.
+	Readers int `binrpc:"readers"`
+
+	MaxConnections       int `binrpc:"max_connections"`
+	MaxTLSConnections    int `binrpc:"max_tls_connections"`
+	OpenedConnections    int `binrpc:"opened_connections"`
+	OpenedTLSConnections int `binrpc:"opened_tls_connections"`
+
+	// WriteQueuedBytes is the number of bytes waiting to be written to the connections, with async writes.
+	WriteQueuedBytes int `binrpc:"write_queued_bytes"`
+}
+
+// TCPOptions is the TCP configuration, as returned by core.tcp_options. Durations are in seconds.
+type TCPOptions struct {
+	ConnectTimeout     int `binrpc:"connect_timeout"`
+	SendTimeout        int `binrpc:"send_timeout"`
+	ConnectionLifetime int `binrpc:"connection_lifetime"`
+
+	// MaxConnections and MaxTLSConnections are the soft limits, which can be changed at runtime.
+	MaxConnections    int `binrpc:"max_connections(soft)"`
+	MaxTLSConnections int `binrpc:"max_tls_connections(soft)"`
+
+	NoConnect   bool `binrpc:"no_connect"`
+	FDCache     bool `binrpc:"fd_cache"`
+	Async       bool `binrpc:"async"`
+	ConnectWait bool `binrpc:"connect_wait"`
+
+	// ConnWQMax and WQMax are the maximum number of bytes queued for writing per connection and in total.
+	ConnWQMax int `binrpc:"conn_wq_max"`
+	WQMax     int `binrpc:"wq_max"`
+
+	DeferAccept int  `binrpc:"defer_accept"`
+	DelayedAck  bool `binrpc:"delayed_ack"`
+	SynCnt      int  `binrpc:"syncnt"`
+	Linger2     int  `binrpc:"linger2"`
+
+	KeepAlive bool `binrpc:"keepalive"`
+	KeepIdle  int  `binrpc:"keepidle"`
+	KeepIntvl int  `binrpc:"keepintvl"`
+	KeepCnt   int  `binrpc:"keepcnt"`
+	CRLFPing  bool `binrpc:"crlf_ping"`
+
+	AcceptAliases     bool `binrpc:"accept_aliases"`
+	AliasFlags        int  `binrpc:"alias_flags"`
+	NewConnAliasFlags int  `binrpc:"new_conn_alias_flags"`
+	AcceptNoCL        bool `binrpc:"accept_no_cl"`
+	ReusePort         bool `binrpc:"reuse_port"`
+
+	ReadBufSize int `binrpc:"rd_buf_size"`
+	WQBlockSize int `binrpc:"wq_blk_size"`
+}
+
+// UDP4RawInfo is the configuration of the raw sockets used to send UDP over IPv4, as returned by core.udp4_raw_info.
+type UDP4RawInfo struct {
+	// Enabled is 1 if raw sockets are used, 0 if not, and -1 if they are used when available.
+	Enabled int `binrpc:"udp4_raw"`
+
+	MTU int `binrpc:"udp4_raw_mtu"`
+	TTL int `binrpc:"udp4_raw_ttl"`
+}
+
+// CoreTCPInfo calls core.tcp_info with caller and returns the state of the TCP connections. If TCP is disabled,
+// Kamailio replies with a fault, returned as an *RPCError.
+func CoreTCPInfo(ctx context.Context, caller Caller) (*TCPInfo, error) {
+	records, err := caller.CallContext(ctx, "core.tcp_info")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var info TCPInfo
+
+	if err = Unmarshal(records, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// CoreTCPOptions calls core.tcp_options with caller and returns the TCP configuration.
+func CoreTCPOptions(ctx context.Context, caller Caller) (*TCPOptions, error) {
+	records, err := caller.CallContext(ctx, "core.tcp_options")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var options TCPOptions
+
+	if err = Unmarshal(records, &options); err != nil {
+		return nil, err
+	}
+
+	return &options, nil
+}
+
+// CoreUDP4RawInfo calls core.udp4_raw_info with caller and returns the configuration of the raw UDP sockets. If raw
+// sockets are not supported, Kamailio replies with a fault, returned as an *RPCError.
+func CoreUDP4RawInfo(ctx context.Context, caller Caller) (*UDP4RawInfo, error) {
+	records, err := caller.CallContext(ctx, "core.udp4_raw_info")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var info UDP4RawInfo
+
+	if err = Unmarshal(records, &info); err != nil {
+		return nil, err
+	}
+
+	// ints are read unsigned: -1 is read as 0xffffffff
+	info.Enabled = int(int32(info.Enabled))
+
+	return &info, nil
+}
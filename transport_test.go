@@ -0,0 +1,102 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCoreTransportInfo(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		switch method, _ := records[0].String(); method {
+		case "core.tcp_info":
+			return []any{map[string]any{
+				"readers":                8,
+				"max_connections":        4096,
+				"max_tls_connections":    2048,
+				"opened_connections":     12,
+				"opened_tls_connections": 3,
+				"write_queued_bytes":     512,
+			}}
+		case "core.tcp_options":
+			return []any{[]StructItem{
+				{Key: "connect_timeout", Value: mustRecord(t, 10)},
+				{Key: "send_timeout", Value: mustRecord(t, 10)},
+				{Key: "connection_lifetime", Value: mustRecord(t, 120)},
+				{Key: "max_connections(soft)", Value: mustRecord(t, 4096)},
+				{Key: "async", Value: mustRecord(t, 1)},
+				{Key: "conn_wq_max", Value: mustRecord(t, 32768)},
+				{Key: "keepalive", Value: mustRecord(t, 1)},
+				{Key: "keepidle", Value: mustRecord(t, 0)},
+				{Key: "accept_aliases", Value: mustRecord(t, 0)},
+				{Key: "rd_buf_size", Value: mustRecord(t, 16384)},
+			}}
+		case "core.udp4_raw_info":
+			return []any{map[string]any{"udp4_raw": -1, "udp4_raw_mtu": 1500, "udp4_raw_ttl": 63}}
+		}
+
+		return []any{&RPCError{Code: 500, Message: "tcp support disabled"}}
+	})
+
+	info, err := CoreTCPInfo(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedInfo := TCPInfo{
+		Readers:              8,
+		MaxConnections:       4096,
+		MaxTLSConnections:    2048,
+		OpenedConnections:    12,
+		OpenedTLSConnections: 3,
+		WriteQueuedBytes:     512,
+	}
+
+	if *info != expectedInfo {
+		t.Errorf("expected %+v, got %+v", expectedInfo, *info)
+	}
+
+	options, err := CoreTCPOptions(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOptions := TCPOptions{
+		ConnectTimeout:     10,
+		SendTimeout:        10,
+		ConnectionLifetime: 120,
+		MaxConnections:     4096,
+		Async:              true,
+		ConnWQMax:          32768,
+		KeepAlive:          true,
+		ReadBufSize:        16384,
+	}
+
+	if *options != expectedOptions {
+		t.Errorf("expected %+v, got %+v", expectedOptions, *options)
+	}
+
+	raw, err := CoreUDP4RawInfo(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (UDP4RawInfo{Enabled: -1, MTU: 1500, TTL: 63}); *raw != expected {
+		t.Errorf("expected %+v, got %+v", expected, *raw)
+	}
+}
+
+func TestCoreTCPInfoFault(t *testing.T) {
+	client := serve(t, func(records []Record) []any {
+		return []any{&RPCError{Code: 500, Message: "tcp support disabled"}}
+	})
+
+	var rpcErr *RPCError
+
+	if _, err := CoreTCPInfo(context.Background(), client); !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package binrpc
+
+import (
+	"context"
+)
+
+// Flags of a UACRegistration.
+const (
+	UACRegDisabled = 1 << 0
+	UACRegOngoing  = 1 << 1
+	UACRegOnline   = 1 << 2
+	UACRegAuthSent = 1 << 3
+	UACRegInit     = 1 << 4
+)
+
+// UACRegistration is a remote registration of the uac module, such as to a SIP trunk, as returned by uac.reg_dump
+// and uac.reg_info.
+type UACRegistration struct {
+	// LocalUUID is the identifier of the registration, used by UACRegInfo and the other helpers.
+	LocalUUID     string `binrpc:"l_uuid"`
+	LocalUsername string `binrpc:"l_username"`
+	LocalDomain   string `binrpc:"l_domain"`
+
+	RemoteUsername string `binrpc:"r_username"`
+	RemoteDomain   string `binrpc:"r_domain"`
+
+	Realm        string `binrpc:"realm"`
+	AuthUsername string `binrpc:"auth_username"`
+	AuthProxy    string `binrpc:"auth_proxy"`
+
+	// Expires is the expiration requested, in seconds.
+	Expires int `binrpc:"expires"`
+
+	// Flags is a combination of UACRegDisabled, UACRegOngoing, UACRegOnline, UACRegAuthSent and UACRegInit.
+	Flags int `binrpc:"flags"`
+
+	// DiffExpires is the number of seconds before the registration is refreshed, and TimerExpires the time of the
+	// refresh, in seconds since Kamailio started.
+	DiffExpires  int `binrpc:"diff_expires"`
+	TimerExpires int `binrpc:"timer_expires"`
+
+	RegInit  int `binrpc:"reg_init"`
+	RegDelay int `binrpc:"reg_delay"`
+
+	ContactAddr string `binrpc:"contact_addr"`
+	Socket      string `binrpc:"socket"`
+}
+
+// State returns the state of the registration from its flags: "disabled", "online" (registered), "ongoing" (a
+// REGISTER is in progress) or "offline".
+func (reg UACRegistration) State() string {
+	switch {
+	case reg.Flags&UACRegDisabled != 0:
+		return "disabled"
+	case reg.Flags&UACRegOnline != 0:
+		return "online"
+	case reg.Flags&UACRegOngoing != 0:
+		return "ongoing"
+	default:
+		return "offline"
+	}
+}
+
+// UACRegDump calls uac.reg_dump with caller and returns the remote registrations.
+func UACRegDump(ctx context.Context, caller Caller) ([]UACRegistration, error) {
+	records, err := caller.CallContext(ctx, "uac.reg_dump")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var registrations []UACRegistration
+
+	if err = Unmarshal(records, &registrations); err != nil {
+		return nil, err
+	}
+
+	return registrations, nil
+}
+
+// UACRegInfo calls uac.reg_info with caller and returns the remote registration whose l_uuid is uuid. If there is
+// none, Kamailio replies with a fault, returned as an *RPCError.
+func UACRegInfo(ctx context.Context, caller Caller, uuid string) (*UACRegistration, error) {
+	records, err := caller.CallContext(ctx, "uac.reg_info", "l_uuid", uuid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var registration UACRegistration
+
+	if err = Unmarshal(records, &registration); err != nil {
+		return nil, err
+	}
+
+	return &registration, nil
+}
+
+// UACRegRefresh calls uac.reg_refresh with caller to reload the remote registration whose l_uuid is uuid from the
+// database.
+func UACRegRefresh(ctx context.Context, caller Caller, uuid string) error {
+	_, err := caller.CallContext(ctx, "uac.reg_refresh", uuid)
+
+	return err
+}
+
+// UACRegEnable calls uac.reg_enable with caller to enable the remote registration whose l_uuid is uuid.
+func UACRegEnable(ctx context.Context, caller Caller, uuid string) error {
+	_, err := caller.CallContext(ctx, "uac.reg_enable", "l_uuid", uuid)
+
+	return err
+}
+
+// UACRegDisable calls uac.reg_disable with caller to disable the remote registration whose l_uuid is uuid, which
+// stops refreshing it.
+func UACRegDisable(ctx context.Context, caller Caller, uuid string) error {
+	_, err := caller.CallContext(ctx, "uac.reg_disable", "l_uuid", uuid)
+
+	return err
+}
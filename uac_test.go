@@ -0,0 +1,135 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestUACReg(t *testing.T) {
+	registration := map[string]any{
+		"l_uuid":        "trunk1",
+		"l_username":    "alice",
+		"l_domain":      "example.com",
+		"r_username":    "4420000000",
+		"r_domain":      "sip.carrier.net",
+		"realm":         "carrier",
+		"auth_username": "4420000000",
+		"auth_password": "secret",
+		"auth_proxy":    "sip:sip.carrier.net",
+		"expires":       3600,
+		"flags":         UACRegOnline | UACRegInit,
+		"diff_expires":  1720,
+		"timer_expires": 98765,
+		"reg_init":      1700000000,
+		"reg_delay":     0,
+		"contact_addr":  "10.0.0.1:5060",
+		"socket":        "",
+	}
+
+	var mu sync.Mutex
+
+	var calls [][]string
+
+	client := serve(t, func(records []Record) []any {
+		call := make([]string, len(records))
+
+		for i, record := range records {
+			record.Scan(&call[i])
+		}
+
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+
+		switch call[0] {
+		case "uac.reg_dump":
+			return []any{registration, map[string]any{"l_uuid": "trunk2", "flags": UACRegDisabled}}
+		case "uac.reg_info":
+			if call[2] != "trunk1" {
+				return []any{&RPCError{Code: 404, Message: "Record not found"}}
+			}
+
+			return []any{registration}
+		}
+
+		return []any{"ok"}
+	})
+
+	ctx := context.Background()
+
+	registrations, err := UACRegDump(ctx, client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := UACRegistration{
+		LocalUUID:      "trunk1",
+		LocalUsername:  "alice",
+		LocalDomain:    "example.com",
+		RemoteUsername: "4420000000",
+		RemoteDomain:   "sip.carrier.net",
+		Realm:          "carrier",
+		AuthUsername:   "4420000000",
+		AuthProxy:      "sip:sip.carrier.net",
+		Expires:        3600,
+		Flags:          UACRegOnline | UACRegInit,
+		DiffExpires:    1720,
+		TimerExpires:   98765,
+		RegInit:        1700000000,
+		ContactAddr:    "10.0.0.1:5060",
+	}
+
+	if len(registrations) != 2 || registrations[0] != expected {
+		t.Fatalf("expected %+v first, got %+v", expected, registrations)
+	}
+
+	if state := registrations[0].State(); state != "online" {
+		t.Errorf("expected online, got %s", state)
+	}
+
+	if state := registrations[1].State(); state != "disabled" {
+		t.Errorf("expected disabled, got %s", state)
+	}
+
+	registration2, err := UACRegInfo(ctx, client, "trunk1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *registration2 != expected {
+		t.Errorf("expected %+v, got %+v", expected, *registration2)
+	}
+
+	var rpcErr *RPCError
+
+	if _, err = UACRegInfo(ctx, client, "unknown"); !errors.As(err, &rpcErr) {
+		t.Errorf("expected *RPCError, got %v", err)
+	}
+
+	for _, fn := range []func(context.Context, Caller, string) error{UACRegRefresh, UACRegEnable, UACRegDisable} {
+		if err = fn(ctx, client, "trunk1"); err != nil {
+			t.Error(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expectedCalls := [][]string{
+		{"uac.reg_dump"},
+		{"uac.reg_info", "l_uuid", "trunk1"},
+		{"uac.reg_info", "l_uuid", "unknown"},
+		{"uac.reg_refresh", "trunk1"},
+		{"uac.reg_enable", "l_uuid", "trunk1"},
+		{"uac.reg_disable", "l_uuid", "trunk1"},
+	}
+
+	if !reflect.DeepEqual(calls, expectedCalls) {
+		t.Errorf("expected %q, got %q", expectedCalls, calls)
+	}
+}
@@ -0,0 +1,75 @@
+package binrpc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// datagramReaderSize is the size of the buffer reading datagram connections. A datagram larger than the buffer would
+// be truncated, and the ctl module limits datagram replies to 64 KB.
+const datagramReaderSize = 65536
+
+// isDatagram reports whether network is a datagram network.
+func isDatagram(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	default:
+		return false
+	}
+}
+
+// newReader returns the reader of conn, opened on network.
+func newReader(network string, conn net.Conn) *bufio.Reader {
+	if isDatagram(network) {
+		return bufio.NewReaderSize(conn, datagramReaderSize)
+	}
+
+	return bufio.NewReader(conn)
+}
+
+// unixgramConn is a unixgram connection bound to a temporary reply socket, which is removed on Close.
+type unixgramConn struct {
+	net.Conn
+
+	path string
+}
+
+// Close closes the connection and removes its reply socket.
+func (c *unixgramConn) Close() error {
+	err := c.Conn.Close()
+	os.Remove(c.path)
+
+	return err
+}
+
+// dialUnixgram opens a unixgram connection to address with dialer. Unlike a stream, a datagram sent from an unbound
+// socket cannot be replied to, so, like kamcmd, the connection is bound to a temporary socket in os.TempDir. The
+// socket is writable by all users, as Kamailio usually runs as another user.
+func dialUnixgram(ctx context.Context, dialer *net.Dialer, address string) (net.Conn, error) {
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("binrpc_%d_%08x.sock", os.Getpid(), rand.Uint32()))
+
+	bound := *dialer
+	bound.LocalAddr = &net.UnixAddr{Name: path, Net: "unixgram"}
+
+	conn, err := bound.DialContext(ctx, "unixgram", address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the mode of a socket depends on the umask
+	if err = os.Chmod(path, 0o666); err != nil {
+		conn.Close()
+		os.Remove(path)
+
+		return nil, fmt.Errorf("reply socket: %w", err)
+	}
+
+	return &unixgramConn{Conn: conn, path: path}, nil
+}
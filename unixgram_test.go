@@ -0,0 +1,77 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDialUnixgram(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "ctl.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: address, Net: "unixgram"})
+
+	if err != nil {
+		t.Skip(err)
+	}
+
+	defer server.Close()
+
+	go func() {
+		buffer := make([]byte, datagramReaderSize)
+
+		for {
+			n, addr, err := server.ReadFrom(buffer)
+
+			if err != nil {
+				return
+			}
+
+			request, err := ReadRequest(bufio.NewReader(bytes.NewReader(buffer[:n])))
+
+			if err != nil {
+				return
+			}
+
+			// a reply larger than the default buffer of bufio
+			response, _ := NewResponse(request, string(bytes.Repeat([]byte("x"), 10000)))
+			response.WriteTo(&packetWriter{server, addr})
+		}
+	}()
+
+	client, err := Dial("unixgram", address)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := client.conn.(*unixgramConn).path
+
+	records, err := client.Call("core.echo")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, _ := records[0].String(); len(value) != 10000 {
+		t.Errorf("expected a string of 10000 bytes, got %d bytes", len(value))
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode != 0o666 {
+		t.Errorf("expected mode 0666, got %o", mode)
+	}
+
+	client.Close()
+
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the reply socket to be removed, got %v", err)
+	}
+}
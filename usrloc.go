@@ -0,0 +1,255 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// AOR is an address of record registered in usrloc, as returned by ul.dump and ul.lookup.
+type AOR struct {
+	// AOR is the address of record, such as "alice@example.com".
+	AOR string
+
+	// Domain is the usrloc domain (the table, such as "location") of the AOR. It is empty for ul.lookup.
+	Domain string
+
+	Contacts []Contact
+}
+
+// Contact is a contact of an AOR.
+type Contact struct {
+	// URI is the contact address, such as "sip:alice@10.0.0.1:5060".
+	URI string
+
+	// Expires is the number of seconds before the contact expires. It is zero if the contact is Permanent or Expired.
+	Expires   int
+	Permanent bool
+	Expired   bool
+
+	// Q is the q value of the contact, -1 if not set.
+	Q float64
+
+	Path      string
+	Socket    string
+	UserAgent string
+	Received  string
+	CallID    string
+	CSeq      int
+	Instance  string
+	RegID     int
+	Ruid      string
+	Flags     int
+	CFlags    int
+	Methods   int
+
+	// LastModified is the Unix time of the last update of the contact.
+	LastModified int
+}
+
+// ULDump calls ul.dump with caller and returns the AORs of all the usrloc domains.
+func ULDump(ctx context.Context, caller Caller) ([]AOR, error) {
+	records, err := caller.CallContext(ctx, "ul.dump")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseULDump(records)
+}
+
+// ULLookup calls ul.lookup with caller for aor in the usrloc table, such as "location", and returns the AOR.
+// If aor is not registered, Kamailio replies with a fault, returned as an *RPCError.
+func ULLookup(ctx context.Context, caller Caller, table, aor string) (*AOR, error) {
+	records, err := caller.CallContext(ctx, "ul.lookup", table, aor)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseULLookup(records)
+}
+
+// ParseULDump decodes the reply of ul.dump. The structs wrapping domains, AORs and contacts differ between Kamailio
+// versions, so AORs are found as the structs with an "AoR" item, and contacts as the structs with an "Address" item.
+func ParseULDump(records []Record) ([]AOR, error) {
+	var aors []AOR
+
+	err := walkAORs(records, "", &aors)
+
+	return aors, err
+}
+
+// ParseULLookup decodes the reply of ul.lookup.
+func ParseULLookup(records []Record) (*AOR, error) {
+	aors, err := ParseULDump(records)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(aors) == 0 {
+		return nil, errors.New("AoR not found in reply")
+	}
+
+	return &aors[0], nil
+}
+
+// walkAORs appends the AORs found in records to aors. domain is the usrloc domain of the records.
+func walkAORs(records []Record, domain string, aors *[]AOR) error {
+	for _, record := range records {
+		switch record.Type {
+		case TypeArray:
+			elements, _ := record.Array()
+
+			if err := walkAORs(elements, domain, aors); err != nil {
+				return err
+			}
+		case TypeStruct:
+			items, _ := record.StructItems()
+
+			if name, ok := record.Get("AoR"); ok {
+				aor, err := parseAOR(name, items)
+
+				if err != nil {
+					return err
+				}
+
+				aor.Domain = domain
+				*aors = append(*aors, aor)
+
+				continue
+			}
+
+			// a domain is a struct like {Domain: "location", Size: 1024, AoRs: ...}
+			scope := domain
+
+			for _, item := range items {
+				if item.Key == "Domain" && item.Value.Type == TypeString {
+					scope, _ = item.Value.String()
+					continue
+				}
+
+				if err := walkAORs([]Record{item.Value}, scope, aors); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseAOR returns the AOR whose struct has items.
+func parseAOR(name Record, items []StructItem) (AOR, error) {
+	var aor AOR
+	var err error
+
+	if aor.AOR, err = name.String(); err != nil {
+		return aor, err
+	}
+
+	for _, item := range items {
+		if err = walkContacts(item.Value, &aor.Contacts); err != nil {
+			return aor, fmt.Errorf("%s: %w", aor.AOR, err)
+		}
+	}
+
+	return aor, nil
+}
+
+// walkContacts appends the contacts found in record to contacts.
+func walkContacts(record Record, contacts *[]Contact) error {
+	switch record.Type {
+	case TypeArray:
+		elements, _ := record.Array()
+
+		for _, element := range elements {
+			if err := walkContacts(element, contacts); err != nil {
+				return err
+			}
+		}
+	case TypeStruct:
+		items, _ := record.StructItems()
+
+		if _, ok := record.Get("Address"); !ok {
+			for _, item := range items {
+				if err := walkContacts(item.Value, contacts); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		contact, err := parseContact(items)
+
+		if err != nil {
+			return err
+		}
+
+		*contacts = append(*contacts, contact)
+	}
+
+	return nil
+}
+
+// parseContact returns the contact whose struct has items.
+func parseContact(items []StructItem) (Contact, error) {
+	contact := Contact{Q: -1}
+
+	fields := map[string]any{
+		"Address":       &contact.URI,
+		"Q":             &contact.Q,
+		"Path":          &contact.Path,
+		"Socket":        &contact.Socket,
+		"User-Agent":    &contact.UserAgent,
+		"Received":      &contact.Received,
+		"Call-ID":       &contact.CallID,
+		"CSeq":          &contact.CSeq,
+		"Instance":      &contact.Instance,
+		"Reg-Id":        &contact.RegID,
+		"Ruid":          &contact.Ruid,
+		"Flags":         &contact.Flags,
+		"CFlags":        &contact.CFlags,
+		"Methods":       &contact.Methods,
+		"Last-Modified": &contact.LastModified,
+	}
+
+	for _, item := range items {
+		if item.Key == "Expires" {
+			parseExpires(item.Value, &contact)
+			continue
+		}
+
+		dest, ok := fields[item.Key]
+
+		if !ok {
+			continue
+		}
+
+		// unset values are strings such as "[not set]"
+		if s, err := item.Value.String(); err == nil && s == "[not set]" {
+			continue
+		}
+
+		if err := item.Value.Scan(dest); err != nil {
+			return contact, fmt.Errorf("%s: %w", item.Key, err)
+		}
+	}
+
+	return contact, nil
+}
+
+// parseExpires sets the expiration of contact from value, the number of seconds left, or "permanent", "expired" or
+// "deleted".
+func parseExpires(value Record, contact *Contact) {
+	switch s, _ := value.String(); s {
+	case "permanent":
+		contact.Permanent = true
+	case "expired", "deleted":
+		contact.Expired = true
+	default:
+		value.Scan(&contact.Expires)
+	}
+}
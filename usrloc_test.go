@@ -0,0 +1,128 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestULDump(t *testing.T) {
+	contacts := []any{
+		[]StructItem{{Key: "Contact", Value: mustRecord(t, []StructItem{
+			{Key: "Address", Value: mustRecord(t, "sip:alice@10.0.0.1:5060")},
+			{Key: "Expires", Value: mustRecord(t, 3600)},
+			{Key: "Q", Value: mustRecord(t, 0.5)},
+			{Key: "Path", Value: mustRecord(t, "[not set]")},
+			{Key: "Socket", Value: mustRecord(t, "udp:10.0.0.2:5060")},
+			{Key: "User-Agent", Value: mustRecord(t, "Linphone")},
+			{Key: "CSeq", Value: mustRecord(t, 2)},
+		})}},
+		[]StructItem{{Key: "Contact", Value: mustRecord(t, []StructItem{
+			{Key: "Address", Value: mustRecord(t, "sip:alice@10.0.0.3:5060")},
+			{Key: "Expires", Value: mustRecord(t, "permanent")},
+		})}},
+	}
+
+	aor := []StructItem{
+		{Key: "AoR", Value: mustRecord(t, "alice@example.com")},
+		{Key: "HashID", Value: mustRecord(t, 1234)},
+		{Key: "Contacts", Value: mustRecord(t, contacts)},
+	}
+
+	dump := map[string]any{
+		"Domains": []any{
+			map[string]any{
+				"Domain": []StructItem{
+					{Key: "Domain", Value: mustRecord(t, "location")},
+					{Key: "Size", Value: mustRecord(t, 1024)},
+					{Key: "AoRs", Value: mustRecord(t, []any{map[string]any{"Info": aor}})},
+				},
+			},
+		},
+	}
+
+	server := NewServer()
+	defer server.Close()
+
+	server.Handle("ul.dump", func(ctx context.Context, request *Request) ([]any, error) {
+		return []any{dump}, nil
+	})
+
+	server.Handle("ul.lookup", func(ctx context.Context, request *Request) ([]any, error) {
+		if name, _ := request.Args[1].(Record).String(); name != "alice@example.com" {
+			return nil, &RPCError{Code: 404, Message: "AOR not found"}
+		}
+
+		return []any{aor}, nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(listener)
+
+	client, err := Dial("tcp", listener.Addr().String())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	aors, err := ULDump(context.Background(), client)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(aors) != 1 || aors[0].AOR != "alice@example.com" || aors[0].Domain != "location" || len(aors[0].Contacts) != 2 {
+		t.Fatalf("unexpected AoRs %+v", aors)
+	}
+
+	expected := Contact{
+		URI:       "sip:alice@10.0.0.1:5060",
+		Expires:   3600,
+		Q:         0.5,
+		Socket:    "udp:10.0.0.2:5060",
+		UserAgent: "Linphone",
+		CSeq:      2,
+	}
+
+	if contact := aors[0].Contacts[0]; contact != expected {
+		t.Errorf("expected %+v, got %+v", expected, contact)
+	}
+
+	if contact := aors[0].Contacts[1]; !contact.Permanent || contact.Expires != 0 || contact.Q != -1 {
+		t.Errorf("expected a permanent contact without q, got %+v", contact)
+	}
+
+	found, err := ULLookup(context.Background(), client, "location", "alice@example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.AOR != "alice@example.com" || len(found.Contacts) != 2 {
+		t.Errorf("unexpected AoR %+v", found)
+	}
+
+	var fault *RPCError
+
+	if _, err = ULLookup(context.Background(), client, "location", "bob@example.com"); !errors.As(err, &fault) {
+		t.Errorf("expected an *RPCError, got %v", err)
+	}
+}
+
+func mustRecord(t *testing.T, value any) Record {
+	records, err := createRecords([]any{value})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return *records[0]
+}
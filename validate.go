@@ -0,0 +1,160 @@
+package binrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidArgs is returned by calls whose args do not match the signature of the method, see WithValidation.
+var ErrInvalidArgs = errors.New("invalid args")
+
+// signatureTypes maps the type names returned by system.methodSignature to BINRPC types.
+var signatureTypes = map[string]uint8{
+	"int":    TypeInt,
+	"i4":     TypeInt,
+	"string": TypeString,
+	"double": TypeDouble,
+	"float":  TypeDouble,
+	"base64": TypeBytes,
+	"bytes":  TypeBytes,
+	"struct": TypeStruct,
+	"array":  TypeArray,
+}
+
+// WithValidation makes the Client check the args of each call against the signature of the method before sending
+// it, so that invalid calls fail locally with an error wrapping ErrInvalidArgs, instead of a generic fault from
+// Kamailio.
+//
+// The signature is the one registered by RegisterSignature, or else the one returned by system.methodSignature, which
+// is fetched on the first call of each method and cached for the life of the Client. Calls to methods without a
+// known signature are sent without validation. Note that Kamailio implements system.methodSignature for few modules,
+// so registering the signatures of the methods used is the most reliable.
+func WithValidation() Option {
+	return func(c *Client) {
+		c.validate = true
+		c.signatures = make(map[string][]Signature)
+	}
+}
+
+// checkArgs returns an error wrapping ErrInvalidArgs if args do not match any signature of method. It returns nil if
+// validation is disabled, or if no signature is known.
+func (c *Client) checkArgs(ctx context.Context, method string, args []any) error {
+	if !c.validate {
+		return nil
+	}
+
+	signatures := c.lookupSignatures(ctx, method)
+
+	var first error
+
+	for _, signature := range signatures {
+		err := signature.Validate(args)
+
+		if err == nil {
+			return nil
+		}
+
+		if first == nil {
+			first = err
+		}
+	}
+
+	if first != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArgs, first)
+	}
+
+	return nil
+}
+
+// lookupSignatures returns the signatures of method, registered or fetched from Kamailio.
+func (c *Client) lookupSignatures(ctx context.Context, method string) []Signature {
+	if signature, ok := LookupSignature(method); ok {
+		return []Signature{signature}
+	}
+
+	c.signaturesMu.Lock()
+	signatures, ok := c.signatures[method]
+	c.signaturesMu.Unlock()
+
+	if ok {
+		return signatures
+	}
+
+	records, err := c.call(ctx, CallInfo{
+		Method: "system.methodSignature",
+		Args:   []any{method},
+		Cookie: c.cookies.Cookie(),
+	})
+
+	var rpcErr *RPCError
+
+	if err != nil && !errors.As(err, &rpcErr) {
+		// the call itself will report the error, and the signature is fetched again by the next one
+		return nil
+	}
+
+	// a fault means that the signature is not available: the method is not validated
+	signatures = parseMethodSignature(method, records)
+
+	c.signaturesMu.Lock()
+	c.signatures[method] = signatures
+	c.signaturesMu.Unlock()
+
+	return signatures
+}
+
+// parseMethodSignature returns the signatures of method from the reply of system.methodSignature. As in XML-RPC, the
+// reply is a list of signatures, each one being a list of type names, the first one being the type of the reply.
+// A reply made of type names only is a single signature. Signatures with unknown type names are ignored.
+func parseMethodSignature(method string, records []Record) []Signature {
+	if len(records) == 1 && records[0].Type == TypeArray {
+		records, _ = records[0].Array()
+	}
+
+	lists := [][]Record{records}
+
+	if len(records) > 0 && records[0].Type == TypeArray {
+		lists = nil
+
+		for _, record := range records {
+			list, _ := record.Array()
+			lists = append(lists, list)
+		}
+	}
+
+	var signatures []Signature
+
+lists:
+	for _, list := range lists {
+		if len(list) == 0 {
+			continue
+		}
+
+		signature := Signature{Method: method}
+
+		// the first type is the one of the reply
+		for i, record := range list[1:] {
+			name, err := record.String()
+
+			if err != nil {
+				continue lists
+			}
+
+			paramType, ok := signatureTypes[name]
+
+			if !ok {
+				continue lists
+			}
+
+			signature.Params = append(signature.Params, Param{
+				Name: fmt.Sprintf("#%d", i+1),
+				Type: paramType,
+			})
+		}
+
+		signatures = append(signatures, signature)
+	}
+
+	return signatures
+}
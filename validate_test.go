@@ -0,0 +1,95 @@
+package binrpc
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestWithValidation(t *testing.T) {
+	var mu sync.Mutex
+
+	calls := make(map[string]int)
+
+	client := serve(t, func(records []Record) []any {
+		method, _ := records[0].String()
+
+		mu.Lock()
+		calls[method]++
+		mu.Unlock()
+
+		if method != "system.methodSignature" {
+			return []any{"ok"}
+		}
+
+		if name, _ := records[1].String(); name != "app.set" {
+			return []any{&RPCError{Code: 500, Message: "Not Implemented Yet"}}
+		}
+
+		return []any{[]any{[]string{"string", "string", "int"}}}
+	}, WithValidation())
+
+	if _, err := client.Call("app.set", "key", "1"); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("expected ErrInvalidArgs, got %v", err)
+	}
+
+	if _, err := client.Call("app.set", "key"); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("expected ErrInvalidArgs, got %v", err)
+	}
+
+	if _, err := client.Call("app.set", "key", 1); err != nil {
+		t.Error(err)
+	}
+
+	// registered signatures are not fetched
+	if _, err := client.Call("htable.sets", "ipban", "1.2.3.4", 1); !errors.Is(err, ErrInvalidArgs) {
+		t.Errorf("expected ErrInvalidArgs, got %v", err)
+	}
+
+	// methods without signature are not validated
+	for i := 0; i < 2; i++ {
+		if _, err := client.Call("app.other", 1, "two", 3.0); err != nil {
+			t.Error(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls["app.set"] != 1 || calls["htable.sets"] != 0 || calls["app.other"] != 2 {
+		t.Errorf("invalid calls must not be sent, got %v", calls)
+	}
+
+	if calls["system.methodSignature"] != 2 {
+		t.Errorf("expected signatures to be fetched once per method, got %d calls", calls["system.methodSignature"])
+	}
+}
+
+func TestSignatureValidate(t *testing.T) {
+	signature, _ := LookupSignature("dispatcher.add")
+
+	valid := [][]any{
+		{1, "sip:10.0.0.1:5060"},
+		{1, "sip:10.0.0.1:5060", 8, 0, "weight=50"},
+		{1, Record{Type: TypeString, Value: "sip:10.0.0.1:5060"}},
+	}
+
+	for _, args := range valid {
+		if err := signature.Validate(args); err != nil {
+			t.Errorf("%v: %v", args, err)
+		}
+	}
+
+	invalid := [][]any{
+		{1},
+		{"1", "sip:10.0.0.1:5060"},
+		{1, "sip:10.0.0.1:5060", 8, 0, "weight=50", "extra"},
+		{1, []string{"sip:10.0.0.1:5060"}},
+	}
+
+	for _, args := range invalid {
+		if err := signature.Validate(args); err == nil {
+			t.Errorf("%v: error must be returned", args)
+		}
+	}
+}
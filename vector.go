@@ -0,0 +1,67 @@
+package binrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// Test vectors are packets written in hex, used to check decoders and encoders against each other, including other
+// BINRPC implementations. Vectors produced by this package are canonical: lowercase hex digits without separators, and
+// the minimum sizes for the length and the cookie.
+
+// VectorHex returns the test vector of a packet with cookie, containing values. Valid values are the args of
+// Client.Call.
+func VectorHex(cookie uint32, values ...any) (string, error) {
+	packet, _, err := encodePacket(0, cookie, values)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(packet), nil
+}
+
+// Hex returns the test vector of request.
+func (request *Request) Hex() string {
+	return hex.EncodeToString(request.packet)
+}
+
+// Hex returns the test vector of response.
+func (response *Response) Hex() string {
+	return hex.EncodeToString(response.packet)
+}
+
+// ParseVectorHex decodes the packet of a test vector. Whitespace is ignored, so that vectors can be split on several
+// lines. The packet must be complete, and nothing may follow it.
+func ParseVectorHex(vector string) (*Header, []Record, error) {
+	vector = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, vector)
+
+	data, err := hex.DecodeString(vector)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(bytes.NewReader(data))
+	header, records, err := DecodePacket(reader, ReaderOptions{})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err = reader.Peek(1); err == nil {
+		return nil, nil, errors.New("trailing data after the packet")
+	}
+
+	return header, records, nil
+}
@@ -0,0 +1,84 @@
+package binrpc
+
+import (
+	"testing"
+)
+
+func TestVectorHex(t *testing.T) {
+	vector, err := VectorHex(0x1234, "core.echo", 42)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// header with a 1 byte length and a 2 bytes cookie, "core.echo" (with a 1 byte size) and 42
+	expected := "a1010e1234" + "910a636f72652e6563686f00" + "102a"
+
+	if vector != expected {
+		t.Errorf("expected %s, got %s", expected, vector)
+	}
+
+	header, records, err := ParseVectorHex("a1010e1234\n 910a636f72652e6563686f00\n 102a")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Cookie != 0x1234 || len(records) != 2 {
+		t.Errorf("expected cookie 1234 and 2 records, got %x and %d", header.Cookie, len(records))
+	}
+
+	if _, _, err = ParseVectorHex(expected + "00"); err == nil {
+		t.Error("error must be returned for trailing data")
+	}
+
+	request, _ := NewRequest("core.echo", 42)
+
+	if _, records, err = ParseVectorHex(request.Hex()); err != nil || len(records) != 2 {
+		t.Errorf("expected the request to be parsed back, got %v (%v)", records, err)
+	}
+}
+
+func FuzzParseVectorHex(f *testing.F) {
+	seeds := [][]any{
+		{"core.echo", 42},
+		{"stats.fetch", "all"},
+		{1.5, map[string]any{"a": 1, "b": "c"}},
+		{[]any{1, "two", []string{"three"}}},
+	}
+
+	for _, values := range seeds {
+		vector, err := VectorHex(0xcafe, values...)
+
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		f.Add(vector)
+	}
+
+	f.Fuzz(func(t *testing.T, vector string) {
+		header, records, err := ParseVectorHex(vector)
+
+		if err != nil {
+			return
+		}
+
+		values := make([]any, 0, len(records))
+
+		for _, record := range records {
+			values = append(values, record)
+		}
+
+		// a packet decoded must encode again, and decode to the same records
+		again, err := VectorHex(header.Cookie, values...)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, decoded, err := ParseVectorHex(again); err != nil || len(decoded) != len(records) {
+			t.Errorf("%s: re-encoded as %s, decoded %d records (%v)", vector, again, len(decoded), err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package binrpc
+
+import (
+	"errors"
+	"strconv"
+)
+
+// SkipRecord is returned by a WalkFunc to skip the items or the elements of the struct or the array it was called
+// with. It is not returned by Walk.
+var SkipRecord = errors.New("skip this record")
+
+// WalkFunc is the function called by Walk for each record, with its path in the syntax of Record.GetPath. If it
+// returns an error, other than SkipRecord, the walk stops and Walk returns the error.
+type WalkFunc func(path string, record Record) error
+
+// Walk calls fn for each record of a reply and, depth-first, for the items of their structs and the elements of their
+// arrays, in order. The paths start with the index of the record in the reply, as for Query:
+//
+//	err := binrpc.Walk(records, func(path string, record binrpc.Record) error {
+//		if record.Type == binrpc.TypeInt {
+//			fmt.Println(path, record.Value) // "[0].shmem.used 1024"
+//		}
+//
+//		return nil
+//	})
+//
+// The values of a key found several times in a struct have the same path, which selects the first one with GetPath.
+func Walk(records []Record, fn WalkFunc) error {
+	for i := range records {
+		if err := records[i].walk("["+strconv.Itoa(i)+"]", fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Walk is like the function Walk, for record and its nested records. The path of record is empty, and the paths of
+// the nested records are relative to it.
+func (record *Record) Walk(fn WalkFunc) error {
+	return record.walk("", fn)
+}
+
+// walk calls fn for record, whose path is path, and then for its nested records.
+func (record *Record) walk(path string, fn WalkFunc) error {
+	err := fn(path, *record)
+
+	if errors.Is(err, SkipRecord) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	switch record.Type {
+	case TypeStruct:
+		for _, item := range record.Value.([]StructItem) {
+			key := item.Key
+
+			if path != "" {
+				key = path + "." + key
+			}
+
+			if err = item.Value.walk(key, fn); err != nil {
+				return err
+			}
+		}
+	case TypeArray:
+		for i, element := range record.Value.([]Record) {
+			if err = element.walk(path+"["+strconv.Itoa(i)+"]", fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
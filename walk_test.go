@@ -0,0 +1,78 @@
+package binrpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	records := []Record{
+		{Type: TypeStruct, Value: []StructItem{
+			{Key: "shmem", Value: Record{Type: TypeStruct, Value: []StructItem{
+				{Key: "used", Value: Record{Type: TypeInt, Value: 1024}},
+			}}},
+			{Key: "sets", Value: Record{Type: TypeArray, Value: []Record{
+				{Type: TypeInt, Value: 1},
+				{Type: TypeInt, Value: 2},
+			}}},
+		}},
+		{Type: TypeString, Value: "second"},
+	}
+
+	var paths []string
+
+	err := Walk(records, func(path string, record Record) error {
+		paths = append(paths, path)
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"[0]", "[0].shmem", "[0].shmem.used", "[0].sets", "[0].sets[0]", "[0].sets[1]", "[1]"}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+
+	// the paths select the records with Query
+	for _, path := range expected {
+		if _, err = Query(records, path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	paths = nil
+
+	err = records[0].Walk(func(path string, record Record) error {
+		paths = append(paths, path)
+
+		if path == "shmem" {
+			return SkipRecord
+		}
+
+		return nil
+	})
+
+	expected = []string{"", "shmem", "sets", "sets[0]", "sets[1]"}
+
+	if err != nil || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v (%v)", expected, paths, err)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+
+	err = Walk(records, func(path string, record Record) error {
+		calls++
+
+		return stop
+	})
+
+	if err != stop || calls != 1 {
+		t.Errorf("expected the walk to stop with its error after 1 call, got %v after %d calls", err, calls)
+	}
+}